@@ -0,0 +1,77 @@
+package csvi
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// auditRecord is a line of the audit log written in JSON Lines
+type auditRecord struct {
+	Time   string `json:"time"`
+	User   string `json:"user"`
+	Action string `json:"action"`
+	Row    int    `json:"row"`
+	Column int    `json:"column,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+}
+
+var auditUser = sync.OnceValue(func() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+})
+
+// audit writes an edit into Config.AuditLog. col is the index of the column
+// or -1 when the whole row is changed.
+func (app *_Application) audit(action string, row *RowPtr, col int, oldText, newText string) {
+	if app.AuditLog == nil {
+		return
+	}
+	r := &auditRecord{
+		Time:   time.Now().Format(time.RFC3339),
+		User:   auditUser(),
+		Action: action,
+		Row:    row.Index() + 1,
+		Old:    oldText,
+		New:    newText,
+	}
+	if col >= 0 {
+		r.Column = col + 1
+		r.Name = app.columnName(col)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	app.AuditLog.Write(append(data, '\n'))
+}
+
+// auditRow writes the insertion or the deletion of a row into the audit log
+func (app *_Application) auditRow(action string, row *RowPtr, content *uncsv.Row) {
+	if app.AuditLog == nil {
+		return
+	}
+	// the row as written in the file without the terminator
+	cells := make([]string, len(content.Cell))
+	for i, c := range content.Cell {
+		cells[i] = c.SourceText(app.Mode)
+	}
+	text := strings.Join(cells, string(rune(app.Mode.Comma)))
+	if action == "delete-row" {
+		app.audit(action, row, -1, text, "")
+	} else {
+		app.audit(action, row, -1, "", text)
+	}
+}
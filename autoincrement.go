@@ -0,0 +1,70 @@
+package csvi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["autoinc"] = &exCommand{
+		help: "toggle filling the current column of new rows with max+1",
+		run:  cmdAutoIncrement,
+	}
+}
+
+// autoIncrementColumn returns the index of Config.AutoIncrement or -1
+func (app *_Application) autoIncrementColumn() int {
+	if app.AutoIncrement == "" {
+		return -1
+	}
+	col, err := app.columnIndex(app.AutoIncrement)
+	if err != nil {
+		return -1
+	}
+	return col
+}
+
+// nextSerial returns the maximum of the integers in the column col plus one.
+// The header lines are not counted.
+func (app *_Application) nextSerial(col int) int64 {
+	var max int64
+	lnum := 0
+	app.Each(func(row *uncsv.Row) bool {
+		if lnum++; lnum <= app.HeaderLines || col >= len(row.Cell) {
+			return true
+		}
+		if n, err := strconv.ParseInt(strings.TrimSpace(row.Cell[col].Text()), 10, 64); err == nil && n > max {
+			max = n
+		}
+		return true
+	})
+	return max + 1
+}
+
+// applyAutoIncrement fills the cell of Config.AutoIncrement of a new row
+// not inserted yet. The row is extended to width cells at least, so that
+// the cursor does not move onto the serial number.
+func (app *_Application) applyAutoIncrement(row *uncsv.Row, width int) {
+	col := app.autoIncrementColumn()
+	if col < 0 {
+		return
+	}
+	for len(row.Cell) <= col || len(row.Cell) < width {
+		row.Insert(len(row.Cell), "", app.Mode)
+	}
+	row.Replace(col, strconv.FormatInt(app.nextSerial(col), 10), app.Mode)
+}
+
+func cmdAutoIncrement(e *exCommandArgs) (string, error) {
+	if e.autoIncrementColumn() == e.CursorCol {
+		e.AutoIncrement = ""
+		return "auto-increment: off", nil
+	}
+	e.AutoIncrement = strconv.Itoa(e.CursorCol + 1)
+	if name := e.columnName(e.CursorCol); name != "" {
+		e.AutoIncrement = name
+	}
+	return "auto-increment: " + e.AutoIncrement, nil
+}
@@ -0,0 +1,64 @@
+package csvi
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// AutoPilot is the Pilot which gives the keys and the lines of the script
+// instead of the terminal, for tests and demonstrations.
+type AutoPilot struct {
+	// Width and Height are the size of the screen
+	Width, Height int
+	script        string
+}
+
+// NewAutoPilot returns the AutoPilot of the screen 80x25. The script is
+// the keys and the lines separated by `|` like `j|l|r|new text|q|y`.
+// A key is a character or an escape sequence. After the script ends,
+// GetKey returns io.EOF.
+func NewAutoPilot(script string) *AutoPilot {
+	return &AutoPilot{Width: 80, Height: 25, script: script}
+}
+
+func (ap *AutoPilot) Size() (int, int, error) {
+	return ap.Width, ap.Height, nil
+}
+
+func (ap *AutoPilot) Calibrate() error {
+	return nil
+}
+
+func (ap *AutoPilot) next() (string, error) {
+	if ap.script == "" {
+		return "", io.EOF
+	}
+	var command string
+	command, ap.script, _ = strings.Cut(ap.script, "|")
+	return command, nil
+}
+
+func (ap *AutoPilot) ReadLine(io.Writer, string, string, Candidate) (string, error) {
+	return ap.next()
+}
+
+func (ap *AutoPilot) GetKey() (string, error) {
+	key, err := ap.next()
+	if err != nil || len(key) <= 1 || key[0] == '\x1B' {
+		return key, err
+	}
+	if utf8.RuneCountInString(key) != 1 {
+		return key, fmt.Errorf("%#v: too long string for getkey", key)
+	}
+	return key, nil
+}
+
+func (ap *AutoPilot) GetFilename(out io.Writer, prompt string, defaultName string) (string, error) {
+	return ap.next()
+}
+
+func (ap *AutoPilot) Close() error {
+	return nil
+}
@@ -0,0 +1,317 @@
+package csvi
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// readAll makes the application holding all rows of in without the terminal.
+func (cfg *Config) readAll(in io.Reader, out io.Writer) (*_Application, error) {
+	if cfg.Mode == nil {
+		cfg.Mode = &uncsv.Mode{}
+	}
+	app := &_Application{
+		Config:      cfg,
+		Document:    NewDocument(cfg.Mode),
+		out:         out,
+		registers:   registers{},
+		recentFiles: &recentFiles{},
+		parseState:  &parseState{},
+	}
+	if in != nil {
+		reader, ok := in.(*bufio.Reader)
+		if !ok {
+			reader = bufio.NewReader(in)
+		}
+		for {
+			row, err := cfg.readLine(reader, app.parseState)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if err == io.EOF {
+				if !isEmptyRow(row) {
+					app.Push(row)
+				}
+				break
+			}
+			app.Push(row)
+		}
+	}
+	if app.Len() <= 0 {
+		newRow := uncsv.NewRow(cfg.Mode)
+		app.Push(&newRow)
+	}
+	return app, nil
+}
+
+// splitBatchScript splits script into commands by `;` and newlines
+// except for those between double quotes.
+func splitBatchScript(script string) []string {
+	var commands []string
+	var buffer strings.Builder
+	quoted := false
+	escaped := false
+	for _, c := range script {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == ';' || c == '\n'):
+			commands = append(commands, buffer.String())
+			buffer.Reset()
+			continue
+		}
+		buffer.WriteRune(c)
+	}
+	return append(commands, buffer.String())
+}
+
+// unquoteBatchText removes the double quotes around s if any.
+func unquoteBatchText(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
+
+var errBadPosition = errors.New("the position must be ROW,COLUMN")
+
+// parsePosition parses `ROW,COLUMN` where COLUMN is a header name or a number.
+func (app *_Application) parsePosition(s string) (*RowPtr, int, error) {
+	r, c, ok := strings.Cut(s, ",")
+	if !ok {
+		return nil, 0, errBadPosition
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(r))
+	if err != nil {
+		return nil, 0, errBadPosition
+	}
+	row, err := app.rowAt(n)
+	if err != nil {
+		return nil, 0, err
+	}
+	col, err := app.columnIndex(strings.TrimSpace(c))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", c, err)
+	}
+	return row, col, nil
+}
+
+type batchCommand func(e *exCommandArgs) error
+
+var batchCommands = map[string]batchCommand{
+	"set":        batchSet,
+	"delete-row": batchDeleteRow,
+	"move":       batchMove,
+	"sort":       batchSort,
+	"write":      batchWrite,
+}
+
+// batchSet replaces the cell: set ROW,COLUMN TEXT
+func batchSet(e *exCommandArgs) error {
+	pos, text, _ := strings.Cut(e.Args, " ")
+	row, col, err := e.parsePosition(pos)
+	if err != nil {
+		return err
+	}
+	if m := e.checkWriteProtect(row); m != "" {
+		return errors.New(m)
+	}
+	text, err = unquoteBatchText(strings.TrimSpace(text))
+	if err != nil {
+		return err
+	}
+	if col >= len(row.Cell) {
+		if e.FixColumn {
+			return errors.New(msgColumnFixed)
+		}
+		for col >= len(row.Cell) {
+			row.Insert(len(row.Cell), "", e.Mode)
+		}
+	}
+	text, err = e.validate(row, col, text)
+	if err != nil {
+		return err
+	}
+	e.replaceCell(row, col, text)
+	return nil
+}
+
+// batchDeleteRow removes the row: delete-row ROW
+func batchDeleteRow(e *exCommandArgs) error {
+	n, err := strconv.Atoi(e.Args)
+	if err != nil {
+		return fmt.Errorf("%s: the row must be a number", e.Args)
+	}
+	row, err := e.rowAt(n)
+	if err != nil {
+		return err
+	}
+	if m := e.checkWriteProtect(row); m != "" {
+		return errors.New(m)
+	}
+	if e.Len() <= 1 {
+		return errors.New("the last row can not be removed")
+	}
+	if e.CursorRow.Index() >= row.Index() {
+		e.CursorRow = e.Front()
+	}
+	e.auditRow("delete-row", row, row.Row)
+	e.removeRow(row)
+	return nil
+}
+
+// batchMove moves the cursor for the commands of `:`: move ROW,COLUMN
+func batchMove(e *exCommandArgs) error {
+	row, col, err := e.parsePosition(e.Args)
+	if err != nil {
+		return err
+	}
+	e.CursorRow = row
+	e.CursorCol = col
+	return nil
+}
+
+// compareCells orders numbers by their values before texts ordered by
+// their bytes
+func compareCells(a, b string) int {
+	x, xok := parseNumber(a)
+	y, yok := parseNumber(b)
+	switch {
+	case xok && yok:
+		return cmp.Compare(x, y)
+	case xok:
+		return -1
+	case yok:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// batchSort sorts the rows under the header by the column: sort COLUMN
+func batchSort(e *exCommandArgs) error {
+	if e.Args == "" {
+		return errors.New("sort: the column is required")
+	}
+	col, err := e.columnIndex(e.Args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", e.Args, err)
+	}
+	if e.ReadOnly {
+		return errors.New(msgReadOnly)
+	}
+	text := func(row *uncsv.Row) string {
+		if col < len(row.Cell) {
+			return row.Cell[col].Text()
+		}
+		return ""
+	}
+	e.sortRows(e.HeaderLines, func(a, b *uncsv.Row) int {
+		return compareCells(text(a), text(b))
+	})
+	e.CursorRow, _ = e.rowAt(e.CursorRow.Index() + 1)
+	return nil
+}
+
+// batchWrite writes all rows to the file or to the output for `-`: write FILE
+func batchWrite(e *exCommandArgs) error {
+	fname := e.Args
+	if fname == "" {
+		return errors.New("write: the filename is required")
+	}
+	if fname == "-" {
+		dump(e._Application, e.out)
+		return nil
+	}
+	if err := e.WriteFile(fname); err != nil {
+		return err
+	}
+	e.markSaved(fname)
+	return nil
+}
+
+func batchCommandNames() []string {
+	names := []string{}
+	for name := range batchCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range exCommandNames() {
+		if exCommands[name].batch {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (e *exCommandArgs) runBatch(line string) (string, error) {
+	name, args := cutCommandLine(line)
+	if name == "" {
+		return "", nil
+	}
+	e.Args = strings.TrimSpace(args)
+	if cmd, ok := batchCommands[name]; ok {
+		return "", cmd(e)
+	}
+	cmd, ok := exCommands[name]
+	if !ok || !cmd.batch {
+		return "", fmt.Errorf("%s: no such command (available: %s)",
+			name, strings.Join(batchCommandNames(), ","))
+	}
+	if e.Args == "" {
+		return "", fmt.Errorf("%s: the arguments are required", name)
+	}
+	return cmd.run(e)
+}
+
+// Batch reads all rows from in and applies the commands in script
+// without the terminal. Commands are separated by `;` or newlines:
+//
+//	set ROW,COLUMN TEXT   replace the cell (TEXT may be double-quoted)
+//	delete-row ROW        remove the row
+//	move ROW,COLUMN       move the cursor for the commands below
+//	sort COLUMN           sort the rows under the header by the column
+//	write FILE            write all rows to FILE (`-` for out)
+//
+// and the commands of `:` that work without the terminal like eol and
+// rename. ROW is the 1-based line number and COLUMN is the header name
+// or the 1-based column number. Messages of the commands are written to log.
+func (cfg Config) Batch(in io.Reader, script string, out, log io.Writer) (*Result, error) {
+	app, err := cfg.readAll(in, out)
+	if err != nil {
+		return nil, err
+	}
+	e := &exCommandArgs{
+		KeyEventArgs: &KeyEventArgs{
+			_Application: app,
+			CursorRow:    app.Front(),
+		},
+		view:     newView(),
+		fetchAll: func() error { return nil },
+		loading:  func() bool { return false },
+	}
+	for _, line := range splitBatchScript(script) {
+		message, err := e.runBatch(line)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", strings.TrimSpace(line), err)
+			e.notify(MessageError, err.Error())
+			return &Result{_Application: app, Saved: app.saved}, err
+		}
+		e.notify(MessageInfo, message)
+		if message != "" && log != nil {
+			fmt.Fprintln(log, message)
+		}
+	}
+	return &Result{_Application: app, Saved: app.saved}, nil
+}
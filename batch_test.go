@@ -0,0 +1,329 @@
+package csvi
+
+import (
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestBatchCommands(t *testing.T) {
+	dir := t.TempDir()
+	pairs := filepath.Join(dir, "pairs.csv")
+	if err := os.WriteFile(pairs, []byte("JP,Japan\nUS,United States\n"), 0666); err != nil {
+		t.Fatal(err.Error())
+	}
+	lookup := filepath.Join(dir, "lookup.tsv")
+	if err := os.WriteFile(lookup, []byte("label\tcode\nJapan\t81\n"), 0666); err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, c := range []struct {
+		cfg    Config
+		source string
+		script string
+		expect string
+		log    string
+	}{
+		{
+			source: "name,age\r\nbob,3\nann,\"4\"\r\ncat,5\n",
+			script: `set 3,age 40; set 2,name "x;y"; delete-row 4; move 1,2; rename years; write -`,
+			expect: "name,years\r\nx;y,3\nann,\"40\"\n",
+			log:    "renamed the column to years\n",
+		},
+		{
+			source: "name,age\nbob,10\nann,9\ncat,\"x\"\ndan,9\r\neve,-1",
+			script: `set 3,4 hello; delete-row 2; sort 2; write -`,
+			expect: "name,age\neve,-1\nann,9,,hello\ndan,9\r\ncat,\"x\"",
+		},
+		{
+			cfg:    Config{ModifiedColumn: "modified", TimestampLayout: "stamp"},
+			source: "name,modified\nbob,\nann,\n",
+			script: "set 3,name cat; set 1,name who; write -",
+			expect: "who,modified\nbob,\ncat,stamp\n",
+		},
+		{
+			source: "name,status\nbob,open\nann,\"wip\"\ncat,open\n",
+			script: `move 1,status; setcol -where name~^[ab] done; write -; setcol -undo; setcol "x y"; write -`,
+			expect: "name,status\nbob,done\nann,\"done\"\ncat,open\n" +
+				"name,status\nbob,x y\nann,\"x y\"\ncat,x y\n",
+			log: "set 2 cell(s) of status (`:setcol -undo` restores them)\n" +
+				"restored 2 cell(s)\n" +
+				"set 3 cell(s) of status (`:setcol -undo` restores them)\n",
+		},
+		{
+			source: "date\n12/31/2024\n\"01/02/2025\"\nunknown\n",
+			script: `transform "^(\\d+)/(\\d+)/(\\d+)$" $3-$1-$2; write -; transform -undo; write -`,
+			expect: "date\n2024-12-31\n\"2025-01-02\"\nunknown\n" +
+				"date\n12/31/2024\n\"01/02/2025\"\nunknown\n",
+			log: "transformed 2 cell(s) (`:transform -undo` restores them)\n" +
+				"restored 2 cell(s)\n",
+		},
+		{
+			source: "name,country\nbob,JP\nann,FR\ncat,US\n",
+			script: "move 1,country; map " + pairs + "; write -; map " + lookup + " label code; write -",
+			expect: "name,country\nbob,Japan\nann,FR\ncat,United States\n" +
+				"name,country\nbob,81\nann,FR\ncat,United States\n",
+			log: "replaced 2 cell(s), 1 value(s) not found (`:map -undo` restores them)\n" +
+				"replaced 1 cell(s), 2 value(s) not found (`:map -undo` restores them)\n",
+		},
+		{
+			source: "date\n12/31/2024\n\n01/02/2025\n",
+			script: `date iso; date us iso; write -; date -undo; set 3,1 2025-1-2; date us iso`,
+			expect: "date\n2024-12-31\n\n2025-01-02\n",
+			log: "2 cell(s) do not match 2006-01-02\n" +
+				"reformatted 2 cell(s) to 2006-01-02 (`:date -undo` restores them)\n" +
+				"restored 2 cell(s)\n" +
+				"1 cell(s) do not match 01/02/2006. Nothing is reformatted\n",
+		},
+		{
+			source: "first,id,last\nJohn,1,Smith\nMary Ann,2,Jones\n",
+			script: `joincol last,first ", "; write -; move 1,2; splitcol ", "; write -`,
+			expect: "id,\"last, first\"\n1,\"Smith, John\"\n2,\"Jones, Mary Ann\"\n" +
+				"id,\"last, first\",\"last, first_2\"\n1,Smith,John\n2,Jones,Mary Ann\n",
+			log: "joined 2 columns of 3 row(s)\n" +
+				"split the column into 2 columns\n",
+		},
+		{
+			source: "qty,price,paid\n 3 ,1.5,Yes\n2.0,abc,n\nx,2,maybe\n",
+			script: `convert int; move 1,2; convert float 2; move 1,3; convert bool; write -`,
+			expect: "qty,price,paid\n3,1.50,true\n2,abc,false\nx,2.00,maybe\n",
+			log: "converted 2 cell(s), 1 cell(s) do not match int\n" +
+				"converted 2 cell(s), 1 cell(s) do not match float 2\n" +
+				"converted 2 cell(s), 1 cell(s) do not match bool\n",
+		},
+	} {
+		cfg := c.cfg
+		cfg.Mode = &uncsv.Mode{Comma: ','}
+		cfg.HeaderLines = 1
+		var out, log strings.Builder
+		_, err := cfg.Batch(strings.NewReader(c.source), c.script, &out, &log)
+		if err != nil {
+			t.Fatalf("%s: %s", c.script, err.Error())
+		}
+		if out.String() != c.expect {
+			t.Fatalf("%s: expect %q but %q", c.script, c.expect, out.String())
+		}
+		if log.String() != c.log {
+			t.Fatalf("%s: expect the log %q but %q", c.script, c.log, log.String())
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	dir := t.TempDir()
+	source := "name,age\nbob,3\nann,4\ncat,5\n"
+	cfg := Config{Mode: &uncsv.Mode{Comma: ','}, HeaderLines: 1}
+	_, err := cfg.Batch(strings.NewReader(source),
+		"split -h 2 "+filepath.Join(dir, "out.csv"), io.Discard, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for name, expect := range map[string]string{
+		"out-001.csv": "name,age\nbob,3\nann,4\n",
+		"out-002.csv": "name,age\ncat,5\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if string(data) != expect {
+			t.Fatalf("%s: expect %q but %q", name, expect, string(data))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out-003.csv")); err == nil {
+		t.Fatal("out-003.csv should not be written")
+	}
+}
+
+func TestChunkName(t *testing.T) {
+	for source, expect := range map[string]string{
+		"out.csv":    "out-002.csv",
+		"out.csv.gz": "out-002.csv.gz",
+		"out":        "out-002",
+	} {
+		if result := chunkName(source, 2); result != expect {
+			t.Fatalf("chunkName(%q): expect %q but %q", source, expect, result)
+		}
+	}
+}
+
+func TestCut(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "out.csv")
+	source := "name,age,note\nbob,3,\"a,b\"\nann\n"
+	cfg := Config{Mode: &uncsv.Mode{Comma: ','}, HeaderLines: 1}
+	_, err := cfg.Batch(strings.NewReader(source), "cut note,1 "+fname, io.Discard, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if expect := "note,name\n\"a,b\",bob\n,ann\n"; string(data) != expect {
+		t.Fatalf("expect %q but %q", expect, string(data))
+	}
+}
+
+func TestSample(t *testing.T) {
+	source := "id\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10"
+	run := func(seed string) string {
+		cfg := Config{Mode: &uncsv.Mode{Comma: ','}, HeaderLines: 1}
+		var out strings.Builder
+		_, err := cfg.Batch(strings.NewReader(source), "sample -seed "+seed+" 30%; write -", &out, nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		return out.String()
+	}
+	result := run("1")
+	lines := strings.Split(result, "\n")
+	if len(lines) != 4 || lines[0] != "id" {
+		t.Fatalf("expect the header and 3 rows but %q", result)
+	}
+	if strings.HasSuffix(result, "\n") {
+		t.Fatalf("the terminator of the last row must be kept: %q", result)
+	}
+	if again := run("1"); again != result {
+		t.Fatalf("the same seed must give the same sample: %q and %q", result, again)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	source := "name,age\nbob,3\nann,4\n"
+	var log strings.Builder
+	cfg := Config{Mode: &uncsv.Mode{Comma: ','}, HeaderLines: 1, AuditLog: &log}
+	_, err := cfg.Batch(strings.NewReader(source), "set 2,age 30; delete-row 3", io.Discard, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expect 2 lines but %q", log.String())
+	}
+	for i, expect := range []string{
+		`"action":"replace","row":2,"column":2,"name":"age","old":"3","new":"30"}`,
+		`"action":"delete-row","row":3,"old":"ann,4","new":""}`,
+	} {
+		if !strings.HasSuffix(lines[i], expect) {
+			t.Fatalf("expect %q at the end of %q", expect, lines[i])
+		}
+	}
+}
+
+func TestStrict(t *testing.T) {
+	source := "a,b\n\"c\nd\",e\"f\"g\ng,h\x00\ni,\"j"
+	cfg := Config{Mode: &uncsv.Mode{Comma: ','}, Strict: true}
+	result, err := cfg.Batch(strings.NewReader(source), "", io.Discard, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expect := []parseWarning{
+		{lnum: 1, line: 2, col: 1, message: "bare quote inside the field"},
+		{lnum: 2, line: 4, col: 1, message: "NUL byte"},
+		{lnum: 3, line: 5, col: 1, message: "unterminated quote"},
+	}
+	warnings := result.parseState.warnings
+	if len(warnings) != len(expect) {
+		t.Fatalf("expect %v but %v", expect, warnings)
+	}
+	for i := range expect {
+		if warnings[i] != expect[i] {
+			t.Fatalf("expect %v but %v", expect[i], warnings[i])
+		}
+	}
+}
+
+func TestCutBatchArg(t *testing.T) {
+	for _, c := range [][3]string{
+		{`a b c`, "a", "b c"},
+		{`"a b" c`, "a b", "c"},
+		{`"a\"b"`, `a"b`, ""},
+	} {
+		arg, rest, err := cutBatchArg(c[0])
+		if err != nil || arg != c[1] || rest != c[2] {
+			t.Fatalf("%s: expect %q,%q but %q,%q (%v)", c[0], c[1], c[2], arg, rest, err)
+		}
+	}
+}
+
+func TestColumnStatesFollowColumns(t *testing.T) {
+	source := "first,last,born,age\nJohn,Smith,2000-01-02,3\n"
+	cfg := Config{Mode: &uncsv.Mode{Comma: ','}, HeaderLines: 1}
+	const declare = `move 1,3; date iso; move 1,4; convert int; `
+	for _, c := range []struct {
+		script string
+		date   int
+		int    int
+	}{
+		{declare, 2, 3},
+		{declare + `joincol first,last " "`, 1, 2},
+		{declare + `move 1,1; splitcol o`, 3, 4},
+		{declare + `move 1,3; splitcol -`, -1, 5},
+	} {
+		result, err := cfg.Batch(strings.NewReader(source), c.script, io.Discard, nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		expect := map[int]string{}
+		if c.date >= 0 {
+			expect[c.date] = "2006-01-02"
+		}
+		if !maps.Equal(result.dateLayouts, expect) {
+			t.Fatalf("%s: expect %v but %v", c.script, expect, result.dateLayouts)
+		}
+		if len(result.columnTypes) != 1 || result.columnTypes[c.int] == nil {
+			t.Fatalf("%s: expect int at %d but %v", c.script, c.int, result.columnTypes)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr is not found")
+	}
+	source := "name,id\nalice,1\nbob,2\n"
+	cfg := Config{Mode: &uncsv.Mode{Comma: ','}, HeaderLines: 1}
+	var out, log strings.Builder
+	_, err := cfg.Batch(strings.NewReader(source),
+		`%!tr a-z A-Z; move 2,2; !expr 1 + 10; write -`,
+		&out, &log)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expect := "name,id\nALICE,11\nBOB,2\n"
+	if out.String() != expect {
+		t.Fatalf("expect %q but %q", expect, out.String())
+	}
+	_, err = cfg.Batch(strings.NewReader(source), `%!head -n 1`, &out, &log)
+	if err == nil {
+		t.Fatal("the output fewer lines must be an error")
+	}
+}
+
+func TestOnMessage(t *testing.T) {
+	var messages []string
+	cfg := Config{
+		Mode:   &uncsv.Mode{Comma: ','},
+		Strict: true,
+		OnMessage: func(level, text string) {
+			messages = append(messages, level+":"+text)
+		},
+	}
+	_, err := cfg.Batch(strings.NewReader("a,b\"c\"d\ne,f\n"), `rename x; move 9,1`, nil, nil)
+	if err == nil {
+		t.Fatal("move 9,1 must be an error")
+	}
+	expect := []string{
+		"warning:line 1: bare quote inside the field",
+		"info:rename: no header lines",
+		"error:move 9,1: 9: no such row",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expect, "\n") {
+		t.Fatalf("expect %q but %q", expect, messages)
+	}
+}
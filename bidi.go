@@ -0,0 +1,51 @@
+package csvi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bidiControls are the characters which change the order to display
+// the following text. They can break the layout of the columns.
+var bidiControls = []rune{
+	'\u061C', // ARABIC LETTER MARK
+	'\u200E', // LEFT-TO-RIGHT MARK
+	'\u200F', // RIGHT-TO-LEFT MARK
+	'\u202A', // LEFT-TO-RIGHT EMBEDDING
+	'\u202B', // RIGHT-TO-LEFT EMBEDDING
+	'\u202C', // POP DIRECTIONAL FORMATTING
+	'\u202D', // LEFT-TO-RIGHT OVERRIDE
+	'\u202E', // RIGHT-TO-LEFT OVERRIDE
+	'\u2066', // LEFT-TO-RIGHT ISOLATE
+	'\u2067', // RIGHT-TO-LEFT ISOLATE
+	'\u2068', // FIRST STRONG ISOLATE
+	'\u2069', // POP DIRECTIONAL ISOLATE
+}
+
+var bidiEscaper = func() *strings.Replacer {
+	pairs := make([]string, 0, len(bidiControls)*2)
+	for _, c := range bidiControls {
+		pairs = append(pairs, string(c), fmt.Sprintf("<U+%04X>", c))
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+func hasBidiControl(s string) bool {
+	return strings.ContainsFunc(s, func(c rune) bool {
+		for _, b := range bidiControls {
+			if c == b {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// escapeBidi replaces bidirectional control characters with the notation
+// like <U+202E> when Config.EscapeBidi is true.
+func (cfg *Config) escapeBidi(s string) string {
+	if !cfg.EscapeBidi || !hasBidiControl(s) {
+		return s
+	}
+	return bidiEscaper.Replace(s)
+}
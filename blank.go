@@ -0,0 +1,56 @@
+package csvi
+
+import (
+	"strings"
+)
+
+func init() {
+	exCommands["blank"] = &exCommand{
+		help: "toggle showing empty cells and white spaces",
+		run: func(e *exCommandArgs) (string, error) {
+			e.ShowBlank = !e.ShowBlank
+			e.view.clearCache()
+			if e.ShowBlank {
+				return "blank on", nil
+			}
+			return "blank off", nil
+		},
+	}
+}
+
+func init() {
+	exCommands["strictgrid"] = &exCommand{
+		help: "toggle drawing every column in its own slot with `·` for empty cells",
+		run: func(e *exCommandArgs) (string, error) {
+			e.StrictGrid = !e.StrictGrid
+			e.view.clearCache()
+			if e.StrictGrid {
+				return "strictgrid on", nil
+			}
+			return "strictgrid off", nil
+		},
+	}
+}
+
+// mergesEmptyCells returns true when the empty cells are drawn as a part
+// of the previous cell
+func (cfg *Config) mergesEmptyCells() bool {
+	return !cfg.ShowBlank && !cfg.StrictGrid
+}
+
+const (
+	emptyCellMark = "·"
+	spaceMark     = "␣"
+)
+
+// visualizeBlank returns the text to draw for an empty cell or a cell
+// containing only white spaces. Other texts are returned as they are.
+func visualizeBlank(text string) string {
+	if text == "" {
+		return emptyCellMark
+	}
+	if strings.TrimSpace(text) == "" {
+		return strings.ReplaceAll(text, " ", spaceMark)
+	}
+	return text
+}
@@ -0,0 +1,13 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStrictGrid(t *testing.T) {
+	_, out := runEdit(t, &Config{StrictGrid: true}, "q|y", "x,y,z,w\na,,,b\n")
+	if !strings.Contains(out, "a\x1B[15G·\x1B[29G·\x1B[43Gb") {
+		t.Fatalf("the empty cells are not drawn in their slots: %q", out)
+	}
+}
@@ -0,0 +1,39 @@
+package csvi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// frameCapturer is the Pilot writing the output to the terminal since
+// the last input to Config.CaptureFrames before every input.
+type frameCapturer struct {
+	Pilot
+	frame bytes.Buffer
+	w     io.Writer
+	count int
+}
+
+func (f *frameCapturer) flush() {
+	f.count++
+	fmt.Fprintf(f.w, "--- frame %d ---\n", f.count)
+	f.w.Write(f.frame.Bytes())
+	io.WriteString(f.w, "\n")
+	f.frame.Reset()
+}
+
+func (f *frameCapturer) GetKey() (string, error) {
+	f.flush()
+	return f.Pilot.GetKey()
+}
+
+func (f *frameCapturer) ReadLine(out io.Writer, prompt, text string, c Candidate) (string, error) {
+	f.flush()
+	return f.Pilot.ReadLine(out, prompt, text, c)
+}
+
+func (f *frameCapturer) GetFilename(out io.Writer, prompt, name string) (string, error) {
+	f.flush()
+	return f.Pilot.GetFilename(out, prompt, name)
+}
@@ -0,0 +1,30 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCaptureFrames(t *testing.T) {
+	var frames strings.Builder
+	cfg := &Config{
+		CaptureFrames: &frames,
+		KeyMap: map[string]func(*KeyEventArgs) (*CommandResult, error){
+			"j": func(e *KeyEventArgs) (*CommandResult, error) {
+				return &CommandResult{Message: "custom j"}, nil
+			},
+		},
+	}
+	runEdit(t, cfg, "j|r|X|q|y", "a,b\nc,d\n")
+	// frames before j, r, the line X, q and y
+	parts := strings.Split(frames.String(), "--- frame ")
+	if len(parts) != 6 {
+		t.Fatalf("expect 5 frames but %d", len(parts)-1)
+	}
+	if !strings.HasPrefix(parts[2], "2 ---\n") || !strings.Contains(parts[2], "custom j") {
+		t.Fatalf("the message of KeyMap is not drawn: %q", parts[2])
+	}
+	if !strings.Contains(parts[4], "X") {
+		t.Fatalf("the cell replaced is not drawn: %q", parts[4])
+	}
+}
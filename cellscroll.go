@@ -0,0 +1,66 @@
+package csvi
+
+import (
+	"fmt"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func init() {
+	exCommands["cellscroll"] = &exCommand{
+		help: "toggle scrolling the text of the current cell by a character with h and l",
+		run: func(e *exCommandArgs) (string, error) {
+			e.CellScroll = !e.CellScroll
+			e.cellOffset = 0
+			if e.CellScroll {
+				return "cellscroll on", nil
+			}
+			return "cellscroll off", nil
+		},
+	}
+}
+
+// skipChars returns text without the first n characters
+func skipChars(text string, n int) string {
+	for i := range text {
+		if n <= 0 {
+			return text[i:]
+		}
+		n--
+	}
+	return ""
+}
+
+// cursorTextWidth returns the width in which the text of the cell at
+// cursorCol is drawn, in the same way as drawLine.
+func (cfg *Config) cursorTextWidth(row *RowPtr, startCol, cursorCol, cellWidth, screenWidth int) int {
+	w := cfg.columnWidth(cursorCol, cellWidth)
+	n := cursorCol + 1
+	for n < len(row.Cell) && row.Cell[n].Text() == "" && cfg.mergesEmptyCells() {
+		w += cfg.columnWidth(n, cellWidth)
+		n++
+	}
+	rest := screenWidth - 1 - cfg.screenX(cursorCol, startCol, cellWidth)
+	if w > rest || n >= len(row.Cell) {
+		return rest
+	}
+	return w - runewidth.StringWidth(cfg.ColumnSeparator)
+}
+
+// canScrollCell returns true when CellScroll is enabled and the rest of
+// the text of the current cell after cellOffset is wider than the cell.
+func (cfg *Config) canScrollCell(row *RowPtr, startCol, cursorCol, cellWidth, screenWidth int) bool {
+	if !cfg.CellScroll || cfg.Wrap || cursorCol >= len(row.Cell) {
+		return false
+	}
+	text := skipChars(cfg.displayText(row.Cell[cursorCol]), cfg.cellOffset)
+	return runewidth.StringWidth(text) > cfg.cursorTextWidth(row, startCol, cursorCol, cellWidth, screenWidth)
+}
+
+// offsetStatus returns the field {offset} of the status line
+func (cfg *Config) offsetStatus() string {
+	if cfg.cellOffset <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("+%d", cfg.cellOffset)
+}
@@ -0,0 +1,22 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCellScroll(t *testing.T) {
+	cfg := &Config{CellScroll: true}
+	_, out := runEdit(t, cfg, "j|l|l|l|l|l|h|q|y", "id,text,z\n1,abcdefghijklmnopqrstuvwxyz0123456789,q\n")
+	if !strings.Contains(out, "(2+3,2/2)") {
+		t.Fatalf("the offset is not shown: %q", out)
+	}
+	if !strings.Contains(out, "defghijklmnop") {
+		t.Fatalf("the text is not scrolled: %q", out)
+	}
+
+	_, out = runEdit(t, cfg, "j|l|l|l|q|y", "id,text,z\n1,short,q\n")
+	if !strings.Contains(out, "(3,2/2)") {
+		t.Fatalf("the cursor does not move over the cell fitting in the column: %q", out)
+	}
+}
@@ -0,0 +1,50 @@
+package csvi
+
+// CellStyleEvent is given to Config.OnCellStyle and Config.OnCellProgress
+// for the cells of the body
+type CellStyleEvent struct {
+	Text string
+	Col  int
+	// Column is the name of the column on the header, or empty without
+	// the header
+	Column string
+}
+
+// updateColumnNames keeps the names of the columns given to OnCellStyle
+// and OnCellProgress
+func (app *_Application) updateColumnNames() {
+	if app.OnCellStyle == nil && app.OnCellProgress == nil {
+		app.columnNames = nil
+		return
+	}
+	app.columnNames = app.Columns()
+}
+
+// cellStyle returns the escape sequence of OnCellStyle for the cell, or
+// of the outliers or the heatmap when OnCellStyle returns ""
+func (cfg *Config) cellStyle(col int, text string) string {
+	if cfg.OnCellStyle == nil {
+		return cfg.builtinStyle(col, text)
+	}
+	if style := cfg.OnCellStyle(cfg.cellEvent(col, text)); style != "" {
+		return style
+	}
+	return cfg.builtinStyle(col, text)
+}
+
+func (cfg *Config) cellEvent(col int, text string) *CellStyleEvent {
+	e := &CellStyleEvent{Text: text, Col: col}
+	if col < len(cfg.columnNames) {
+		e.Column = cfg.columnNames[col]
+	}
+	return e
+}
+
+// builtinStyle returns the escape sequence of the outliers by `:outliers`
+// or of the heatmap by `:heatmap`
+func (cfg *Config) builtinStyle(col int, text string) string {
+	if cfg.isOutlier(col, text) {
+		return outlierStyle
+	}
+	return cfg.heatStyle(col, text)
+}
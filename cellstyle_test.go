@@ -0,0 +1,25 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOnCellStyle(t *testing.T) {
+	cfg := &Config{
+		HeaderLines: 1,
+		OnCellStyle: func(e *CellStyleEvent) string {
+			if e.Column == "status" && e.Text == "ERROR" {
+				return "\x1B[31m"
+			}
+			return ""
+		},
+	}
+	_, out := runEdit(t, cfg, "q|y", "job,status\nA,OK\nB,ERROR\n")
+	if !strings.Contains(out, "\x1B[31mERROR") {
+		t.Fatalf("the cell is not painted: %q", out)
+	}
+	if strings.Contains(out, "\x1B[31mOK") || strings.Contains(out, "\x1B[31mstatus") {
+		t.Fatalf("the other cells are painted: %q", out)
+	}
+}
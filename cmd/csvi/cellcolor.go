@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hymkor/csvi"
+)
+
+// colorCodes are the names of the colors of -cellcolor
+var colorCodes = map[string]string{
+	"black":   "\x1B[30m",
+	"red":     "\x1B[31m",
+	"green":   "\x1B[32m",
+	"yellow":  "\x1B[33m",
+	"blue":    "\x1B[34m",
+	"magenta": "\x1B[35m",
+	"cyan":    "\x1B[36m",
+	"white":   "\x1B[37m",
+	"gray":    "\x1B[90m",
+}
+
+// cellColors are the rules of -cellcolor: the colors of the values for
+// each column named on the header or numbered from 1
+type cellColors map[string]map[string]string
+
+// String is required by flag.Value
+func (cc cellColors) String() string {
+	return ""
+}
+
+// Set adds the rules like `status:ERROR=red,OK=green`
+func (cc cellColors) Set(s string) error {
+	col, rules, ok := strings.Cut(s, ":")
+	if !ok || col == "" || rules == "" {
+		return fmt.Errorf("%s: must be COL:VALUE=COLOR,VALUE=COLOR...", s)
+	}
+	colors := cc[col]
+	if colors == nil {
+		colors = map[string]string{}
+		cc[col] = colors
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		value, name, ok := strings.Cut(rule, "=")
+		if !ok {
+			return fmt.Errorf("%s: must be VALUE=COLOR", rule)
+		}
+		code, ok := colorCodes[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("%s: unknown color", name)
+		}
+		colors[value] = code
+	}
+	return nil
+}
+
+// style is Config.OnCellStyle painting the cells matching the rules
+func (cc cellColors) style(e *csvi.CellStyleEvent) string {
+	colors := cc[e.Column]
+	if colors == nil {
+		colors = cc[strconv.Itoa(e.Col+1)]
+	}
+	return colors[e.Text]
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// checkRoundTrip reports whether opening and saving each file would make
+// it byte-identical, and returns an error when some files would not be.
+func checkRoundTrip(args []string, mode *uncsv.Mode, w io.Writer) error {
+	if len(args) <= 0 {
+		args = []string{"-"}
+	}
+	failed := 0
+	for _, fname := range args {
+		var r io.Reader = os.Stdin
+		if fname != "-" {
+			r = multiFileReader(fname)
+		}
+		m := *mode
+		if err := uncsv.VerifyRoundTrip(r, &m); err != nil {
+			fmt.Fprintf(w, "%s: %s\n", fname, err.Error())
+			failed++
+		} else {
+			fmt.Fprintf(w, "%s: ok\n", fname)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("-check: %d of %d file(s) would not be saved as they are", failed, len(args))
+	}
+	return nil
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/mattn/go-colorable"
@@ -22,6 +24,8 @@ var (
 	flagCsv           = flag.Bool("c", false, "use Comma as field-separator")
 	flagSemicolon     = flag.Bool("semicolon", false, "use Semicolon as field-separator")
 	flagIana          = flag.String("iana", "", "IANA-registered-name to decode/encode NonUTF8 text(for example: Shift_JIS,EUC-JP... )")
+	flagEncoding      = flag.String("encoding", "", "the encoding of NonUTF8 text (for example: sjis,euc-jp,latin1,cp1252...)")
+	flagDetect        = flag.Bool("detect", runtime.GOOS != "windows", "guess the encoding of NonUTF8 text and confirm it")
 	flagNonUTF8       = flag.Bool("nonutf8", false, "do not judge as utf8")
 	flagHelp          = flag.Bool("help", false, "this help")
 	flagAuto          = flag.String("auto", "", "autopilot")
@@ -30,8 +34,165 @@ var (
 	flagFixColumn     = flag.Bool("fixcol", false, "Do not insert/delete a column")
 	flagReadOnly      = flag.Bool("readonly", false, "Read Only Mode")
 	flagProtectHeader = flag.Bool("p", false, "Protect the header line")
+	flagLockHeader    = flag.Bool("lockheader", false, "Keep the cursor out of the header lines")
+	flagNoTitle       = flag.Bool("notitle", false, "Do not change the title of the terminal window")
+	flagPreview       = flag.Uint("preview", 0, "the number of lines(1-3) to preview the current cell")
+	flagWrap          = flag.Bool("wrap", false, "Wrap long texts of cells")
+	flagMarker        = flag.String("marker", "…", "the mark drawn at the end of cut cells")
+	flagGrid          = flag.Bool("grid", false, "Draw lines between columns and under the header")
+	flagBlank         = flag.Bool("blank", false, "Show empty cells and white spaces")
+	flagAutoWidth     = flag.Int("autowidth", 100, "derive the widths of columns from the header and the first N rows (0: all columns are as wide as -w. The default is 0 when -w is given)")
+	flagElastic       = flag.Bool("elastic", false, "widen the columns to fill the screen when all of them are narrower than it")
+	flagElasticCol    = flag.String("elastic-col", "", "the column taking all the spare width of -elastic (the name on the header or the number)")
+	flagMinWidth      = flag.String("minwidth", "", "the minimum widths of columns like id=10,3=20 (COL is the name on the header or the number)")
+	flagMaxWidth      = flag.String("maxwidth", "", "the maximum widths of columns like note=30 (COL is the name on the header or the number)")
+	flagSummary       = flag.String("summary", "", "draw the summaries of the numeric columns under the rows: spark (sparklines) or stats (min..max avg)")
+	flagProgress      = flag.String("progress", "", "the columns whose percentages are drawn as progress bars separated by commas (COL is the name on the header or the number)")
+	flagStrictGrid    = flag.Bool("strictgrid", false, "Draw every column in its own slot with a mark for empty cells instead of merging them with the previous cell")
+	flagCellScroll    = flag.Bool("cellscroll", false, "h and l scroll the text of the current cell wider than the column by a character")
+	flagConfirm       = flag.String("confirm", "quit,overwrite", "the confirmations to ask separated by commas: quit, overwrite and delete-row (empty: none)")
+	flagAmbiguous     = flag.Uint("aw", 0, "the width of East Asian Ambiguous characters (1 or 2. 0: measure on the terminal)")
+	flagNFC           = flag.Bool("nfc", false, "Draw texts normalized in NFC")
+	flagEscapeBidi    = flag.Bool("escbidi", false, "Draw bidirectional control characters as <U+XXXX>")
+	flagControlHex    = flag.Bool("ctrlhex", false, "Draw control characters as <0x07> instead of control pictures")
+	flagBatch         = flag.String("batch", "", "Apply the editing commands separated by `;` without the terminal (for example: 'set 3,4 hello; delete-row 7; sort 2; write out.csv')")
+	flagPrintTable    = flag.Bool("print-table", false, "Print the data as an aligned table without the terminal")
+	flagColor         = flag.Bool("color", false, "Paint the table of -print-table")
+	flagPick          = flag.Bool("pick", false, "Choose a row with Enter and write it to STDOUT without editing (the screen is drawn on STDERR)")
+	flagMultiPick     = flag.Bool("multipick", false, "Check rows with Space and write them to STDOUT with Enter as -pick")
+	flagOutput        = flag.Bool("output", false, "Write the edited data to STDOUT on quit (the screen is drawn on STDERR)")
+	flagEol           = flag.String("eol", "", "Force the terminator of rows on writing (lf or crlf)")
+	flagStatusFormat  = flag.String("status", csvi.DefaultStatusFormat, "the format of the status line")
+	flagPseudoHeader  = flag.String("pseudoheader", "", "the header drawn when -h 0: letter (A,B,C...) or first (pin the first row)")
+	flagDelimiter     = flag.String("d", "", "the field-separator (a character or tab)")
+	flagGoto          = flag.String("goto", "", "the position to start at as ROW:COLUMN or ROW (COLUMN is the number or the name on the header)")
+	flagSearch        = flag.String("search", "", "the text to search and start at")
+	flagAutoInc       = flag.String("autoinc", "", "the column (name or number) filled with max+1 on new rows")
+	flagCreated       = flag.String("created", "", "the column (name or number) filled with the time on new rows")
+	flagModified      = flag.String("modified", "", "the column (name or number) updated with the time when the row is changed")
+	flagTimeFormat    = flag.String("timefmt", csvi.DefaultTimestampLayout, "the layout of the time for -created and -modified (in the format of Go)")
+	flagAudit         = flag.String("audit", "", "the file to append every edit to as JSON Lines")
+	flagGitCommit     = flag.Bool("gitcommit", false, "Ask a message and commit the file to git on saving")
+	flagMerge         = flag.Bool("merge", false, "Merge the files BASE OURS THEIRS and resolve the conflicts (with -merge-out and -key)")
+	flagMergeOutput   = flag.String("merge-out", "", "the file to write the result of -merge")
+	flagMergeKey      = flag.String("key", "1", "the column (name or number) to match the rows on -merge")
+	flagCheck         = flag.Bool("check", false, "Check that opening and saving the files would keep them byte-identical")
+	flagStrict        = flag.Bool("strict", false, "Record the problems of the data like unterminated quotes on reading (listed by :warnings)")
+	flagMaxCell       = flag.Int("maxcell", 0, "the maximum length in bytes of a cell whose text is shown (0: no limit)")
+	flagMaxCellMarker = flag.String("maxcell-marker", "", "the mark drawn after cells cut by -maxcell")
+	flagInitialRows   = flag.Int("initrows", csvi.DefaultInitialRows, "the number of rows read before the screen is drawn first")
+	flagReadAhead     = flag.Int("readahead", csvi.DefaultReadAheadRows, "the number of rows read at once while no keys are typed")
+	flagResidentRows  = flag.Int("resident-rows", 0, "the number of rows kept in memory around the cursor with -readonly (0: all)")
+	flagRecord        = flag.String("record", "", "write the keys and the screen sizes to FILE to reproduce the session with -replay")
+	flagReplay        = flag.String("replay", "", "replay the session recorded by -record")
+	flagDebug         = flag.String("debug", "", "write the keys, the time to draw the frames, the rows fetched and the memory statistics to FILE")
+	flagTemplate      = flag.String("template", "", "the default values of new rows separated by commas ({today} and {now} are replaced)")
 )
 
+var flagCellColor = cellColors{}
+
+func init() {
+	flag.Var(flagCellColor, "cellcolor", "paint the cells by the values of the column as COL:VALUE=COLOR,VALUE=COLOR... (COL is the name on the header or the number. It can be given for each column)")
+	// long names of the options
+	flag.UintVar(flagHeader, "header", *flagHeader, "the number of row-header (same as -h)")
+	flag.BoolVar(flagTsv, "tsv", *flagTsv, "use TAB as field-separator (same as -t)")
+	flag.BoolVar(flagCsv, "csv", *flagCsv, "use Comma as field-separator (same as -c)")
+	flag.BoolVar(flagFixColumn, "fix-column", *flagFixColumn, "Do not insert/delete a column (same as -fixcol)")
+	flag.BoolVar(flagProtectHeader, "protect-header", *flagProtectHeader, "Protect the header line (same as -p)")
+}
+
+// parseDelimiter returns the field-separator given by -d
+func parseDelimiter(s string) (byte, error) {
+	switch strings.ToLower(s) {
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	if len(s) != 1 {
+		return 0, fmt.Errorf("-d %s: the field-separator must be one ASCII character", s)
+	}
+	return s[0], nil
+}
+
+// parseTemplate returns the values given by -template as a CSV line
+func parseTemplate(s string) ([]string, error) {
+	row, err := uncsv.ReadLine(bufio.NewReader(strings.NewReader(s)), &uncsv.Mode{Comma: ','})
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	values := make([]string, len(row.Cell))
+	for i, c := range row.Cell {
+		values[i] = c.Text()
+	}
+	return values, nil
+}
+
+// parseGoto returns the 1-based position given by -goto as ROW:COLUMN or
+// ROW. The COLUMN not a number is returned as name of the header.
+func parseGoto(s string) (row, col int, name string, err error) {
+	r, c, hasCol := strings.Cut(s, ":")
+	row, err = strconv.Atoi(r)
+	if err == nil && hasCol {
+		if n, err1 := strconv.Atoi(c); err1 == nil {
+			col = n
+		} else if c != "" {
+			name = c
+		} else {
+			err = err1
+		}
+	}
+	if err != nil || row < 0 || col < 0 {
+		return 0, 0, "", fmt.Errorf("-goto %s: must be ROW:COLUMN or ROW", s)
+	}
+	return row, col, name, nil
+}
+
+// isFlagGiven returns true when the option name is given on the command line
+func isFlagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
+// parseColumnWidths parses the widths of columns like `id=10,3=20`
+func parseColumnWidths(option, s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	widths := map[string]int{}
+	for _, pair := range strings.Split(s, ",") {
+		col, value, ok := strings.Cut(pair, "=")
+		width, err := strconv.Atoi(value)
+		if !ok || col == "" || err != nil || width <= 0 {
+			return nil, fmt.Errorf("-%s %s: must be COL=WIDTH,COL=WIDTH...", option, s)
+		}
+		widths[col] = width
+	}
+	return widths, nil
+}
+
+// parseConfirm sets the confirmations of -confirm to cfg
+func parseConfirm(s string, cfg *csvi.Config) error {
+	asked := map[string]bool{}
+	if s != "" {
+		for _, kind := range strings.Split(s, ",") {
+			switch kind {
+			case csvi.ConfirmQuit, csvi.ConfirmOverwrite, csvi.ConfirmDeleteRow:
+				asked[kind] = true
+			default:
+				return fmt.Errorf("-confirm %s: must be quit, overwrite or delete-row", s)
+			}
+		}
+	}
+	cfg.NoConfirmQuit = !asked[csvi.ConfirmQuit]
+	cfg.NoConfirmOverwrite = !asked[csvi.ConfirmOverwrite]
+	cfg.ConfirmDeleteRow = asked[csvi.ConfirmDeleteRow]
+	return nil
+}
+
 const (
 	_ANSI_CURSOR_OFF = "\x1B[?25l"
 	_ANSI_CURSOR_ON  = "\x1B[?25h"
@@ -50,8 +211,18 @@ func mains() error {
 
 	var pilot csvi.Pilot
 	if *flagAuto != "" {
-		pilot = &_AutoPilot{script: *flagAuto}
+		pilot = csvi.NewAutoPilot(*flagAuto)
 		defer pilot.Close()
+	} else if *flagReplay != "" {
+		fd, err := os.Open(*flagReplay)
+		if err != nil {
+			return err
+		}
+		pilot, err = csvi.NewReplayPilot(fd)
+		fd.Close()
+		if err != nil {
+			return err
+		}
 	}
 	mode := &uncsv.Mode{}
 	if *flagIana != "" {
@@ -59,6 +230,12 @@ func mains() error {
 			return fmt.Errorf("-iana %w", err)
 		}
 	}
+	if *flagEncoding != "" {
+		if err := mode.SetEncoding(*flagEncoding); err != nil {
+			return fmt.Errorf("-encoding %w", err)
+		}
+		mode.NonUTF8 = true
+	}
 	if *flagNonUTF8 {
 		mode.NonUTF8 = true
 	}
@@ -68,21 +245,38 @@ func mains() error {
 	if *flag16be {
 		mode.SetUTF16BE()
 	}
+	mode.MaxCellLength = *flagMaxCell
+	mode.TruncatedMarker = *flagMaxCellMarker
+	// Cells are mostly viewed in the read-only mode, so decode them on demand
+	mode.LazyText = *flagReadOnly
+	switch strings.ToLower(*flagEol) {
+	case "":
+	case "lf":
+		mode.ForceTerm = "\n"
+	case "crlf":
+		mode.ForceTerm = "\r\n"
+	default:
+		return fmt.Errorf("-eol %s: must be lf or crlf", *flagEol)
+	}
 
 	var out io.Writer
 	var reader io.Reader
-	if len(flag.Args()) <= 0 {
+	newFile := false
+	args, start, err := splitStartArgs(flag.Args())
+	if err != nil {
+		return err
+	}
+	if len(args) <= 0 || *flagOutput || *flagPick || *flagMultiPick {
 		out = colorable.NewColorableStderr()
 	} else {
 		out = colorable.NewColorableStdout()
 	}
-	if len(flag.Args()) <= 0 && isatty.IsTerminal(uintptr(os.Stdin.Fd())) {
+	if len(args) <= 0 && isatty.IsTerminal(uintptr(os.Stdin.Fd())) {
 		// Start with one empty line
 		mode.Comma = '\t'
 	} else {
 		mode.Comma = ','
-		args := flag.Args()
-		if len(args) >= 1 && !strings.HasSuffix(strings.ToLower(args[0]), ".csv") {
+		if len(args) >= 1 && !strings.HasSuffix(strings.ToLower(trimCompressSuffix(args[0])), ".csv") {
 			mode.Comma = '\t'
 		}
 		if *flagTsv {
@@ -94,22 +288,232 @@ func mains() error {
 		if *flagSemicolon {
 			mode.Comma = ';'
 		}
-		reader = multiFileReader(args...)
+		if *flagDelimiter != "" {
+			d, err := parseDelimiter(*flagDelimiter)
+			if err != nil {
+				return err
+			}
+			mode.Comma = d
+		}
+		if isNewFile(args) && *flagBatch == "" && !*flagPrintTable && !*flagReadOnly {
+			newFile = true
+		} else {
+			reader = multiFileReader(args...)
+			if *flagResidentRows > 0 {
+				if fd := residentFile(args); fd != nil {
+					defer fd.Close()
+					reader = fd
+				}
+			}
+		}
+	}
+
+	filename := editFilename(args)
+	cfg := csvi.Config{
+		Mode:            mode,
+		Pilot:           pilot,
+		CellWidth:       int(*flagCellWidth),
+		HeaderLines:     int(*flagHeader),
+		FixColumn:       *flagFixColumn,
+		ReadOnly:        *flagReadOnly || hasURL(args),
+		ProtectHeader:   *flagProtectHeader,
+		LockHeaderRows:  *flagLockHeader,
+		Filename:        filename,
+		StatusFormat:    *flagStatusFormat,
+		SetTitle:        !*flagNoTitle,
+		PreviewLines:    int(*flagPreview),
+		Wrap:            *flagWrap,
+		TruncateMarker:  *flagMarker,
+		ShowBlank:       *flagBlank,
+		CellScroll:      *flagCellScroll,
+		StrictGrid:      *flagStrictGrid,
+		AmbiguousWidth:  int(*flagAmbiguous),
+		NormalizeNFC:    *flagNFC,
+		EscapeBidi:      *flagEscapeBidi,
+		ControlHex:      *flagControlHex,
+		DetectEncoding:  *flagDetect,
+		ReadAllOnQuit:   *flagOutput,
+		PickMode:        *flagPick,
+		MultiPick:       *flagMultiPick,
+		NewFile:         newFile,
+		AutoIncrement:   *flagAutoInc,
+		GitCommit:       *flagGitCommit,
+		CreatedColumn:   *flagCreated,
+		ModifiedColumn:  *flagModified,
+		TimestampLayout: *flagTimeFormat,
+		Strict:          *flagStrict,
+		InitialRows:     *flagInitialRows,
+		ReadAheadRows:   *flagReadAhead,
+		ResidentRows:    *flagResidentRows,
+	}
+	if *flagRecord != "" {
+		fd, err := os.Create(*flagRecord)
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		cfg.Record = fd
+	}
+	if *flagDebug != "" {
+		fd, err := os.Create(*flagDebug)
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		cfg.DebugLog = fd
+	}
+	switch *flagPseudoHeader {
+	case "", csvi.PseudoHeaderLetter, csvi.PseudoHeaderFirst:
+		cfg.PseudoHeader = *flagPseudoHeader
+	default:
+		return fmt.Errorf("-pseudoheader %s: must be letter or first", *flagPseudoHeader)
+	}
+	cfg.AutoWidthRows = *flagAutoWidth
+	if isFlagGiven("w") && !isFlagGiven("autowidth") {
+		cfg.AutoWidthRows = 0
+	}
+	cfg.Elastic = *flagElastic || *flagElasticCol != ""
+	cfg.ElasticColumn = *flagElasticCol
+	if cfg.ColumnMinWidth, err = parseColumnWidths("minwidth", *flagMinWidth); err != nil {
+		return err
+	}
+	if cfg.ColumnMaxWidth, err = parseColumnWidths("maxwidth", *flagMaxWidth); err != nil {
+		return err
+	}
+	if err = parseConfirm(*flagConfirm, &cfg); err != nil {
+		return err
+	}
+	if len(flagCellColor) > 0 {
+		cfg.OnCellStyle = flagCellColor.style
+	}
+	switch *flagSummary {
+	case "", csvi.SummarySpark, csvi.SummaryStats:
+		cfg.Summary = *flagSummary
+	default:
+		return fmt.Errorf("-summary %s: must be spark or stats", *flagSummary)
+	}
+	if *flagProgress != "" {
+		cfg.ProgressColumns = strings.Split(*flagProgress, ",")
+	}
+	if *flagGoto != "" {
+		var err error
+		cfg.StartRow, cfg.StartCol, cfg.StartColumn, err = parseGoto(*flagGoto)
+		if err != nil {
+			return err
+		}
+	}
+	cfg.StartSearch = *flagSearch
+	if *flagAudit != "" {
+		fd, err := os.OpenFile(*flagAudit, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		cfg.AuditLog = fd
+	}
+	if *flagTemplate != "" {
+		var err error
+		cfg.RowTemplate, err = parseTemplate(*flagTemplate)
+		if err != nil {
+			return fmt.Errorf("-template %w", err)
+		}
+	}
+	if start.row > 0 {
+		cfg.StartRow = start.row
+	}
+	if start.search != "" {
+		cfg.StartSearch = start.search
+	}
+	cfg.StartCommand = start.command
+	if *flagGrid {
+		cfg.ColumnSeparator = "│"
+		cfg.HeaderRule = "─"
+	}
+	if *flagCheck {
+		return checkRoundTrip(args, mode, os.Stdout)
+	}
+	if *flagPrintTable {
+		var stdout io.Writer = os.Stdout
+		if *flagColor {
+			stdout = colorable.NewColorableStdout()
+		}
+		return cfg.PrintTable(reader, stdout, *flagColor)
+	}
+	if *flagBatch != "" {
+		_, err := cfg.Batch(reader, *flagBatch, os.Stdout, os.Stderr)
+		return err
+	}
+	if *flagMerge {
+		return runMerge(cfg, args, *flagMergeOutput, *flagMergeKey, out)
 	}
 	io.WriteString(out, _ANSI_CURSOR_OFF)
 	defer io.WriteString(out, _ANSI_CURSOR_ON)
 
-	_, err := csvi.Config{
-		Mode:          mode,
-		Pilot:         pilot,
-		CellWidth:     int(*flagCellWidth),
-		HeaderLines:   int(*flagHeader),
-		FixColumn:     *flagFixColumn,
-		ReadOnly:      *flagReadOnly,
-		ProtectHeader: *flagProtectHeader,
-	}.Edit(reader, out)
-
-	return err
+	result, err := cfg.Edit(reader, out)
+	if err != nil {
+		return err
+	}
+	if *flagPick || *flagMultiPick {
+		rows := result.Checked
+		if len(rows) <= 0 && result.Picked != nil {
+			rows = []*uncsv.Row{result.Picked}
+		}
+		w := bufio.NewWriter(os.Stdout)
+		for _, row := range rows {
+			w.Write(row.Rebuild(mode))
+		}
+		return w.Flush()
+	}
+	if *flagOutput {
+		w := bufio.NewWriter(os.Stdout)
+		result.Each(func(row *uncsv.Row) bool {
+			w.Write(row.Rebuild(mode))
+			return true
+		})
+		return w.Flush()
+	}
+	return nil
+}
+
+type startArgs struct {
+	row     int
+	search  string
+	command string
+}
+
+// splitStartArgs removes the arguments like less/vim
+// (+N, +/PATTERN and +:COMMAND) from args
+func splitStartArgs(args []string) ([]string, *startArgs, error) {
+	start := &startArgs{}
+	files := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "+") {
+			files = append(files, arg)
+			continue
+		}
+		switch value := arg[1:]; {
+		case strings.HasPrefix(value, "/"):
+			start.search = value[1:]
+		case strings.HasPrefix(value, ":"):
+			start.command = value[1:]
+		default:
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, nil, fmt.Errorf("%s: must be +N, +/PATTERN or +:COMMAND", arg)
+			}
+			start.row = n
+		}
+	}
+	return files, start, nil
+}
+
+func hasURL(args []string) bool {
+	for _, arg := range args {
+		if isURL(arg) {
+			return true
+		}
+	}
+	return false
 }
 
 var version string
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hymkor/csvi"
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func readRows(fname string, mode *uncsv.Mode) ([]uncsv.Row, error) {
+	fd, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	rows, err := uncsv.ReadAll(fd, mode)
+	if err != nil {
+		return nil, err
+	}
+	if L := len(rows); L > 0 && (len(rows[L-1].Cell) == 0 ||
+		(len(rows[L-1].Cell) == 1 && len(rows[L-1].Cell[0].Source()) <= 0)) {
+		rows = rows[:L-1]
+	}
+	return rows, nil
+}
+
+// runMerge merges ours and theirs changed from base, lets the user resolve
+// the conflicts and writes the result to output. output is not written
+// while some conflicts are not resolved.
+func runMerge(cfg csvi.Config, args []string, output, key string, out io.Writer) error {
+	if len(args) != 3 {
+		return errors.New("-merge: BASE OURS THEIRS are required")
+	}
+	if output == "" {
+		return errors.New("-merge: -merge-out FILE is required")
+	}
+	var sides [3][]uncsv.Row
+	for i, fname := range args {
+		var err error
+		sides[i], err = readRows(fname, cfg.Mode)
+		if err != nil {
+			return err
+		}
+	}
+	m, err := csvi.MergeRows(sides[0], sides[1], sides[2], cfg.HeaderLines, key, cfg.Mode)
+	if err != nil {
+		return fmt.Errorf("-merge: %w", err)
+	}
+	cfg.Filename = output
+	if n := m.Unresolved(); n > 0 {
+		cfg.Message = fmt.Sprintf("%d conflict(s): `]`/`[` jump, `<` ours, `>` theirs, `=` shows both", n)
+		cfg.KeyMap = m.KeyMap()
+		cfg.Saver = csvi.SaveFunc(func(e *csvi.SaveEvent) error {
+			if n := m.Unresolved(); n > 0 && e.Filename == output {
+				return fmt.Errorf("%s: not written with %d conflict(s) left", output, n)
+			}
+			return csvi.FileSaver{}.Save(e)
+		})
+		io.WriteString(out, _ANSI_CURSOR_OFF)
+		defer io.WriteString(out, _ANSI_CURSOR_ON)
+		result, err := cfg.EditRows(m.Rows, out)
+		if err != nil {
+			return err
+		}
+		if n := m.Unresolved(); n > 0 {
+			return fmt.Errorf("%s: not written with %d conflict(s) not resolved by `<` or `>`", output, n)
+		}
+		return result.WriteFile(output)
+	}
+	fd, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	rows := m.Rows
+	cfg.Mode.DumpBy(func() *uncsv.Row {
+		if len(rows) <= 0 {
+			return nil
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row
+	}, fd)
+	return fd.Close()
+}
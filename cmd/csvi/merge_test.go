@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hymkor/csvi"
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestRunMergeUnresolved(t *testing.T) {
+	dir := t.TempDir()
+	var args []string
+	for _, c := range []struct{ name, data string }{
+		{"base.csv", "id,age\n1,3\n"},
+		{"ours.csv", "id,age\n1,30\n"},
+		{"theirs.csv", "id,age\n1,31\n"},
+	} {
+		fname := filepath.Join(dir, c.name)
+		if err := os.WriteFile(fname, []byte(c.data), 0666); err != nil {
+			t.Fatal(err.Error())
+		}
+		args = append(args, fname)
+	}
+	output := filepath.Join(dir, "out.csv")
+	cfg := csvi.Config{
+		Mode:        &uncsv.Mode{Comma: ','},
+		HeaderLines: 1,
+		Pilot:       csvi.NewAutoPilot("q|y"),
+	}
+	if err := runMerge(cfg, args, output, "id", io.Discard); err == nil {
+		t.Fatal("expect the error for the conflict not resolved")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatalf("%s is written with the conflict", output)
+	}
+}
+
+func TestReadRowsEmpty(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "empty.csv")
+	if err := os.WriteFile(fname, nil, 0666); err != nil {
+		t.Fatal(err.Error())
+	}
+	rows, err := readRows(fname, &uncsv.Mode{Comma: ','})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(rows) != 0 {
+		t.Fatalf("expect no rows but %d", len(rows))
+	}
+}
@@ -1,30 +1,144 @@
 package main
 
 import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 type stream struct {
 	fname string
-	fd    *os.File
+	fd    io.ReadCloser
+	r     io.Reader
+}
+
+// isURL returns true when fname is not a local file but a remote source.
+func isURL(fname string) bool {
+	lower := strings.ToLower(fname)
+	return strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "http://") ||
+		strings.HasPrefix(lower, "s3://")
+}
+
+// openURL starts to download the body of the URL.
+// s3://BUCKET/KEY is read through the public endpoint of the bucket,
+// so objects that require credentials can not be opened.
+func openURL(rawURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(u.Scheme, "s3") {
+		u = &url.URL{
+			Scheme: "https",
+			Host:   u.Host + ".s3.amazonaws.com",
+			Path:   u.Path,
+		}
+	}
+	resp, err := http.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", rawURL, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// openSource opens a local file or a URL.
+func openSource(fname string) (io.ReadCloser, error) {
+	if isURL(fname) {
+		return openURL(fname)
+	}
+	return os.Open(fname)
+}
+
+// decompress wraps r with the decompressor for the suffix of fname.
+func decompress(fname string, r io.Reader) (io.Reader, error) {
+	if isURL(fname) {
+		if u, err := url.Parse(fname); err == nil {
+			fname = u.Path
+		}
+	}
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".gz":
+		return gzip.NewReader(r)
+	case ".bz2":
+		return bzip2.NewReader(r), nil
+	}
+	return r, nil
+}
+
+// trimCompressSuffix removes the suffix of the compression from fname
+// so that the separator can be judged by the suffix remaining.
+func trimCompressSuffix(fname string) string {
+	switch ext := filepath.Ext(fname); strings.ToLower(ext) {
+	case ".gz", ".bz2":
+		return fname[:len(fname)-len(ext)]
+	}
+	return fname
 }
 
 func (s *stream) Read(r []byte) (int, error) {
 	if s.fd == nil {
 		var err error
-		s.fd, err = os.Open(s.fname)
+		s.fd, err = openSource(s.fname)
 		if err != nil {
 			return 0, err
 		}
+		s.r, err = decompress(s.fname, s.fd)
+		if err != nil {
+			s.fd.Close()
+			return 0, err
+		}
 	}
-	n, err := s.fd.Read(r)
+	n, err := s.r.Read(r)
 	if err != nil {
 		s.fd.Close()
 	}
 	return n, err
 }
 
+// isNewFile returns true when the only file given does not exist yet.
+func isNewFile(filenames []string) bool {
+	if len(filenames) != 1 || isURL(filenames[0]) {
+		return false
+	}
+	_, err := os.Stat(filenames[0])
+	return os.IsNotExist(err)
+}
+
+// editFilename returns the name of the file saved by `W`, `ZZ` and `:x`.
+// The files joined by multiFileReader have no name, so that they are not
+// saved over the first one.
+func editFilename(filenames []string) string {
+	if len(filenames) != 1 {
+		return ""
+	}
+	return filenames[0]
+}
+
+// residentFile opens the only file given to read its rows again after
+// they are evicted. It returns nil for the files which can not be read
+// at any offset: URLs, compressed files and the files joined.
+func residentFile(filenames []string) *os.File {
+	if len(filenames) != 1 || isURL(filenames[0]) || trimCompressSuffix(filenames[0]) != filenames[0] {
+		return nil
+	}
+	fd, err := os.Open(filenames[0])
+	if err != nil {
+		return nil
+	}
+	return fd
+}
+
 func multiFileReader(filenames ...string) io.Reader {
 	if len(filenames) <= 0 {
 		return os.Stdin
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestEditFilename(t *testing.T) {
+	for _, c := range []struct {
+		args   []string
+		expect string
+	}{
+		{nil, ""},
+		{[]string{"a.csv"}, "a.csv"},
+		{[]string{"a.csv", "b.csv"}, ""},
+	} {
+		if result := editFilename(c.args); result != c.expect {
+			t.Fatalf("%v: expect %q but %q", c.args, c.expect, result)
+		}
+	}
+}
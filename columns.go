@@ -0,0 +1,125 @@
+package csvi
+
+import (
+	"errors"
+	"strconv"
+)
+
+func init() {
+	exCommands["rename"] = &exCommand{
+		help:  "rename the current column (rename NEWNAME)",
+		batch: true,
+		run:   cmdRename,
+	}
+}
+
+// Columns returns the names of the columns written on the first header line.
+// When Config.HeaderLines is zero, it returns nil.
+// Features that refer columns by name look them up with this every time,
+// so renaming a column takes effect on them immediately.
+func (app *_Application) Columns() []string {
+	if app.HeaderLines <= 0 || app.Len() <= 0 {
+		return nil
+	}
+	header := app.Front()
+	names := make([]string, len(header.Cell))
+	for i, c := range header.Cell {
+		names[i] = c.Text()
+	}
+	return names
+}
+
+// columnName returns the header text of the column col, or "" when
+// there are no header lines.
+func (app *_Application) columnName(col int) string {
+	if names := app.Columns(); col < len(names) {
+		return names[col]
+	}
+	return ""
+}
+
+// cellPrompt returns the prompt to edit a cell of the column col
+// like "replace [price]>".
+func (app *_Application) cellPrompt(verb string, col int) string {
+	if name := app.columnName(col); name != "" {
+		return verb + " [" + app.replaceControls(name) + "]>"
+	}
+	return verb + " cell>"
+}
+
+var errNoSuchColumn = errors.New("no such column")
+
+// remapColumns moves the states of the columns in m to the columns given
+// by remap after the columns are inserted or deleted. The states of the
+// columns for which remap returns -1 are dropped.
+func remapColumns[V any](m map[int]V, remap func(col int) int) map[int]V {
+	if len(m) <= 0 {
+		return m
+	}
+	result := make(map[int]V, len(m))
+	for col, v := range m {
+		if c := remap(col); c >= 0 {
+			result[c] = v
+		}
+	}
+	return result
+}
+
+// remapColumnStates moves the declarations of the columns by `:date` and
+// `:convert` as the columns are inserted or deleted
+func (app *_Application) remapColumnStates(remap func(col int) int) {
+	app.dateLayouts = remapColumns(app.dateLayouts, remap)
+	app.columnTypes = remapColumns(app.columnTypes, remap)
+}
+
+// columnIndex returns the index of the column specified by the header name
+// or the 1-based column number.
+func (app *_Application) columnIndex(name string) (int, error) {
+	for i, c := range app.Columns() {
+		if c == name {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 {
+		return n - 1, nil
+	}
+	return -1, errNoSuchColumn
+}
+
+func cmdRename(e *exCommandArgs) (string, error) {
+	if e.HeaderLines <= 0 {
+		return "rename: no header lines", nil
+	}
+	header := e.Front()
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	if e.ProtectHeader {
+		return msgProtectHeader, nil
+	}
+	col := e.CursorCol
+	if col >= len(header.Cell) {
+		return "rename: the header has no cell for the current column", nil
+	}
+	name := e.Args
+	if name == "" {
+		var err error
+		name, err = e.readlineAndValidate("rename column>", header.Cell[col].Text(), header, col)
+		if err != nil {
+			return "", nil
+		}
+	} else {
+		var err error
+		name, err = e.validate(header, col, name)
+		if err != nil {
+			return err.Error(), nil
+		}
+	}
+	q := header.Cell[col].IsQuoted()
+	header.Replace(col, name, e.Mode)
+	if q {
+		header.Cell[col] = header.Cell[col].Quote(e.Mode)
+	}
+	e.setDirty()
+	return "renamed the column to " + name, nil
+}
@@ -0,0 +1,68 @@
+package csvi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// exCommandArgs is given to the commands typed after `:`.
+// A command may move the cursor by updating CursorRow and CursorCol.
+type exCommandArgs struct {
+	*KeyEventArgs
+	Args         string
+	view         *_View
+	fetchAll     func() error
+	loading      func() bool
+	lfCount      int
+	screenWidth  int
+	screenHeight int
+	// quit is set by commands to end the editor
+	quit bool
+}
+
+type exCommand struct {
+	help string
+	run  func(*exCommandArgs) (string, error)
+	// batch is true when the command works without the terminal
+	// given its arguments, so it can be used in Config.Batch
+	batch bool
+}
+
+var exCommands = map[string]*exCommand{}
+
+func exCommandNames() []string {
+	names := make([]string, 0, len(exCommands))
+	for name := range exCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cutCommandLine splits line into the command name and the arguments.
+// `!` and `%!` need no spaces before the shell command like vi.
+func cutCommandLine(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"!", "%!"} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			return prefix, rest
+		}
+	}
+	name, args, _ := strings.Cut(line, " ")
+	return name, args
+}
+
+func (e *exCommandArgs) run(line string) (string, error) {
+	name, args := cutCommandLine(line)
+	if name == "" {
+		return "", nil
+	}
+	cmd, ok := exCommands[name]
+	if !ok {
+		return fmt.Sprintf("%s: no such command (available: %s)",
+			name, strings.Join(exCommandNames(), ",")), nil
+	}
+	e.Args = strings.TrimSpace(args)
+	return cmd.run(e)
+}
@@ -0,0 +1,30 @@
+package csvi
+
+// Kinds of the confirmations given to Config.OnConfirm
+const (
+	ConfirmQuit      = "quit"
+	ConfirmOverwrite = "overwrite"
+	ConfirmDeleteRow = "delete-row"
+	// ConfirmCommand is the confirmation of the commands of `:` changing
+	// many cells like `:setcol`
+	ConfirmCommand = "command"
+)
+
+// ConfirmEvent is given to Config.OnConfirm
+type ConfirmEvent struct {
+	// Kind is one of ConfirmQuit, ConfirmOverwrite, ConfirmDeleteRow and
+	// ConfirmCommand
+	Kind string
+	// Message is the question shown with `[y/n]` by default
+	Message string
+	*_Application
+}
+
+// confirm asks the question with Config.OnConfirm, or `[y/n]` on the
+// status line by default.
+func (app *_Application) confirm(kind, message string) bool {
+	if app.OnConfirm != nil {
+		return app.OnConfirm(&ConfirmEvent{Kind: kind, Message: message, _Application: app})
+	}
+	return app.YesNo(message)
+}
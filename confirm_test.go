@@ -0,0 +1,48 @@
+package csvi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestConfirmDeleteRow(t *testing.T) {
+	var kinds []string
+	cfg := &Config{
+		ConfirmDeleteRow: true,
+		OnConfirm: func(e *ConfirmEvent) bool {
+			kinds = append(kinds, e.Kind)
+			// refuse the first deletion only
+			return len(kinds) > 1
+		},
+	}
+	result, _ := runEdit(t, cfg, "j|D|j|D|q", "a\nb\nc\n")
+	expect := []string{ConfirmDeleteRow, ConfirmDeleteRow, ConfirmQuit}
+	if fmt.Sprint(kinds) != fmt.Sprint(expect) {
+		t.Fatalf("expect %v, but %v", expect, kinds)
+	}
+	var texts []string
+	result.Each(func(row *uncsv.Row) bool {
+		texts = append(texts, row.Cell[0].Text())
+		return true
+	})
+	if fmt.Sprint(texts) != "[a b]" {
+		t.Fatalf("expect [a b], but %v", texts)
+	}
+
+	// `.` repeating D asks as well
+	kinds = nil
+	cfg.OnConfirm = func(e *ConfirmEvent) bool {
+		kinds = append(kinds, e.Kind)
+		// refuse the deletion by `.`
+		return len(kinds) != 2
+	}
+	result, _ = runEdit(t, cfg, "D|.|q", "a\nb\nc\n")
+	if fmt.Sprint(kinds) != fmt.Sprint(expect) {
+		t.Fatalf("expect %v, but %v", expect, kinds)
+	}
+	if n := result.Len(); n != 2 {
+		t.Fatalf("expect 2 rows left, but %d", n)
+	}
+}
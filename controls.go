@@ -0,0 +1,54 @@
+package csvi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// controlPicture returns the default text to draw the control character c.
+// C0 controls and DEL are drawn as the Unicode control pictures
+// (See. en.wikipedia.org/wiki/Unicode_control_characters#Control_pictures)
+// and C1 controls, which have no pictures, are drawn in hexadecimal.
+func controlPicture(c rune, hex bool) string {
+	if hex || c >= 0x80 {
+		return fmt.Sprintf("<0x%02X>", c)
+	}
+	if c == 0x7F {
+		return "␡"
+	}
+	return string(rune(0x2400 + c))
+}
+
+func isControl(c rune) bool {
+	return c < 0x20 || (0x7F <= c && c < 0xA0)
+}
+
+func newControlReplacer(hex bool, custom map[rune]string) *strings.Replacer {
+	pairs := make([]string, 0, 2*(0x20+0x21))
+	for c := rune(0); c < 0xA0; c++ {
+		if !isControl(c) {
+			continue
+		}
+		picture, ok := custom[c]
+		if !ok {
+			picture = controlPicture(c, hex)
+		}
+		pairs = append(pairs, string(c), picture)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+var replaceTable = newControlReplacer(false, nil)
+
+// replaceControls replaces control characters in s with the texts to draw
+// according to Config.ControlHex and Config.ControlPictures.
+func (cfg *Config) replaceControls(s string) string {
+	if cfg.controlReplacer == nil {
+		if !cfg.ControlHex && len(cfg.ControlPictures) <= 0 {
+			cfg.controlReplacer = replaceTable
+		} else {
+			cfg.controlReplacer = newControlReplacer(cfg.ControlHex, cfg.ControlPictures)
+		}
+	}
+	return cfg.controlReplacer.Replace(s)
+}
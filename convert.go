@@ -0,0 +1,202 @@
+package csvi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nyaosorg/go-readline-ny/keys"
+)
+
+func init() {
+	exCommands["convert"] = &exCommand{
+		help:  "convert the current column to integers, decimals with N digits or booleans, and report cells not converted (convert int|float N|bool [TRUE FALSE] | convert off | convert -undo)",
+		batch: true,
+		run:   cmdConvert,
+	}
+}
+
+// columnType is the type of a column given by `:convert`
+type columnType struct {
+	name    string
+	convert func(string) (string, bool)
+	valid   func(string) bool
+}
+
+func convertToInt(s string) (string, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+		return s, false
+	}
+	return strconv.FormatInt(int64(f), 10), true
+}
+
+func isInt(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+var (
+	trueWords  = []string{"true", "t", "yes", "y", "1", "on"}
+	falseWords = []string{"false", "f", "no", "n", "0", "off"}
+)
+
+func newBoolType(trueText, falseText string) *columnType {
+	return &columnType{
+		name: "bool",
+		convert: func(s string) (string, bool) {
+			s = strings.ToLower(strings.TrimSpace(s))
+			for _, w := range trueWords {
+				if s == w {
+					return trueText, true
+				}
+			}
+			for _, w := range falseWords {
+				if s == w {
+					return falseText, true
+				}
+			}
+			return s, false
+		},
+		valid: func(s string) bool {
+			return s == trueText || s == falseText
+		},
+	}
+}
+
+func newFloatType(digits int) *columnType {
+	return &columnType{
+		name: fmt.Sprintf("float %d", digits),
+		convert: func(s string) (string, bool) {
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+				return s, false
+			}
+			return strconv.FormatFloat(f, 'f', digits, 64), true
+		},
+		valid: isNumber,
+	}
+}
+
+// isValidCell returns false when the text of the column col does not
+// match the date layout or the type declared for it.
+func (app *_Application) isValidCell(col int, text string) bool {
+	if !app.isValidDate(col, text) {
+		return false
+	}
+	if t, ok := app.columnTypes[col]; ok && text != "" {
+		return t.valid(text)
+	}
+	return true
+}
+
+// invalidRows returns the rows whose cells of col are not valid
+func (app *_Application) invalidRows(col int, valid func(int, string) bool) []*RowPtr {
+	var rows []*RowPtr
+	for p := app.Front(); p != nil; p = p.Next() {
+		if p.Index() >= app.HeaderLines && col < len(p.Cell) && !valid(col, p.Cell[col].Text()) {
+			rows = append(rows, p)
+		}
+	}
+	return rows
+}
+
+// reportInvalidCells lists the cells of col in rows not matching
+// expected and lets the user jump to one of them.
+func (e *exCommandArgs) reportInvalidCells(col int, rows []*RowPtr, expected string) (string, error) {
+	if len(rows) <= 0 {
+		return "all cells match " + expected, nil
+	}
+	if e.Config.Pilot == nil {
+		return fmt.Sprintf("%d cell(s) do not match %s", len(rows), expected), nil
+	}
+	lines := make([]string, len(rows))
+	for i, p := range rows {
+		lines[i] = fmt.Sprintf("%d: %s", p.Index()+1, p.Cell[col].Text())
+	}
+	title := fmt.Sprintf("%d cell(s) do not match %s: [Enter]jump [q]close", len(rows), expected)
+	key, index, err := e.listBox(title, lines, 0, e.lfCount, e.screenWidth, e.screenHeight)
+	if err != nil {
+		return "", err
+	}
+	if key == keys.Enter && 0 <= index && index < len(rows) {
+		e.CursorRow = rows[index]
+	}
+	return fmt.Sprintf("%d cell(s) drawn in red do not match %s", len(rows), expected), nil
+}
+
+func cmdConvert(e *exCommandArgs) (string, error) {
+	const usage = "usage: convert int|float N|bool [TRUE FALSE] | convert off | convert -undo"
+	if e.Args == "-undo" {
+		return e.undoColumnEdit()
+	}
+	col := e.CursorCol
+	e.view.clearCache()
+	var t *columnType
+	switch args := strings.Fields(e.Args); {
+	case len(args) == 0:
+		if t, ok := e.columnTypes[col]; ok {
+			return "column type: " + t.name, nil
+		}
+		return usage, nil
+	case len(args) == 1 && args[0] == "off":
+		delete(e.columnTypes, col)
+		return "the column type is removed", nil
+	case len(args) == 1 && args[0] == "int":
+		t = &columnType{name: "int", convert: convertToInt, valid: isInt}
+	case len(args) == 2 && args[0] == "float":
+		digits, err := strconv.Atoi(args[1])
+		if err != nil || digits < 0 {
+			return usage, nil
+		}
+		t = newFloatType(digits)
+	case len(args) == 1 && args[0] == "bool":
+		t = newBoolType("true", "false")
+	case len(args) == 3 && args[0] == "bool":
+		t = newBoolType(args[1], args[2])
+	default:
+		return usage, nil
+	}
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	var rows []*RowPtr
+	var texts []string
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() < e.HeaderLines || col >= len(p.Cell) || p.Cell[col].Text() == "" {
+			continue
+		}
+		old := p.Cell[col].Text()
+		text, ok := t.convert(old)
+		if !ok || text == old {
+			// the cells not converted are left as they are to be reported
+			continue
+		}
+		text, err := e.validate(p, col, text)
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", p.Index()+1, err)
+		}
+		rows = append(rows, p)
+		texts = append(texts, text)
+	}
+	if len(rows) > 0 {
+		e.setColumn(col, rows, texts)
+	}
+	if e.columnTypes == nil {
+		e.columnTypes = map[int]*columnType{}
+	}
+	e.columnTypes[col] = t
+	failed := e.invalidRows(col, e.isValidCell)
+	if len(failed) <= 0 {
+		return fmt.Sprintf("converted %d cell(s) to %s (`:convert -undo` restores them)", len(rows), t.name), nil
+	}
+	message, err := e.reportInvalidCells(col, failed, t.name)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("converted %d cell(s), %s", len(rows), message), nil
+}
@@ -0,0 +1,324 @@
+// Package csvitest interprets the text which csvi writes to the terminal
+// into a matrix of cells, so that tests can assert what is shown at a
+// position and in which style instead of comparing escape sequences.
+//
+//	screen := csvitest.NewScreen(80, 25)
+//	cfg := csvi.Config{Pilot: csvi.NewAutoPilot("j|l")}
+//	cfg.Edit(reader, screen)
+//	if screen.Text(1, 14, 3) != "abc" || !screen.Cell(1, 14).Style.Reverse { ... }
+package csvitest
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// DefaultColor is the value of Style.Foreground and Style.Background
+// when no colors are set.
+const DefaultColor = -1
+
+// Style is the attributes of a cell set by SGR sequences
+type Style struct {
+	// Foreground and Background are the indexes of the 256 colors
+	// (0-7 for `ESC[30m`-`ESC[37m`, 8-15 for the bright ones)
+	// or DefaultColor
+	Foreground int
+	Background int
+	Bold       bool
+	Underline  bool
+	Reverse    bool
+	// Link is the URI of the hyperlink set by `ESC]8;;URI ST`
+	Link string
+}
+
+var defaultStyle = Style{Foreground: DefaultColor, Background: DefaultColor}
+
+// Cell is a cell of the screen. The right half of a wide character is
+// the cell whose Text is empty.
+type Cell struct {
+	Text  string
+	Style Style
+}
+
+// Screen is the virtual terminal. It implements io.Writer.
+type Screen struct {
+	Width, Height int
+	// Row and Col are the position of the cursor (0-based)
+	Row, Col int
+	// CursorVisible is false while the cursor is hidden by `ESC[?25l`
+	CursorVisible bool
+	// Title is the last title set by `ESC]0;...BEL`
+	Title string
+
+	cells   [][]Cell
+	style   Style
+	pending []byte
+}
+
+// NewScreen returns the blank screen of width x height
+func NewScreen(width, height int) *Screen {
+	s := &Screen{Width: width, Height: height, CursorVisible: true, style: defaultStyle}
+	s.cells = make([][]Cell, height)
+	for i := range s.cells {
+		s.cells[i] = s.blankLine()
+	}
+	return s
+}
+
+func (s *Screen) blankLine() []Cell {
+	line := make([]Cell, s.Width)
+	for i := range line {
+		line[i] = Cell{Text: " ", Style: defaultStyle}
+	}
+	return line
+}
+
+// Cell returns the cell at (row,col), both 0-based
+func (s *Screen) Cell(row, col int) Cell {
+	if row < 0 || row >= s.Height || col < 0 || col >= s.Width {
+		return Cell{}
+	}
+	return s.cells[row][col]
+}
+
+// Text returns the text of n columns from (row,col)
+func (s *Screen) Text(row, col, n int) string {
+	var buffer strings.Builder
+	for i := col; i < col+n && i < s.Width; i++ {
+		buffer.WriteString(s.Cell(row, i).Text)
+	}
+	return buffer.String()
+}
+
+// Line returns the text of the line row without the trailing spaces
+func (s *Screen) Line(row int) string {
+	return strings.TrimRight(s.Text(row, 0, s.Width), " ")
+}
+
+// String returns all lines without the trailing spaces and empty lines
+func (s *Screen) String() string {
+	lines := make([]string, s.Height)
+	for i := range lines {
+		lines[i] = s.Line(i)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func (s *Screen) lineFeed() {
+	if s.Row < s.Height-1 {
+		s.Row++
+		return
+	}
+	copy(s.cells, s.cells[1:])
+	s.cells[s.Height-1] = s.blankLine()
+}
+
+func (s *Screen) erase(row, from, to int) {
+	for i := max(from, 0); i < to && i < s.Width; i++ {
+		s.cells[row][i] = Cell{Text: " ", Style: s.style}
+	}
+}
+
+func (s *Screen) put(text string) {
+	w := runewidth.StringWidth(text)
+	if w <= 0 {
+		// combining characters join the previous cell
+		if s.Col > 0 {
+			s.cells[s.Row][s.Col-1].Text += text
+		}
+		return
+	}
+	if s.Col+w > s.Width {
+		s.Col = 0
+		s.lineFeed()
+	}
+	s.cells[s.Row][s.Col] = Cell{Text: text, Style: s.style}
+	for i := 1; i < w; i++ {
+		s.cells[s.Row][s.Col+i] = Cell{Style: s.style}
+	}
+	s.Col += w
+}
+
+// Write interprets p. Sequences split between calls are joined.
+func (s *Screen) Write(p []byte) (int, error) {
+	n := len(p)
+	data := append(s.pending, p...)
+	s.pending = nil
+	for len(data) > 0 {
+		switch data[0] {
+		case '\x1B':
+			size := s.escape(data)
+			if size <= 0 {
+				s.pending = append([]byte{}, data...)
+				return n, nil
+			}
+			data = data[size:]
+			continue
+		case '\r':
+			s.Col = 0
+		case '\n':
+			s.lineFeed()
+		case '\b':
+			s.Col = max(s.Col-1, 0)
+		case '\t':
+			s.Col = min((s.Col/8+1)*8, s.Width-1)
+		case '\a':
+		default:
+			if !utf8.FullRune(data) {
+				s.pending = append([]byte{}, data...)
+				return n, nil
+			}
+			r, size := utf8.DecodeRune(data)
+			s.put(string(r))
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return n, nil
+}
+
+// escape interprets the sequence at the top of data and returns its
+// length, or 0 when it is not complete.
+func (s *Screen) escape(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+	switch data[1] {
+	case '[':
+		for i := 2; i < len(data); i++ {
+			if 0x40 <= data[i] && data[i] <= 0x7E {
+				s.csi(string(data[2:i]), data[i])
+				return i + 1
+			}
+		}
+		return 0
+	case ']':
+		for i := 2; i < len(data); i++ {
+			if data[i] == '\a' {
+				s.osc(string(data[2:i]))
+				return i + 1
+			}
+			if data[i] == '\x1B' && i+1 < len(data) && data[i+1] == '\\' {
+				s.osc(string(data[2:i]))
+				return i + 2
+			}
+		}
+		return 0
+	}
+	return 2
+}
+
+func (s *Screen) osc(param string) {
+	if title, ok := strings.CutPrefix(param, "0;"); ok {
+		s.Title = title
+	} else if title, ok := strings.CutPrefix(param, "2;"); ok {
+		s.Title = title
+	} else if link, ok := strings.CutPrefix(param, "8;"); ok {
+		_, s.style.Link, _ = strings.Cut(link, ";")
+	}
+}
+
+func (s *Screen) csi(param string, final byte) {
+	if private, ok := strings.CutPrefix(param, "?"); ok {
+		if private == "25" {
+			s.CursorVisible = final == 'h'
+		}
+		return
+	}
+	var args []int
+	for _, p := range strings.Split(param, ";") {
+		n, _ := strconv.Atoi(p)
+		args = append(args, n)
+	}
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+	switch final {
+	case 'A':
+		s.Row = max(s.Row-arg(0, 1), 0)
+	case 'B':
+		s.Row = min(s.Row+arg(0, 1), s.Height-1)
+	case 'C':
+		s.Col = min(s.Col+arg(0, 1), s.Width-1)
+	case 'D':
+		s.Col = max(s.Col-arg(0, 1), 0)
+	case 'G':
+		s.Col = min(arg(0, 1)-1, s.Width-1)
+	case 'H', 'f':
+		s.Row = min(arg(0, 1)-1, s.Height-1)
+		s.Col = min(arg(1, 1)-1, s.Width-1)
+	case 'K':
+		switch args[0] {
+		case 0:
+			s.erase(s.Row, s.Col, s.Width)
+		case 1:
+			s.erase(s.Row, 0, s.Col+1)
+		case 2:
+			s.erase(s.Row, 0, s.Width)
+		}
+	case 'J':
+		switch args[0] {
+		case 0:
+			s.erase(s.Row, s.Col, s.Width)
+			for i := s.Row + 1; i < s.Height; i++ {
+				s.erase(i, 0, s.Width)
+			}
+		case 2:
+			for i := 0; i < s.Height; i++ {
+				s.erase(i, 0, s.Width)
+			}
+		}
+	case 'm':
+		s.sgr(args)
+	}
+}
+
+func (s *Screen) sgr(args []int) {
+	for i := 0; i < len(args); i++ {
+		switch n := args[i]; {
+		case n == 0:
+			// SGR does not end the hyperlink
+			link := s.style.Link
+			s.style = defaultStyle
+			s.style.Link = link
+		case n == 1:
+			s.style.Bold = true
+		case n == 22:
+			s.style.Bold = false
+		case n == 4:
+			s.style.Underline = true
+		case n == 24:
+			s.style.Underline = false
+		case n == 7:
+			s.style.Reverse = true
+		case n == 27:
+			s.style.Reverse = false
+		case 30 <= n && n <= 37:
+			s.style.Foreground = n - 30
+		case n == 39:
+			s.style.Foreground = DefaultColor
+		case 40 <= n && n <= 47:
+			s.style.Background = n - 40
+		case n == 49:
+			s.style.Background = DefaultColor
+		case 90 <= n && n <= 97:
+			s.style.Foreground = n - 90 + 8
+		case 100 <= n && n <= 107:
+			s.style.Background = n - 100 + 8
+		case (n == 38 || n == 48) && i+2 < len(args) && args[i+1] == 5:
+			if n == 38 {
+				s.style.Foreground = args[i+2]
+			} else {
+				s.style.Background = args[i+2]
+			}
+			i += 2
+		}
+	}
+}
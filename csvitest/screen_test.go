@@ -0,0 +1,96 @@
+package csvitest
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi"
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestScreen(t *testing.T) {
+	s := NewScreen(10, 3)
+	io.WriteString(s, "abc\x1B[4mde\x1B[24m\r\n\x1B[31m漢\x1B[0mx\x1B[5GY\x1B[?25l\x1B]0;title\a")
+	io.WriteString(s, "\x1B[1")
+	io.WriteString(s, "A\rZ\x1B[K")
+	if s.String() != "Z\n漢x Y" {
+		t.Fatalf("%q", s.String())
+	}
+	if c := s.Cell(0, 3); c.Text != " " || c.Style.Underline {
+		t.Fatalf("the line is not erased: %#v", c)
+	}
+	if c := s.Cell(1, 0); c.Style.Foreground != 1 || c.Text != "漢" || s.Cell(1, 1).Text != "" {
+		t.Fatalf("the wide character: %#v", c)
+	}
+	if s.CursorVisible || s.Title != "title" || s.Row != 0 || s.Col != 1 {
+		t.Fatalf("the cursor or the title: %#v", s)
+	}
+	io.WriteString(s, "\r\n\n\nbottom")
+	if s.Line(2) != "bottom" || s.Line(0) != "漢x Y" {
+		t.Fatalf("not scrolled: %q", s.String())
+	}
+}
+
+func TestEdit(t *testing.T) {
+	screen := NewScreen(80, 25)
+	cfg := csvi.Config{
+		Mode:        &uncsv.Mode{Comma: ','},
+		CellWidth:   10,
+		HeaderLines: 1,
+		Pilot:       csvi.NewAutoPilot("j|l|r|xyz"),
+		ReadOnly:    false,
+	}
+	cfg.Edit(strings.NewReader("name,value\nfoo,abc\nbar,def\n"), screen)
+	if text := screen.Text(1, 10, 3); text != "xyz" {
+		t.Fatalf("expect xyz at (1,10) but %q\n%s", text, screen.String())
+	}
+	cell := screen.Cell(1, 10)
+	if !cell.Style.Underline {
+		t.Fatalf("the cell modified is not underlined: %#v", cell)
+	}
+	if screen.Line(0) != "name      value" {
+		t.Fatalf("header: %q", screen.Line(0))
+	}
+}
+
+func TestLink(t *testing.T) {
+	screen := NewScreen(80, 25)
+	cfg := csvi.Config{
+		Mode:      &uncsv.Mode{Comma: ','},
+		CellWidth: 20,
+		Pilot:     csvi.NewAutoPilot("j|r|see https://example.com"),
+	}
+	cfg.Edit(strings.NewReader("https://example.com/a,b\nc,d\n"), screen)
+	cell := screen.Cell(0, 0)
+	if cell.Style.Link != "https://example.com/a" || cell.Style.Underline {
+		t.Fatalf("the URL is not a link without underline: %#v", cell)
+	}
+	if cell := screen.Cell(0, 20); cell.Style.Link != "" {
+		t.Fatalf("the link continues to the next cell: %#v", cell)
+	}
+	cell = screen.Cell(1, 4)
+	if cell.Style.Link != "https://example.com" || !cell.Style.Underline {
+		t.Fatalf("the modified URL is not a link with underline: %#v", cell)
+	}
+}
+
+func TestStatusFilter(t *testing.T) {
+	screen := NewScreen(80, 25)
+	cfg := csvi.Config{
+		Mode:         &uncsv.Mode{Comma: ','},
+		StatusFormat: "{filter}{modified}:",
+		Pilot:        csvi.NewAutoPilot("/|def|u|q|y"),
+	}
+	var raw strings.Builder
+	result, err := cfg.Edit(strings.NewReader("abc\ndef\n"), io.MultiWriter(screen, &raw))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(raw.String(), "[/def]:") {
+		t.Fatalf("no [/def] on the status line: %q", raw.String())
+	}
+	if result.Modified() {
+		t.Fatal("u on the cell not modified marks the data modified")
+	}
+}
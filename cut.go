@@ -0,0 +1,86 @@
+package csvi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["cut"] = &exCommand{
+		help:  "write the columns listed to FILE (cut COL,COL,N-M FILE. COL is a name or a number)",
+		batch: true,
+		run:   cmdCut,
+	}
+}
+
+// parseColumnList returns the indexes of the columns listed like
+// "name,3,5-7" in the order of the list.
+func (app *_Application) parseColumnList(list string) ([]int, error) {
+	var cols []int
+	for _, field := range strings.Split(list, ",") {
+		if from, to, ok := strings.Cut(field, "-"); ok {
+			f, err1 := strconv.Atoi(from)
+			t, err2 := strconv.Atoi(to)
+			if err1 == nil && err2 == nil && 1 <= f && f <= t {
+				for i := f; i <= t; i++ {
+					cols = append(cols, i-1)
+				}
+				continue
+			}
+		}
+		col, err := app.columnIndex(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+func cmdCut(e *exCommandArgs) (string, error) {
+	list, fname, _ := strings.Cut(e.Args, " ")
+	fname = strings.TrimSpace(fname)
+	if list == "" || fname == "" {
+		return "usage: cut COL,COL,N-M FILE", nil
+	}
+	cols, err := e.parseColumnList(list)
+	if err != nil {
+		return "", err
+	}
+	fname, _ = expandHome(fname)
+	if err := checkWritableSuffix(fname); err != nil {
+		return "", err
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	fd, err := os.Create(fname)
+	if err != nil {
+		return "", err
+	}
+	cursor := e.Front()
+	err = dumpAndClose(fname, fd, func(w io.Writer) {
+		e.Mode.DumpBy(func() *uncsv.Row {
+			if cursor == nil {
+				return nil
+			}
+			row := &uncsv.Row{Cell: make([]uncsv.Cell, len(cols)), Term: cursor.Term}
+			for i, col := range cols {
+				if col < len(cursor.Cell) {
+					row.Cell[i] = cursor.Cell[col]
+				}
+			}
+			cursor = cursor.Next()
+			return row
+		}, w)
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d columns written to %s", len(cols), fname), nil
+}
@@ -0,0 +1,122 @@
+package csvi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func init() {
+	exCommands["date"] = &exCommand{
+		help:  "declare the date layout of the current column and check it, or reformat it to TARGET (date LAYOUT [TARGET] | date off | date -undo)",
+		batch: true,
+		run:   cmdDate,
+	}
+}
+
+// datePresets are the names usable instead of the layouts of Go
+var datePresets = map[string]string{
+	"iso":      "2006-01-02",
+	"us":       "01/02/2006",
+	"eu":       "02/01/2006",
+	"ymd":      "2006/01/02",
+	"compact":  "20060102",
+	"datetime": "2006-01-02 15:04:05",
+	"rfc3339":  time.RFC3339,
+}
+
+func dateLayout(s string) string {
+	if layout, ok := datePresets[strings.ToLower(s)]; ok {
+		return layout
+	}
+	return s
+}
+
+// isValidDate returns false when col has the date layout declared by
+// `:date` and text is not empty and does not match it.
+func (app *_Application) isValidDate(col int, text string) bool {
+	layout, ok := app.dateLayouts[col]
+	if !ok || text == "" {
+		return true
+	}
+	_, err := time.Parse(layout, text)
+	return err == nil
+}
+
+// invalidDates returns the rows whose cells of col do not match the layout
+func (app *_Application) invalidDates(col int) []*RowPtr {
+	return app.invalidRows(col, app.isValidDate)
+}
+
+func cmdDate(e *exCommandArgs) (string, error) {
+	const usage = "usage: date LAYOUT [TARGET] | date off | date -undo (LAYOUT is of Go or iso,us,eu,ymd,compact,datetime,rfc3339)"
+	if e.Args == "-undo" {
+		return e.undoColumnEdit()
+	}
+	col := e.CursorCol
+	e.view.clearCache()
+	args := e.Args
+	if args == "" {
+		if layout, ok := e.dateLayouts[col]; ok {
+			return "date layout: " + layout, nil
+		}
+		return usage, nil
+	}
+	if args == "off" {
+		delete(e.dateLayouts, col)
+		return "the date layout is removed", nil
+	}
+	source, rest, err := cutBatchArg(args)
+	if err != nil {
+		return "", err
+	}
+	target, err := unquoteBatchText(rest)
+	if err != nil {
+		return "", err
+	}
+	source, target = dateLayout(source), dateLayout(target)
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	if e.dateLayouts == nil {
+		e.dateLayouts = map[int]string{}
+	}
+	e.dateLayouts[col] = source
+	if target == "" {
+		return e.reportInvalidCells(col, e.invalidDates(col), e.dateLayouts[col])
+	}
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	var rows []*RowPtr
+	var texts []string
+	failed := 0
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() < e.HeaderLines || col >= len(p.Cell) || p.Cell[col].Text() == "" {
+			continue
+		}
+		old := p.Cell[col].Text()
+		t, err := time.Parse(source, old)
+		if err != nil {
+			failed++
+			continue
+		}
+		text := t.Format(target)
+		if text == old {
+			continue
+		}
+		text, err = e.validate(p, col, text)
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", p.Index()+1, err)
+		}
+		rows = append(rows, p)
+		texts = append(texts, text)
+	}
+	if failed > 0 {
+		// keep the source layout to highlight the cells not converted
+		return fmt.Sprintf("%d cell(s) do not match %s. Nothing is reformatted", failed, source), nil
+	}
+	e.setColumn(col, rows, texts)
+	e.dateLayouts[col] = target
+	return fmt.Sprintf("reformatted %d cell(s) to %s (`:date -undo` restores them)", len(rows), target), nil
+}
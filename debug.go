@@ -0,0 +1,92 @@
+package csvi
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// memoryLogInterval is the interval to write the memory statistics to
+// Config.DebugLog, which stops the world to read them.
+const memoryLogInterval = time.Second
+
+// fetchLogInterval is the interval to write the number of rows loaded
+const fetchLogInterval = time.Second / 4
+
+// debugLog writes the events of the event loop to Config.DebugLog and
+// keeps the numbers shown on the overlay. Without Config.DebugLog, it
+// only keeps the numbers.
+type debugLog struct {
+	logger    *slog.Logger
+	frameTime time.Duration
+	nextMem   time.Time
+}
+
+func newDebugLog(w io.Writer) *debugLog {
+	d := &debugLog{}
+	if w != nil {
+		d.logger = slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return d
+}
+
+func (d *debugLog) key(key string) {
+	if d.logger != nil && key != "" {
+		d.logger.Debug("key", "key", key)
+	}
+}
+
+// draw records the time to draw the frame and the memory statistics at
+// most once in memoryLogInterval.
+func (d *debugLog) draw(elapsed time.Duration, rows int) {
+	d.frameTime = elapsed
+	if d.logger == nil {
+		return
+	}
+	d.logger.Debug("draw", "elapsed", elapsed, "rows", rows)
+	if now := time.Now(); now.After(d.nextMem) {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		d.logger.Debug("memory",
+			"heap", m.HeapAlloc, "sys", m.Sys, "gc", m.NumGC, "goroutines", runtime.NumGoroutine())
+		d.nextMem = now.Add(memoryLogInterval)
+	}
+}
+
+// watchFetch returns fetch writing the number of rows loaded at most
+// once in fetchLogInterval and at the end of the data.
+func (d *debugLog) watchFetch(fetch func() (*uncsv.Row, error), rows func() int) func() (*uncsv.Row, error) {
+	if d.logger == nil {
+		return fetch
+	}
+	next := time.Now().Add(fetchLogInterval)
+	return func() (*uncsv.Row, error) {
+		row, err := fetch()
+		// the row is not pushed yet
+		n := rows()
+		if row != nil && !isEmptyRow(row) {
+			n++
+		}
+		if err != nil {
+			d.logger.Debug("fetch", "rows", n, "end", true, "err", err)
+		} else if now := time.Now(); now.After(next) {
+			d.logger.Debug("fetch", "rows", n)
+			next = now.Add(fetchLogInterval)
+		}
+		return row, err
+	}
+}
+
+// overlay returns the text shown at the right end of the status line
+func (d *debugLog) overlay(rows int, loading bool) string {
+	state := ""
+	if loading {
+		state = "+"
+	}
+	return fmt.Sprintf(" frame %.1fms rows %d%s ",
+		float64(d.frameTime.Microseconds())/1000, rows, state)
+}
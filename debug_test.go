@@ -0,0 +1,19 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDebugLog(t *testing.T) {
+	var log strings.Builder
+	_, out := runEdit(t, &Config{DebugLog: &log}, "\x1B[24~|q|y", "a,b\nc,d\n")
+	for _, msg := range []string{`"msg":"key","key":"q"`, `"msg":"draw"`, `"msg":"memory"`} {
+		if !strings.Contains(log.String(), msg) {
+			t.Fatalf("%s is not logged: %s", msg, log.String())
+		}
+	}
+	if !strings.Contains(out, "ms rows 2 ") {
+		t.Fatalf("the overlay is not drawn: %q", out)
+	}
+}
@@ -0,0 +1,68 @@
+package csvi
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/nyaosorg/go-readline-ny"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+var encodingCandidates = Candidate{
+	"UTF-8", "ISO-8859-1", "windows-1252", "EUC-JP", "Shift_JIS",
+}
+
+// guessEncoding guesses the encoding from the data already buffered
+// without waiting more input. UTF-16 is applied at once because it is
+// hardly mistaken. Other guesses are confirmed by the user in edit.
+func (cfg *Config) guessEncoding(reader *bufio.Reader) {
+	mode := cfg.Mode
+	if mode == nil || mode.NonUTF8 || mode.EncodingName() != "" ||
+		mode.IsUTF16LE() || mode.IsUTF16BE() {
+		return
+	}
+	if _, err := reader.Peek(1); err != nil {
+		return
+	}
+	data, _ := reader.Peek(reader.Buffered())
+	switch guess := uncsv.GuessEncoding(data); guess {
+	case "":
+	case "UTF-16LE":
+		mode.SetUTF16LE()
+	case "UTF-16BE":
+		mode.SetUTF16BE()
+	default:
+		cfg.encodingGuess = guess
+	}
+}
+
+// confirmEncoding asks the user which encoding to use with the guess as
+// the default and returns a message to show when it fails.
+func (app *_Application) confirmEncoding(guess string) string {
+	name, err := app.Pilot.ReadLine(app.out, "not UTF-8. encoding>", guess, encodingCandidates)
+	io.WriteString(app.out, "\r"+_ANSI_ERASE_LINE)
+	if err != nil {
+		if err == readline.CtrlC {
+			return ""
+		}
+		return err.Error()
+	}
+	mode := app.Mode
+	switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "") {
+	case "", "utf8":
+		return ""
+	case "utf16le":
+		mode.SetUTF16LE()
+		return ""
+	case "utf16be":
+		mode.SetUTF16BE()
+		return ""
+	}
+	if err := mode.SetEncoding(name); err != nil {
+		return name + ": " + err.Error()
+	}
+	mode.NonUTF8 = true
+	return ""
+}
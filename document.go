@@ -0,0 +1,266 @@
+package csvi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// Document is the rows being edited and whether they are modified.
+// It works without the terminal, so programs can edit rows before and
+// after Edit, or in Config.OnIdle and Config.KeyMap during it.
+// Config.EditDocument attaches the view and the key bindings to it.
+// Document is not safe for concurrent use: use Session to append rows
+// from other goroutines while Edit runs.
+type Document struct {
+	csvLines    *rowList
+	removedRows []*uncsv.Row
+	dirty       bool
+	editCount   int
+	mode        *uncsv.Mode
+}
+
+// NewDocument returns the empty document whose cells are made in mode
+func NewDocument(mode *uncsv.Mode) *Document {
+	if mode == nil {
+		mode = &uncsv.Mode{}
+	}
+	return &Document{csvLines: newRowList(), mode: mode}
+}
+
+// ReadDocument reads all rows of r in mode
+func ReadDocument(r io.Reader, mode *uncsv.Mode) (*Document, error) {
+	doc := NewDocument(mode)
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+	for {
+		row, err := uncsv.ReadLine(reader, doc.mode)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err == io.EOF {
+			if !isEmptyRow(row) {
+				doc.Push(row)
+			}
+			return doc, nil
+		}
+		doc.Push(row)
+	}
+}
+
+// setDirty marks the data modified. editCount tells caches of the data
+// like searchIndex that they are obsolete.
+func (doc *Document) setDirty() {
+	doc.dirty = true
+	doc.editCount++
+}
+
+// Modified returns true when the rows are changed after read
+func (doc *Document) Modified() bool {
+	return doc.dirty
+}
+
+func (doc *Document) Front() *RowPtr {
+	if doc.Len() <= 0 {
+		return nil
+	}
+	return frontPtr(doc.csvLines)
+}
+
+func (doc *Document) Back() *RowPtr {
+	if doc.Len() <= 0 {
+		return nil
+	}
+	return backPtr(doc.csvLines)
+}
+
+func (doc *Document) Len() int {
+	return doc.csvLines.Len()
+}
+
+func (doc *Document) Push(row *uncsv.Row) {
+	doc.csvLines.PushBack(row)
+}
+
+// setTerm changes the terminator of the row in the document
+func (doc *Document) setTerm(row *uncsv.Row, term string) {
+	terms := doc.csvLines.terms
+	terms[row.Term]--
+	row.Term = term
+	terms[row.Term]++
+}
+
+// removeRow removes the row keeping it for RemovedRows. When it is the
+// last row, its terminator is given to the new last row.
+func (doc *Document) removeRow(p *RowPtr) *uncsv.Row {
+	last := p.Index() == doc.Len()-1
+	removed := p.Remove()
+	doc.removedRows = append(doc.removedRows, removed)
+	if last && doc.Len() > 0 {
+		doc.setTerm(doc.Back().Row, removed.Term)
+	}
+	doc.setDirty()
+	return removed
+}
+
+// sortRows sorts the rows from the 0-based line number from by cmp stably.
+// The terminators stay on their lines, so the last line keeps its own.
+// RowPtr made before keep their line numbers, not their rows.
+func (doc *Document) sortRows(from int, cmp func(a, b *uncsv.Row) int) {
+	start, err := doc.rowAt(from + 1)
+	if err != nil {
+		return
+	}
+	var rows []*uncsv.Row
+	var terms []string
+	for p := start; p != nil; p = p.Next() {
+		rows = append(rows, p.Row)
+		terms = append(terms, p.Term)
+	}
+	slices.SortStableFunc(rows, cmp)
+	L := doc.csvLines
+	L.unspill()
+	i := 0
+	for p := start; p != nil; p = p.Next() {
+		rows[i].Term = terms[i]
+		L.chunks[p.chunk][p.offset] = rows[i]
+		i++
+	}
+	doc.setDirty()
+}
+
+func (doc *Document) Each(callback func(*uncsv.Row) bool) {
+	for p := doc.Front(); p != nil; p = p.Next() {
+		if !callback(p.Row) {
+			break
+		}
+	}
+}
+
+func (doc *Document) RemovedRows(callback func(*uncsv.Row) bool) {
+	for _, p := range doc.removedRows {
+		if !callback(p) {
+			break
+		}
+	}
+}
+
+// rowAt returns the row of the 1-based line number n.
+func (doc *Document) rowAt(n int) (*RowPtr, error) {
+	c, o, ok := doc.csvLines.locate(n - 1)
+	if !ok {
+		return nil, fmt.Errorf("%d: no such row", n)
+	}
+	return doc.csvLines.ptr(c, o, n-1), nil
+}
+
+// Row returns the texts of the cells of the row n (0-based), or nil when
+// there is no such row.
+func (doc *Document) Row(n int) []string {
+	p, err := doc.rowAt(n + 1)
+	if err != nil {
+		return nil
+	}
+	return cellTexts(p.Row)
+}
+
+// newRow makes the row of cells terminated by the default terminator
+func (doc *Document) newRow(cells []string) *uncsv.Row {
+	row := uncsv.NewRow(doc.mode)
+	for i, text := range cells {
+		if i == 0 {
+			row.Replace(0, text, doc.mode)
+		} else {
+			row.Insert(i, text, doc.mode)
+		}
+	}
+	return &row
+}
+
+// appendRow appends the row of cells without marking the data modified
+func (doc *Document) appendRow(cells []string) {
+	if doc.Len() > 0 {
+		if last := doc.Back(); last.Term == "" {
+			doc.setTerm(last.Row, doc.mode.DefaultTerm)
+		}
+	}
+	doc.Push(doc.newRow(cells))
+}
+
+// AppendRow appends the row of cells
+func (doc *Document) AppendRow(cells []string) {
+	doc.appendRow(cells)
+	doc.setDirty()
+}
+
+// InsertRow inserts the row of cells before the row n (0-based).
+// n may be Len() to append it.
+func (doc *Document) InsertRow(n int, cells []string) error {
+	if n == doc.Len() {
+		doc.AppendRow(cells)
+		return nil
+	}
+	p, err := doc.rowAt(n + 1)
+	if err != nil {
+		return err
+	}
+	p.InsertBefore(doc.newRow(cells))
+	doc.setDirty()
+	return nil
+}
+
+// DeleteRow removes the row n (0-based). The row removed is given to
+// the callback of RemovedRows.
+func (doc *Document) DeleteRow(n int) error {
+	p, err := doc.rowAt(n + 1)
+	if err != nil {
+		return err
+	}
+	if doc.Len() == 1 {
+		return fmt.Errorf("%d: the last row can not be removed", n)
+	}
+	doc.removeRow(p)
+	return nil
+}
+
+// SetCell replaces the text of the cell (row,col), both 0-based, adding
+// empty cells when the row is shorter. The double quotations enclosing
+// the cell are kept.
+func (doc *Document) SetCell(row, col int, text string) error {
+	p, err := doc.rowAt(row + 1)
+	if err != nil {
+		return err
+	}
+	if col < 0 {
+		return fmt.Errorf("%d: %w", col, errNoSuchColumn)
+	}
+	for col >= len(p.Cell) {
+		p.Insert(len(p.Cell), "", doc.mode)
+	}
+	q := p.Cell[col].IsQuoted()
+	p.Replace(col, text, doc.mode)
+	if q {
+		p.Cell[col] = p.Cell[col].Quote(doc.mode)
+	}
+	doc.setDirty()
+	return nil
+}
+
+// Dump writes all rows to w
+func (doc *Document) Dump(w io.Writer) {
+	cursor := doc.Front()
+	doc.mode.DumpBy(
+		func() *uncsv.Row {
+			if cursor == nil {
+				return nil
+			}
+			row := cursor.Row
+			cursor = cursor.Next()
+			return row
+		}, w)
+}
@@ -0,0 +1,113 @@
+package csvi
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestDocument(t *testing.T) {
+	doc, err := ReadDocument(strings.NewReader("a,\"b\"\nc,d"), &uncsv.Mode{Comma: ',', DefaultTerm: "\n"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if doc.Modified() {
+		t.Fatal("modified before edits")
+	}
+	if err := doc.SetCell(0, 1, "x"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := doc.SetCell(1, 3, "y"); err != nil {
+		t.Fatal(err.Error())
+	}
+	doc.AppendRow([]string{"e", "f,g"})
+	if err := doc.InsertRow(0, []string{"h"}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := doc.DeleteRow(2); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := doc.SetCell(9, 0, "z"); err == nil {
+		t.Fatal("no error for the row not existing")
+	}
+	if !doc.Modified() {
+		t.Fatal("not modified after edits")
+	}
+	if row := doc.Row(1); strings.Join(row, "|") != "a|x" {
+		t.Fatalf("Row(1): %q", row)
+	}
+	var out strings.Builder
+	doc.Dump(&out)
+	expect := "h\na,\"x\"\ne,\"f,g\"\n"
+	if out.String() != expect {
+		t.Fatalf("expect %q but %q", expect, out.String())
+	}
+	var removed []string
+	doc.RemovedRows(func(row *uncsv.Row) bool {
+		removed = append(removed, strings.Join(cellTexts(row), "|"))
+		return true
+	})
+	if strings.Join(removed, ",") != "c|d||y" {
+		t.Fatalf("removed: %q", removed)
+	}
+}
+
+func TestEditDocument(t *testing.T) {
+	doc, err := ReadDocument(strings.NewReader("a,b\nc,d\n"), &uncsv.Mode{Comma: ','})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cfg := &Config{
+		Pilot: NewAutoPilot("j|l|r|x|q|y"),
+	}
+	result, err := cfg.EditDocument(doc, io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result.Document != doc {
+		t.Fatal("the document edited is not the one given")
+	}
+	if err := doc.SetCell(0, 0, "y"); err != nil {
+		t.Fatal(err.Error())
+	}
+	var out strings.Builder
+	doc.Dump(&out)
+	expect := "y,b\nc,x\n"
+	if out.String() != expect {
+		t.Fatalf("expect %q but %q", expect, out.String())
+	}
+}
+
+func TestDocumentTerms(t *testing.T) {
+	doc, err := ReadDocument(strings.NewReader("a\r\nb\nc"), &uncsv.Mode{Comma: ','})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	app := &_Application{Document: doc, Config: &Config{Mode: doc.mode}}
+	if !app.mixedEOL() {
+		t.Fatal("CRLF and LF are not found")
+	}
+	if err := doc.DeleteRow(1); err != nil {
+		t.Fatal(err.Error())
+	}
+	if app.mixedEOL() {
+		t.Fatal("LF is still counted after it is removed")
+	}
+	if err := doc.DeleteRow(1); err != nil {
+		t.Fatal(err.Error())
+	}
+	var out strings.Builder
+	doc.Dump(&out)
+	if out.String() != "a" {
+		t.Fatalf("the terminator of the last row is not carried: %q", out.String())
+	}
+	doc.AppendRow([]string{"d"})
+	if err := doc.InsertRow(1, []string{"e"}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if terms := doc.csvLines.terms; terms["\r\n"] != 3 || terms[""] != 0 {
+		t.Fatalf("the terminators of the rows added are not counted: %v", terms)
+	}
+}
@@ -0,0 +1,72 @@
+package csvi
+
+import (
+	"fmt"
+
+	"github.com/nyaosorg/go-readline-ny/keys"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["dryrun"] = &exCommand{
+		help: "show the rows as they will be saved (`:dryrun modified` for the modified rows only)",
+		run:  cmdDryRun,
+	}
+}
+
+func isModifiedRow(row *uncsv.Row) bool {
+	for _, c := range row.Cell {
+		if c.Modified() {
+			return true
+		}
+	}
+	return false
+}
+
+func cmdDryRun(e *exCommandArgs) (string, error) {
+	modifiedOnly := false
+	switch e.Args {
+	case "":
+	case "modified":
+		modifiedOnly = true
+	default:
+		return "", fmt.Errorf("%s: usage: dryrun [modified]", e.Args)
+	}
+	var lines []string
+	var rows []*RowPtr
+	add := func(p *RowPtr) {
+		lines = append(lines, fmt.Sprintf("%d: %s", p.Index()+1, e.Mode.Decode(p.Rebuild(e.Mode))))
+		rows = append(rows, p)
+	}
+	if modifiedOnly {
+		for p := e.Front(); p != nil; p = p.Next() {
+			if isModifiedRow(p.Row) {
+				add(p)
+			}
+		}
+		if len(lines) <= 0 {
+			return "no rows are modified", nil
+		}
+	} else {
+		p := e.CursorRow.Clone()
+		for i := 0; p != nil && i < e.screenHeight-2; i++ {
+			add(p)
+			p = p.Next()
+		}
+	}
+	title := "dry run"
+	if e.Mode.HasBom() {
+		title += " (with BOM)"
+	}
+	title += ": [Enter]jump [q]close"
+	defer e.view.clearCache()
+	key, index, err := e.listBox(title, lines, 0, e.lfCount, e.screenWidth, e.screenHeight)
+	if err != nil {
+		return "", err
+	}
+	if key == keys.Enter && 0 <= index && index < len(rows) {
+		e.CursorRow = rows[index]
+	}
+	return "", nil
+}
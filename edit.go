@@ -0,0 +1,163 @@
+package csvi
+
+import (
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// The edits of the keys below are shared by the key handlers of the main
+// loop and `.` repeating them, which only give the texts in other ways.
+
+// editProtected returns the message why the edit of key can not be done
+// at row, or "" when it can.
+func (app *_Application) editProtected(key string, row *RowPtr) string {
+	switch key {
+	case "i", "a", "d":
+		return app.checkWriteProtectAndColumn(row)
+	case "o":
+		// a row can be added under the last line of the header
+		if app.ProtectHeader && row.Index()+1 < app.HeaderLines {
+			return msgProtectHeader
+		}
+		if app.ReadOnly {
+			return msgReadOnly
+		}
+		return ""
+	default:
+		return app.checkWriteProtect(row)
+	}
+}
+
+// replaceCell replaces the text of the cell (row,col) keeping the double
+// quotations enclosing it
+func (app *_Application) replaceCell(row *RowPtr, col int, text string) {
+	mode := app.Mode
+	app.audit("replace", row, col, row.Cell[col].Text(), text)
+	q := row.Cell[col].IsQuoted()
+	row.Replace(col, text, mode)
+	if q {
+		row.Cell[col] = row.Cell[col].Quote(mode)
+	}
+	app.setDirty()
+	app.touch(row, col)
+}
+
+// insertCell inserts the cell of text before the column col, or after it
+// when after is true, and returns the column where the cursor moves: the
+// one which was at col for `i` and the new cell for `a`. When the row has
+// only an empty cell, the cell is replaced instead.
+func (app *_Application) insertCell(row *RowPtr, col int, text string, after bool) int {
+	mode := app.Mode
+	if cells := row.Cell; len(cells) == 1 && cells[0].Text() == "" {
+		row.Replace(col, text, mode)
+		app.audit("insert-cell", row, col, "", text)
+	} else if after {
+		col++
+		row.Insert(col, text, mode)
+		app.audit("insert-cell", row, col, "", text)
+	} else {
+		row.Insert(col, text, mode)
+		app.audit("insert-cell", row, col, "", text)
+		col++
+	}
+	app.setDirty()
+	app.touch(row, -1)
+	return col
+}
+
+// deleteCell removes the cell (row,col). The only cell of a row is
+// emptied instead.
+func (app *_Application) deleteCell(row *RowPtr, col int) {
+	app.audit("delete-cell", row, col, row.Cell[col].Text(), "")
+	if len(row.Cell) <= 1 {
+		row.Replace(0, "", app.Mode)
+	} else {
+		row.Delete(col)
+	}
+	app.setDirty()
+	app.touch(row, -1)
+}
+
+// deleteRow removes the row with the confirmation of
+// Config.ConfirmDeleteRow, and returns the row where the cursor moves and
+// true. It returns row and false when the row is not removed.
+func (app *_Application) deleteRow(row *RowPtr) (*RowPtr, bool) {
+	if app.Len() <= 1 {
+		return row, false
+	}
+	if app.ConfirmDeleteRow && !app.confirm(ConfirmDeleteRow, "Delete the row ? [y/n]") {
+		return row, false
+	}
+	prev := row.Prev()
+	app.auditRow("delete-row", row, row.Row)
+	app.removeRow(row)
+	if prev == nil {
+		return app.Front(), true
+	}
+	if next := prev.Next(); next != nil {
+		return next, true
+	}
+	return prev, true
+}
+
+// newRowFor makes the row inserted by `o` or `O` at row. The cells are
+// texts, or the empty ones with Config.RowTemplate when texts is nil.
+// The columns of the timestamps and the auto-increment one are filled.
+func (app *_Application) newRowFor(row *RowPtr, texts []string, fetchAll func() error) (*uncsv.Row, error) {
+	mode := app.Mode
+	newRow := uncsv.NewRow(mode)
+	if texts == nil {
+		if app.FixColumn {
+			for len(newRow.Cell) < len(row.Cell) {
+				newRow.Insert(0, "", mode)
+			}
+		}
+		app.applyRowTemplate(&newRow)
+	}
+	for i, text := range texts {
+		if i == 0 {
+			newRow.Replace(0, text, mode)
+		} else {
+			newRow.Insert(i, text, mode)
+		}
+	}
+	app.stampNewRow(&newRow)
+	if app.AutoIncrement != "" {
+		if err := fetchAll(); err != nil {
+			return nil, err
+		}
+		app.applyAutoIncrement(&newRow, len(row.Cell))
+	}
+	return &newRow, nil
+}
+
+// insertRow inserts newRow after row, or before it when after is false,
+// and returns the pointer to it
+func (app *_Application) insertRow(row *RowPtr, newRow *uncsv.Row, after bool) *RowPtr {
+	mode := app.Mode
+	if after {
+		newRow.Term = row.Term
+		if row.Term == "" {
+			app.setTerm(row.Row, mode.DefaultTerm)
+		}
+		row = row.InsertAfter(newRow)
+	} else {
+		newRow.Term = mode.DefaultTerm
+		row = row.InsertBefore(newRow)
+	}
+	app.setDirty()
+	return row
+}
+
+// toggleQuote encloses the cell (row,col) with double quotations,
+// or removes them
+func (app *_Application) toggleQuote(row *RowPtr, col int) {
+	mode := app.Mode
+	cell := &row.Cell[col]
+	if cell.IsQuoted() {
+		row.Replace(col, cell.Text(), mode)
+	} else {
+		*cell = cell.Quote(mode)
+	}
+	app.setDirty()
+	app.touch(row, col)
+}
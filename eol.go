@@ -0,0 +1,56 @@
+package csvi
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	exCommands["eol"] = &exCommand{
+		help:  "change the terminators of all rows (eol lf|crlf)",
+		batch: true,
+		run:   cmdEol,
+	}
+}
+
+// mixedEOL returns true when both CRLF and LF are used as terminators.
+func (app *_Application) mixedEOL() bool {
+	terms := app.csvLines.terms
+	return terms["\r\n"] > 0 && terms["\n"] > 0
+}
+
+func parseEOL(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "lf":
+		return "\n", nil
+	case "crlf":
+		return "\r\n", nil
+	}
+	return "", fmt.Errorf("%s: the terminator must be lf or crlf", s)
+}
+
+func cmdEol(e *exCommandArgs) (string, error) {
+	term, err := parseEOL(e.Args)
+	if err != nil {
+		return err.Error(), nil
+	}
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	count := 0
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Term != "" && p.Term != term {
+			e.setTerm(p.Row, term)
+			count++
+		}
+	}
+	e.Mode.DefaultTerm = term
+	if count > 0 {
+		e.setDirty()
+	}
+	e.view.clearCache()
+	return fmt.Sprintf("%d row(s) changed", count), nil
+}
@@ -0,0 +1,78 @@
+package csvi
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/nyaosorg/go-readline-ny/completion"
+)
+
+// recentFiles holds the names written in the session of Edit. They are
+// offered as the history and the candidates of the filename prompt.
+type recentFiles struct {
+	names Candidate
+}
+
+const maxRecentFiles = 20
+
+func (r *recentFiles) add(name string) {
+	r.names = append(slices.DeleteFunc(r.names, func(s string) bool {
+		return s == name
+	}), name)
+	if len(r.names) > maxRecentFiles {
+		r.names = r.names[len(r.names)-maxRecentFiles:]
+	}
+}
+
+// expandHome replaces the leading `~` of name with the home directory
+// and returns the new name and the home directory.
+func expandHome(name string) (string, string) {
+	if name != "~" && !strings.HasPrefix(name, "~/") && !strings.HasPrefix(name, "~"+string(os.PathSeparator)) {
+		return name, ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name, ""
+	}
+	home = strings.TrimRight(home, "/"+string(os.PathSeparator))
+	return home + name[1:], home
+}
+
+// fileCompletion completes filenames with `~` and the recent files
+type fileCompletion struct {
+	completion.File
+	recent *recentFiles
+}
+
+func (f fileCompletion) List(field []string) (fullnames, basenames []string) {
+	if len(field) <= 0 {
+		return
+	}
+	target := field[len(field)-1]
+	if expanded, home := expandHome(target); home != "" {
+		if target == "~" {
+			expanded += string(os.PathSeparator)
+		}
+		field = append(slices.Clone(field[:len(field)-1]), expanded)
+		full, base := f.File.List(field)
+		for i, name := range full {
+			if strings.HasPrefix(name, home) {
+				full[i] = "~" + name[len(home):]
+			}
+		}
+		return full, base
+	}
+	if target != "" {
+		fullnames, basenames = f.File.List(field)
+	}
+	names := f.recent.names
+	for i := len(names) - 1; i >= 0; i-- {
+		name := names[i]
+		if strings.HasPrefix(name, target) && !slices.Contains(fullnames, name) {
+			fullnames = append(fullnames, name)
+			basenames = append(basenames, name)
+		}
+	}
+	return
+}
@@ -0,0 +1,21 @@
+package csvi
+
+import (
+	"testing"
+)
+
+func TestExpandHome(t *testing.T) {
+	// os.UserHomeDir reads USERPROFILE on Windows
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("USERPROFILE", "/home/user")
+	for source, expect := range map[string]string{
+		"~":           "/home/user",
+		"~/a.csv":     "/home/user/a.csv",
+		"~user/a.csv": "~user/a.csv",
+		"a~/b.csv":    "a~/b.csv",
+	} {
+		if result, _ := expandHome(source); result != expect {
+			t.Fatalf("expandHome(%q): expect %q but %q", source, expect, result)
+		}
+	}
+}
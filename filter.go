@@ -0,0 +1,148 @@
+package csvi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	exCommands["!"] = &exCommand{
+		help:  "replace the current cell with the output of COMMAND given it (!COMMAND)",
+		batch: true,
+		run:   cmdFilterCell,
+	}
+	exCommands["%!"] = &exCommand{
+		help:  "replace the current column with the output of COMMAND given it one value per line (%!COMMAND | %! -undo)",
+		batch: true,
+		run:   cmdFilterColumn,
+	}
+}
+
+// shellCommand returns the command to run line with the shell
+func shellCommand(line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd.exe", "/c", line)
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return exec.Command(shell, "-c", line)
+}
+
+// filterLines gives texts to the command line one per line and returns
+// the lines of the output, which must be as many as texts.
+func filterLines(line string, texts []string) ([]string, error) {
+	var stdin bytes.Buffer
+	for _, text := range texts {
+		if strings.ContainsAny(text, "\r\n") {
+			return nil, errors.New("the cells containing newlines can not be filtered")
+		}
+		stdin.WriteString(text)
+		stdin.WriteByte('\n')
+	}
+	cmd := shellCommand(line)
+	cmd.Stdin = &stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(strings.SplitN(msg, "\n", 2)[0])
+		}
+		return nil, err
+	}
+	result := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	for i, s := range result {
+		result[i] = strings.TrimSuffix(s, "\r")
+	}
+	if len(result) != len(texts) {
+		return nil, fmt.Errorf("%s: output %d line(s) for %d line(s)", line, len(result), len(texts))
+	}
+	return result, nil
+}
+
+func cmdFilterCell(e *exCommandArgs) (string, error) {
+	if e.Args == "" {
+		return "usage: !COMMAND", nil
+	}
+	if m := e.checkWriteProtect(e.CursorRow); m != "" {
+		return m, nil
+	}
+	row, col := e.CursorRow, e.CursorCol
+	if col >= len(row.Cell) {
+		return "", nil
+	}
+	result, err := filterLines(e.Args, []string{row.Cell[col].Text()})
+	if err != nil {
+		return "", err
+	}
+	text, err := e.validate(row, col, result[0])
+	if err != nil {
+		return "", err
+	}
+	e.audit("replace", row, col, row.Cell[col].Text(), text)
+	q := row.Cell[col].IsQuoted()
+	row.Replace(col, text, e.Mode)
+	if q {
+		row.Cell[col] = row.Cell[col].Quote(e.Mode)
+	}
+	e.touch(row, col)
+	e.setDirty()
+	return "", nil
+}
+
+func cmdFilterColumn(e *exCommandArgs) (string, error) {
+	if e.Args == "" {
+		return "usage: %!COMMAND | %! -undo", nil
+	}
+	if e.Args == "-undo" {
+		return e.undoColumnEdit()
+	}
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	col := e.CursorCol
+	var rows []*RowPtr
+	var texts []string
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() < e.HeaderLines || col >= len(p.Cell) || (p.Next() == nil && isEmptyRow(p.Row)) {
+			continue
+		}
+		rows = append(rows, p)
+		texts = append(texts, p.Cell[col].Text())
+	}
+	if len(rows) <= 0 {
+		return "no cells to filter", nil
+	}
+	result, err := filterLines(e.Args, texts)
+	if err != nil {
+		return "", err
+	}
+	var changed []*RowPtr
+	var newTexts []string
+	for i, p := range rows {
+		if result[i] == texts[i] {
+			continue
+		}
+		text, err := e.validate(p, col, result[i])
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", p.Index()+1, err)
+		}
+		changed = append(changed, p)
+		newTexts = append(newTexts, text)
+	}
+	if len(changed) <= 0 {
+		return "no cells change", nil
+	}
+	e.setColumn(col, changed, newTexts)
+	return fmt.Sprintf("filtered %d cell(s) (`:%%! -undo` restores them)", len(changed)), nil
+}
@@ -0,0 +1,358 @@
+package csvi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["gitdiff"] = &exCommand{
+		help: "compare the cells with the last commit of the file and mark the changed ones (gitdiff [off])",
+		run:  cmdGitDiff,
+	}
+	exCommands["blame"] = &exCommand{
+		help: "draw the last commit which changed each row in the gutter and show that of the current row, or stop it (blame [off])",
+		run:  cmdBlame,
+	}
+}
+
+// git runs the git command in the directory of fname
+func git(fname string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", filepath.Dir(fname)}, args...)...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(strings.SplitN(msg, "\n", 2)[0])
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+func (app *_Application) gitFilename() (string, error) {
+	if app.Filename == "" || strings.Contains(app.Filename, "://") {
+		return "", errors.New("git: no local file")
+	}
+	return filepath.Abs(app.Filename)
+}
+
+// lcs returns the pairs of the indexes of the longest common subsequence
+// of the sequences of n and m elements compared by eq, with the algorithm
+// of Myers taking O((n+m)d) time for d differences.
+func lcs(n, m int, eq func(i, j int) bool) [][2]int {
+	// trace[d] are the furthest x on the diagonals k=-d..d after d edits
+	var trace [][]int
+	v := make([]int, 2*(n+m)+3)
+	offset := n + m + 1
+	for d := 0; d <= n+m; d++ {
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && eq(x, y) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+			}
+		}
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+		if done {
+			break
+		}
+	}
+	var pairs [][2]int
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1] // the diagonals -(d-1)..d-1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			pairs = append(pairs, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		pairs = append(pairs, [2]int{x, y})
+	}
+	slices.Reverse(pairs)
+	return pairs
+}
+
+// diffRows maps rows to the rows of base. The rows not in the longest
+// common subsequence are paired in the order between the common ones to
+// compare their cells, and the rest of rows are mapped to nil as added.
+func diffRows(base []uncsv.Row, rows []*uncsv.Row) map[*uncsv.Row]*uncsv.Row {
+	result := make(map[*uncsv.Row]*uncsv.Row, len(rows))
+	pairs := lcs(len(base), len(rows), func(i, j int) bool {
+		return sameRow(&base[i], rows[j])
+	})
+	i, j := 0, 0
+	for _, pair := range append(pairs, [2]int{len(base), len(rows)}) {
+		for ; j < pair[1]; j++ {
+			if i < pair[0] {
+				result[rows[j]] = &base[i]
+				i++
+			} else {
+				result[rows[j]] = nil
+			}
+		}
+		if pair[1] < len(rows) {
+			result[rows[pair[1]]] = &base[pair[0]]
+		}
+		i, j = pair[0]+1, pair[1]+1
+	}
+	return result
+}
+
+// gitChanged returns true when the cell (row,col) differs from the last
+// commit compared by `:gitdiff`. The rows added after it are not.
+func (app *_Application) gitChanged(row *uncsv.Row, col int) bool {
+	if app.gitBase == nil || row == nil {
+		return false
+	}
+	base, ok := app.gitBase[row]
+	if !ok {
+		return false
+	}
+	return cellAt(base, col).Text() != cellAt(row, col).Text()
+}
+
+// restoreFromHead replaces the cell (row,col) changed only from the last
+// commit with its text there, and returns false for the other cells.
+func (app *_Application) restoreFromHead(row *RowPtr, col int) bool {
+	if row.Cell[col].Modified() || !app.gitChanged(row.Row, col) {
+		return false
+	}
+	row.Replace(col, cellAt(app.gitBase[row.Row], col).Text(), app.Mode)
+	return true
+}
+
+func cmdGitDiff(e *exCommandArgs) (string, error) {
+	if e.Args == "off" {
+		e.gitBase = nil
+		e.view.clearCache()
+		return "the cells are not compared with HEAD", nil
+	}
+	fname, err := e.gitFilename()
+	if err != nil {
+		return "", err
+	}
+	head, err := git(fname, nil, "show", "HEAD:./"+filepath.Base(fname))
+	if err != nil {
+		return "", err
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	mode := *e.Mode
+	base, err := uncsv.ReadAll(bytes.NewReader(head), &mode)
+	if err != nil {
+		return "", err
+	}
+	if L := len(base); L > 0 && isEmptyRow(&base[L-1]) {
+		base = base[:L-1]
+	}
+	var rows []*uncsv.Row
+	for p := e.Front(); p != nil; p = p.Next() {
+		rows = append(rows, p.Row)
+	}
+	e.gitBase = diffRows(base, rows)
+	count := 0
+	for _, row := range rows {
+		for i := range row.Cell {
+			if e.gitChanged(row, i) {
+				count++
+			}
+		}
+	}
+	e.view.clearCache()
+	return fmt.Sprintf("%d cell(s) differ from HEAD (`]` and `[` jump to them, `u` restores)", count), nil
+}
+
+// lineSpan returns the number of the lines of the row in the file
+func (app *_Application) lineSpan(row *uncsv.Row) int {
+	n := 0
+	for _, c := range row.Cell {
+		n += strings.Count(c.SourceText(app.Mode), "\n")
+	}
+	if row.Term != "" {
+		n++
+	}
+	return n
+}
+
+// fileLine returns the 1-based line number of the row in the file
+func (app *_Application) fileLine(row *RowPtr) int {
+	line := 1
+	for p := app.Front(); p != nil && p.Index() < row.Index(); p = p.Next() {
+		line += app.lineSpan(p.Row)
+	}
+	return line
+}
+
+// blameCommit is the last commit which changed a line by `git blame`
+type blameCommit struct {
+	hash    string
+	author  string
+	summary string
+	date    time.Time
+}
+
+// blameWidth is the width of the gutter of `:blame`: the hash of 8
+// characters, the date and a space
+const blameWidth = 20
+
+func (c *blameCommit) committed() bool {
+	return strings.Trim(c.hash, "0") != ""
+}
+
+func (c *blameCommit) String() string {
+	if !c.committed() {
+		return "not committed yet"
+	}
+	return fmt.Sprintf("%.8s %s %s %s", c.hash, c.date.Format("2006-01-02"), c.author, c.summary)
+}
+
+// gutter returns the text of the commit drawn in the gutter
+func (c *blameCommit) gutter() string {
+	text := "(uncommitted)"
+	if c.committed() {
+		text = fmt.Sprintf("%.8s %s", c.hash, c.date.Format("2006-01-02"))
+	}
+	return text + strings.Repeat(" ", blameWidth-len(text))
+}
+
+// parseBlame reads the output of `git blame --porcelain` and returns the
+// commits of the lines by the 1-based line number
+func parseBlame(output []byte) map[int]*blameCommit {
+	commits := map[string]*blameCommit{}
+	lines := map[int]*blameCommit{}
+	var current *blameCommit
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		if fields := strings.Fields(value); len(key) == 40 && len(fields) >= 2 {
+			if current = commits[key]; current == nil {
+				current = &blameCommit{hash: key}
+				commits[key] = current
+			}
+			if n, err := strconv.Atoi(fields[1]); err == nil {
+				lines[n] = current
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch key {
+		case "author":
+			current.author = value
+		case "author-time":
+			if t, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.date = time.Unix(t, 0)
+			}
+		case "summary":
+			current.summary = value
+		}
+	}
+	return lines
+}
+
+// blameGutter returns the text drawn in the gutter of the row by `:blame`
+func (cfg *Config) blameGutter(row *uncsv.Row) string {
+	if c := cfg.blame[row]; c != nil {
+		return c.gutter()
+	}
+	return strings.Repeat(" ", blameWidth)
+}
+
+func cmdBlame(e *exCommandArgs) (string, error) {
+	if e.Args == "off" {
+		e.blame = nil
+		e.view.clearCache()
+		return "blame off", nil
+	}
+	fname, err := e.gitFilename()
+	if err != nil {
+		return "", err
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	dump(e._Application, &buffer)
+	output, err := git(fname, buffer.Bytes(), "blame", "--porcelain", "--contents", "-",
+		"--", filepath.Base(fname))
+	if err != nil {
+		return "", err
+	}
+	lines := parseBlame(output)
+	e.blame = map[*uncsv.Row]*blameCommit{}
+	line := 1
+	for p := e.Front(); p != nil; p = p.Next() {
+		if c := lines[line]; c != nil {
+			e.blame[p.Row] = c
+		}
+		line += e.lineSpan(p.Row)
+	}
+	e.view.clearCache()
+	if c := e.blame[e.CursorRow.Row]; c != nil {
+		return c.String(), nil
+	}
+	return "not committed yet", nil
+}
+
+// gitCommit asks a message and commits the file saved
+func (app *_Application) gitCommit(fname string) error {
+	fname, err := filepath.Abs(fname)
+	if err != nil {
+		return err
+	}
+	message, err := app.Pilot.ReadLine(app.out, "commit message>", "", nil)
+	if err != nil || strings.TrimSpace(message) == "" {
+		return nil
+	}
+	base := filepath.Base(fname)
+	if _, err := git(fname, nil, "add", "--", base); err != nil {
+		return err
+	}
+	if _, err := git(fname, nil, "commit", "-m", message, "--", base); err != nil {
+		return err
+	}
+	return nil
+}
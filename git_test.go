@@ -0,0 +1,119 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestParseBlame(t *testing.T) {
+	hash1 := strings.Repeat("1", 40)
+	hash0 := strings.Repeat("0", 40)
+	output := hash1 + " 1 1 2\n" +
+		"author Alice\n" +
+		"author-time 1700000000\n" +
+		"summary first commit\n" +
+		"filename a.csv\n" +
+		"\ta,1\n" +
+		hash1 + " 2 2\n" +
+		"\tb,2\n" +
+		hash0 + " 3 3 1\n" +
+		"author Not Committed Yet\n" +
+		"filename a.csv\n" +
+		"\tc,3\n"
+	lines := parseBlame([]byte(output))
+	if len(lines) != 3 || lines[1] != lines[2] {
+		t.Fatalf("expect the lines 1 and 2 by the same commit but %v", lines)
+	}
+	if s := lines[1].String(); !strings.HasPrefix(s, "11111111 ") || !strings.HasSuffix(s, " Alice first commit") {
+		t.Fatalf("unexpected commit: %q", s)
+	}
+	if s := lines[3].String(); s != "not committed yet" {
+		t.Fatalf("expect not committed yet but %q", s)
+	}
+	for _, c := range lines {
+		if n := len(c.gutter()); n != blameWidth {
+			t.Fatalf("the gutter %q is not %d wide", c.gutter(), blameWidth)
+		}
+	}
+}
+
+func TestRestoreReadOnly(t *testing.T) {
+	doc, err := ReadDocument(strings.NewReader("a,b\n"), &uncsv.Mode{Comma: ','})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := doc.SetCell(0, 0, "X"); err != nil {
+		t.Fatal(err.Error())
+	}
+	var out strings.Builder
+	cfg := &Config{Pilot: NewAutoPilot("u|q|y"), ReadOnly: true}
+	if _, err := cfg.EditDocument(doc, &out); err != nil {
+		t.Fatal(err.Error())
+	}
+	if text := doc.Row(0)[0]; text != "X" {
+		t.Fatalf("the cell is restored in the read-only mode: %q", text)
+	}
+	if !strings.Contains(out.String(), msgReadOnly) {
+		t.Fatalf("the warning is not shown: %q", out.String())
+	}
+}
+
+func TestLCS(t *testing.T) {
+	for _, c := range []struct {
+		a, b   string
+		expect string
+	}{
+		{"abcabba", "cbabac", "baba"},
+		{"", "abc", ""},
+		{"abc", "abc", "abc"},
+		{"xabcy", "abc", "abc"},
+		{"abc", "def", ""},
+	} {
+		pairs := lcs(len(c.a), len(c.b), func(i, j int) bool { return c.a[i] == c.b[j] })
+		var common strings.Builder
+		for _, p := range pairs {
+			if c.a[p[0]] != c.b[p[1]] {
+				t.Fatalf("%s,%s: %v are not equal", c.a, c.b, p)
+			}
+			common.WriteByte(c.a[p[0]])
+		}
+		if len(common.String()) != len(c.expect) {
+			t.Fatalf("%s,%s: expect %s but %s", c.a, c.b, c.expect, common.String())
+		}
+	}
+}
+
+func TestDiffRows(t *testing.T) {
+	mode := &uncsv.Mode{Comma: ','}
+	base, err := uncsv.ReadAll(strings.NewReader("a,1\nb,2\nc,3\nd,4"), mode)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	current, err := uncsv.ReadAll(strings.NewReader("x,0\na,1\nb,22\nc,3\ne,5"), mode)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	rows := make([]*uncsv.Row, len(current))
+	for i := range current {
+		rows[i] = &current[i]
+	}
+	result := diffRows(base, rows)
+	for i, expect := range []*uncsv.Row{nil, &base[0], &base[1], &base[2], &base[3]} {
+		if result[rows[i]] != expect {
+			t.Fatalf("row %d: expect %v but %v", i, expect, result[rows[i]])
+		}
+	}
+	app := &_Application{gitBase: result}
+	for _, c := range []struct {
+		row, col int
+		expect   bool
+	}{
+		{0, 0, true}, {1, 0, false}, {1, 1, false}, {2, 0, false}, {2, 1, true}, {4, 0, true},
+	} {
+		if app.gitChanged(rows[c.row], c.col) != c.expect {
+			t.Fatalf("(%d,%d): expect %v", c.row, c.col, c.expect)
+		}
+	}
+}
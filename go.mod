@@ -13,6 +13,7 @@ require (
 	github.com/nyaosorg/go-readline-ny v1.3.0
 	github.com/nyaosorg/go-readline-skk v0.3.1
 	github.com/nyaosorg/go-windows-mbcs v0.4.2
+	github.com/rivo/uniseg v0.4.7
 	golang.org/x/text v0.14.0
 )
 
@@ -20,7 +21,6 @@ require (
 	github.com/atotto/clipboard v0.1.4 // indirect
 	github.com/hymkor/go-windows1x-virtualterminal v0.5.0 // indirect
 	github.com/nyaosorg/go-box/v2 v2.2.0 // indirect
-	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.19.0 // indirect
 	golang.org/x/term v0.19.0 // indirect
 )
@@ -0,0 +1,104 @@
+package csvi
+
+import (
+	"fmt"
+)
+
+func init() {
+	exCommands["heatmap"] = &exCommand{
+		help: "paint the numbers of the current column from blue for the minimum to red for the maximum, or stop it",
+		run: func(e *exCommandArgs) (string, error) {
+			if e.heat != nil && e.heat.col == e.CursorCol {
+				e.heat = nil
+				e.view.clearCache()
+				return "heatmap off", nil
+			}
+			e.heat = &heatmap{col: e.CursorCol}
+			e.updateHeatmap()
+			e.view.clearCache()
+			if !e.heat.found {
+				return "heatmap: no numbers in the current column yet", nil
+			}
+			return "heatmap on", nil
+		},
+	}
+}
+
+// heatColors are the background colors of 256 colors from the minimum to
+// the maximum
+var heatColors = []int{21, 27, 33, 39, 45, 51, 49, 47, 46, 82, 118, 154, 190, 226, 220, 214, 208, 202, 196}
+
+// heatmap keeps the range of the numbers of the column painted by
+// `:heatmap`. The rows loaded later extend the range.
+type heatmap struct {
+	col       int
+	min, max  float64
+	found     bool
+	scanned   int
+	editCount int
+}
+
+// updateHeatmap scans the rows not scanned yet for the range, or all rows
+// again after they are modified.
+func (app *_Application) updateHeatmap() {
+	h := app.heat
+	if h == nil {
+		return
+	}
+	if h.editCount != app.editCount {
+		*h = heatmap{col: h.col, editCount: app.editCount}
+	}
+	if h.scanned >= app.Len() {
+		return
+	}
+	p, err := app.rowAt(h.scanned + 1)
+	for ; err == nil && p != nil; p = p.Next() {
+		h.scanned++
+		if p.Index() < app.HeaderLines || h.col >= len(p.Cell) {
+			continue
+		}
+		value, ok := parseNumber(p.Cell[h.col].Text())
+		if !ok {
+			continue
+		}
+		if !h.found {
+			h.min, h.max, h.found = value, value, true
+		} else {
+			h.min = min(h.min, value)
+			h.max = max(h.max, value)
+		}
+	}
+}
+
+// heatStyle returns the escape sequence painting the cell of the column
+// col by the heatmap
+func (cfg *Config) heatStyle(col int, text string) string {
+	h := cfg.heat
+	if h == nil || !h.found || col != h.col {
+		return ""
+	}
+	value, ok := parseNumber(text)
+	if !ok {
+		return ""
+	}
+	i := 0
+	if h.max > h.min {
+		i = int((value - h.min) / (h.max - h.min) * float64(len(heatColors)-1))
+		i = min(max(i, 0), len(heatColors)-1)
+	}
+	return fmt.Sprintf("\x1B[48;5;%d;30m", heatColors[i])
+}
+
+// heatStatus returns the field {heatmap} of the status line: the range
+// painted from blue to red
+func (app *_Application) heatStatus() string {
+	h := app.heat
+	if h == nil || !h.found {
+		return ""
+	}
+	name := app.columnName(h.col)
+	if name == "" {
+		name = fmt.Sprint(h.col + 1)
+	}
+	return fmt.Sprintf("[%s:%g..%g]", name, h.min, h.max)
+}
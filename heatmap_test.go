@@ -0,0 +1,15 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeatmap(t *testing.T) {
+	_, out := runEdit(t, &Config{HeaderLines: 1}, "l|:|heatmap|h|l|q|y", "job,n\nA,1\nB,5\nC,10\n")
+	for _, expect := range []string{"\x1B[48;5;21;30m1\x1B", "\x1B[48;5;196;30m10\x1B", "[n:1..10]"} {
+		if !strings.Contains(out, expect) {
+			t.Fatalf("%q is not drawn: %q", expect, out)
+		}
+	}
+}
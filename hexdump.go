@@ -0,0 +1,42 @@
+package csvi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	exCommands["hex"] = &exCommand{
+		help: "show the raw bytes of the current cell in hexadecimal",
+		run:  cmdHex,
+	}
+}
+
+func hexDumpLines(data []byte) []string {
+	if len(data) <= 0 {
+		return []string{"(empty)"}
+	}
+	return strings.Split(strings.TrimRight(hex.Dump(data), "\n"), "\n")
+}
+
+func cmdHex(e *exCommandArgs) (string, error) {
+	if e.CursorCol >= len(e.CursorRow.Cell) {
+		return "", nil
+	}
+	cell := &e.CursorRow.Cell[e.CursorCol]
+	var lines []string
+	if cell.Modified() {
+		lines = append(lines, "original:")
+		lines = append(lines, hexDumpLines(cell.Original())...)
+		lines = append(lines, "", "current:")
+		lines = append(lines, hexDumpLines(cell.Source())...)
+	} else {
+		lines = hexDumpLines(cell.Original())
+	}
+	title := fmt.Sprintf("(%d,%d): %d bytes [q]close",
+		e.CursorCol+1, e.CursorRow.Index()+1, len(cell.Original()))
+	defer e.view.clearCache()
+	_, _, err := e.listBox(title, lines, 0, e.lfCount, e.screenWidth, e.screenHeight)
+	return "", err
+}
@@ -0,0 +1,135 @@
+package csvi
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func init() {
+	exCommands["hist"] = &exCommand{
+		help: "show the histogram of the numbers of the current column in N buckets, or the N most frequent values of a text column (hist [N])",
+		run:  cmdHistogram,
+	}
+}
+
+// defaultHistogramBins is the number of the buckets of `:hist` without N
+const defaultHistogramBins = 10
+
+type histogramBin struct {
+	label string
+	count int
+}
+
+// numericBins counts values in n buckets of the same width from the
+// minimum to the maximum
+func numericBins(values []float64, n int) []histogramBin {
+	lo, hi := slices.Min(values), slices.Max(values)
+	if lo == hi {
+		return []histogramBin{{label: formatSummaryNumber(lo), count: len(values)}}
+	}
+	bins := make([]histogramBin, n)
+	step := (hi - lo) / float64(n)
+	for i := range bins {
+		from := lo + step*float64(i)
+		if i == n-1 {
+			bins[i].label = fmt.Sprintf("%s..%s", formatSummaryNumber(from), formatSummaryNumber(hi))
+		} else {
+			bins[i].label = fmt.Sprintf("%s..<%s", formatSummaryNumber(from), formatSummaryNumber(from+step))
+		}
+	}
+	for _, v := range values {
+		i := min(int((v-lo)/step), n-1)
+		bins[i].count++
+	}
+	return bins
+}
+
+// valueBins returns the n most frequent values with their counts
+func valueBins(counts map[string]int, n int) []histogramBin {
+	bins := make([]histogramBin, 0, len(counts))
+	for value, count := range counts {
+		bins = append(bins, histogramBin{label: value, count: count})
+	}
+	slices.SortFunc(bins, func(a, b histogramBin) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		return strings.Compare(a.label, b.label)
+	})
+	return bins[:min(n, len(bins))]
+}
+
+// histogramLines draws the bins as the bars in width
+func histogramLines(bins []histogramBin, width int) []string {
+	labelWidth, maxCount := 0, 0
+	for _, b := range bins {
+		labelWidth = max(labelWidth, runewidth.StringWidth(b.label))
+		maxCount = max(maxCount, b.count)
+	}
+	countWidth := len(strconv.Itoa(maxCount))
+	labelWidth = min(labelWidth, width/3)
+	barWidth := max(width-labelWidth-countWidth-3, 1)
+	block := fullBlock()
+	lines := make([]string, len(bins))
+	for i, b := range bins {
+		label := runewidth.FillRight(runewidth.Truncate(b.label, labelWidth, "…"), labelWidth)
+		n := 0
+		if maxCount > 0 {
+			n = (b.count*barWidth + maxCount - 1) / maxCount
+		}
+		lines[i] = fmt.Sprintf("%s │%*d %s", label, countWidth, b.count, strings.Repeat(block, n))
+	}
+	return lines
+}
+
+func cmdHistogram(e *exCommandArgs) (string, error) {
+	n := defaultHistogramBins
+	if e.Args != "" {
+		var err error
+		if n, err = strconv.Atoi(e.Args); err != nil || n <= 0 {
+			return "usage: hist [N]", nil
+		}
+	}
+	col := e.CursorCol
+	var summary columnSummary
+	var values []float64
+	counts := map[string]int{}
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() < e.HeaderLines || col >= len(p.Cell) {
+			continue
+		}
+		text := p.Cell[col].Text()
+		summary.add(text)
+		if v, ok := parseNumber(text); ok {
+			values = append(values, v)
+		}
+		counts[text]++
+	}
+	var bins []histogramBin
+	var kind string
+	if summary.numeric() {
+		bins = numericBins(values, n)
+		kind = fmt.Sprintf("%d numbers", len(values))
+	} else if len(counts) > 0 {
+		bins = valueBins(counts, n)
+		kind = fmt.Sprintf("%d distinct values", len(counts))
+	} else {
+		return "hist: no cells in the current column", nil
+	}
+	name := e.columnName(col)
+	if name == "" {
+		name = fmt.Sprintf("column %d", col+1)
+	}
+	title := fmt.Sprintf("%s: %s", name, kind)
+	if e.loading() {
+		title += " in the rows loaded so far"
+	}
+	title += " [q]close"
+	defer e.view.clearCache()
+	_, _, err := e.listBox(title, histogramLines(bins, e.screenWidth-1), 0, e.lfCount, e.screenWidth, e.screenHeight)
+	return "", err
+}
@@ -0,0 +1,27 @@
+package csvi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	bins := numericBins([]float64{1, 2, 2, 3, 10}, 3)
+	expect := "[{1..<4 4} {4..<7 0} {7..10 1}]"
+	if fmt.Sprint(bins) != expect {
+		t.Fatalf("expect %s but %v", expect, bins)
+	}
+	bins = valueBins(map[string]int{"a": 1, "b": 3, "c": 3}, 2)
+	expect = "[{b 3} {c 3}]"
+	if fmt.Sprint(bins) != expect {
+		t.Fatalf("expect %s but %v", expect, bins)
+	}
+	lines := histogramLines([]histogramBin{{"x", 4}, {"yy", 1}}, 30)
+	if !strings.HasPrefix(lines[0], "x  │4 ") || !strings.HasPrefix(lines[1], "yy │1 ") {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+	if a, b := strings.Count(lines[0], fullBlock()), strings.Count(lines[1], fullBlock()); a != 4*b {
+		t.Fatalf("the bars are not in proportion to the counts: %q", lines)
+	}
+}
@@ -1,13 +1,18 @@
 package nonblock
 
+import (
+	"time"
+)
+
 type _Response struct {
 	data string
 	err  error
 }
 
 type NonBlock struct {
-	chReq chan struct{}
-	chRes chan _Response
+	chReq   chan struct{}
+	chRes   chan _Response
+	pending bool
 }
 
 func New(getter func() (string, error)) *NonBlock {
@@ -28,21 +33,69 @@ func New(getter func() (string, error)) *NonBlock {
 	}
 }
 
+func (w *NonBlock) request() {
+	if !w.pending {
+		w.chReq <- struct{}{}
+		w.pending = true
+	}
+}
+
+func (w *NonBlock) receive() (string, error) {
+	res := <-w.chRes
+	w.pending = false
+	return res.data, res.err
+}
+
 func (w *NonBlock) GetOr(work func() bool) (string, error) {
-	w.chReq <- struct{}{}
+	w.request()
 	for {
 		select {
 		case res := <-w.chRes:
+			w.pending = false
 			return res.data, res.err
 		default:
 			if cont := work(); !cont {
-				res := <-w.chRes
-				return res.data, res.err
+				return w.receive()
 			}
 		}
 	}
 }
 
+// Work calls work until it returns false or a key is typed.
+// The second result is true when a key is typed.
+// When work finishes first, the key typed later is returned by the next
+// GetOr or Work.
+func (w *NonBlock) Work(work func() bool) (string, bool, error) {
+	w.request()
+	for {
+		select {
+		case res := <-w.chRes:
+			w.pending = false
+			return res.data, true, res.err
+		default:
+			if cont := work(); !cont {
+				return "", false, nil
+			}
+		}
+	}
+}
+
+// Wait waits for a key at most timeout. The second result is true when
+// a key is typed. Otherwise the key typed later is returned by the next
+// GetOr, Work or Wait.
+func (w *NonBlock) Wait(timeout time.Duration) (string, bool, error) {
+	w.request()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-w.chRes:
+		w.pending = false
+		return res.data, true, res.err
+	case <-timer.C:
+		return "", false, nil
+	}
+}
+
 func (w *NonBlock) Close() {
 	close(w.chReq)
 }
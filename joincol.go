@@ -0,0 +1,201 @@
+package csvi
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	exCommands["joincol"] = &exCommand{
+		help:  "join the columns listed into the first of them with SEP (joincol COL,COL,... [SEP])",
+		batch: true,
+		run:   cmdJoinColumns,
+	}
+	exCommands["splitcol"] = &exCommand{
+		help:  "split the current column by SEP into new columns at most N (splitcol SEP [N])",
+		batch: true,
+		run:   cmdSplitColumn,
+	}
+}
+
+// checkColumnEdit returns the message why the columns can not be
+// inserted or deleted, or "".
+func (cfg *Config) checkColumnEdit() string {
+	if cfg.ReadOnly {
+		return msgReadOnly
+	}
+	if cfg.FixColumn {
+		return msgColumnFixed
+	}
+	if cfg.ProtectHeader && cfg.HeaderLines > 0 {
+		return msgProtectHeader
+	}
+	return ""
+}
+
+func cmdJoinColumns(e *exCommandArgs) (string, error) {
+	const usage = "usage: joincol COL,COL,... [SEP]"
+	if m := e.checkColumnEdit(); m != "" {
+		return m, nil
+	}
+	list, rest, _ := strings.Cut(strings.TrimSpace(e.Args), " ")
+	if list == "" {
+		return usage, nil
+	}
+	sep, err := unquoteBatchText(strings.TrimSpace(rest))
+	if err != nil {
+		return "", err
+	}
+	cols, err := e.parseColumnList(list)
+	if err != nil {
+		return "", err
+	}
+	if len(cols) < 2 {
+		return usage, nil
+	}
+	for i, col := range cols {
+		if slices.Contains(cols[:i], col) {
+			return fmt.Sprintf("column %d is listed twice", col+1), nil
+		}
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	if e.Config.Pilot != nil && !e.confirm(ConfirmCommand, fmt.Sprintf("join %d columns into column %d ? [y/n]", len(cols), cols[0]+1)) {
+		return "", nil
+	}
+	dst := cols[0]
+	// delete the columns from the right not to shift the others
+	removed := slices.Clone(cols[1:])
+	slices.Sort(removed)
+	slices.Reverse(removed)
+	count := 0
+	for p := e.Front(); p != nil; p = p.Next() {
+		if dst >= len(p.Cell) {
+			continue
+		}
+		texts := make([]string, 0, len(cols))
+		for _, col := range cols {
+			if col < len(p.Cell) {
+				texts = append(texts, p.Cell[col].Text())
+			}
+		}
+		text := strings.Join(texts, sep)
+		e.audit("replace", p, dst, p.Cell[dst].Text(), text)
+		p.Replace(dst, text, e.Mode)
+		for _, col := range removed {
+			if col < len(p.Cell) {
+				e.audit("delete-cell", p, col, p.Cell[col].Text(), "")
+				p.Delete(col)
+			}
+		}
+		e.touch(p, -1)
+		count++
+	}
+	if count <= 0 {
+		return "no rows have the columns", nil
+	}
+	// the cursor stays on the joined column
+	e.CursorCol = dst
+	for _, col := range removed {
+		if col < dst {
+			e.CursorCol--
+		}
+	}
+	e.remapColumnStates(func(col int) int {
+		if col == dst || slices.Contains(removed, col) {
+			return -1
+		}
+		n := col
+		for _, r := range removed {
+			if r < col {
+				n--
+			}
+		}
+		return n
+	})
+	e.lastColumnEdit = nil
+	e.setDirty()
+	return fmt.Sprintf("joined %d columns of %d row(s)", len(cols), count), nil
+}
+
+func cmdSplitColumn(e *exCommandArgs) (string, error) {
+	const usage = "usage: splitcol SEP [N]"
+	if m := e.checkColumnEdit(); m != "" {
+		return m, nil
+	}
+	sep, rest, err := cutBatchArg(e.Args)
+	if err != nil {
+		return "", err
+	}
+	if sep == "" {
+		return usage, nil
+	}
+	limit := -1
+	if rest != "" {
+		limit, err = strconv.Atoi(rest)
+		if err != nil || limit < 2 {
+			return usage, nil
+		}
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	col := e.CursorCol
+	width := 1
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() >= e.HeaderLines && col < len(p.Cell) {
+			width = max(width, len(strings.SplitN(p.Cell[col].Text(), sep, limit)))
+		}
+	}
+	if width <= 1 {
+		return "no cells contain " + sep, nil
+	}
+	name := e.columnName(col)
+	if e.Config.Pilot != nil && !e.confirm(ConfirmCommand, fmt.Sprintf("split the column into %d columns ? [y/n]", width)) {
+		return "", nil
+	}
+	for p := e.Front(); p != nil; p = p.Next() {
+		if col >= len(p.Cell) {
+			continue
+		}
+		var parts []string
+		if p.Index() < e.HeaderLines {
+			// the header keeps the name and names the new columns after it
+			parts = []string{p.Cell[col].Text()}
+			if p.Index() == 0 && name != "" {
+				for i := 2; i <= width; i++ {
+					parts = append(parts, fmt.Sprintf("%s_%d", name, i))
+				}
+			}
+		} else {
+			parts = strings.SplitN(p.Cell[col].Text(), sep, limit)
+		}
+		for len(parts) < width {
+			parts = append(parts, "")
+		}
+		if old := p.Cell[col].Text(); parts[0] != old {
+			e.audit("replace", p, col, old, parts[0])
+			p.Replace(col, parts[0], e.Mode)
+		}
+		for i := 1; i < width; i++ {
+			p.Insert(col+i, parts[i], e.Mode)
+			e.audit("insert-cell", p, col+i, "", parts[i])
+		}
+		e.touch(p, -1)
+	}
+	e.remapColumnStates(func(c int) int {
+		switch {
+		case c == col:
+			return -1
+		case c > col:
+			return c + width - 1
+		}
+		return c
+	})
+	e.lastColumnEdit = nil
+	e.setDirty()
+	return fmt.Sprintf("split the column into %d columns", width), nil
+}
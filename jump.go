@@ -0,0 +1,111 @@
+package csvi
+
+import (
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func cellText(row *RowPtr, col int) string {
+	if col < len(row.Cell) {
+		return row.Cell[col].Text()
+	}
+	return ""
+}
+
+// nextValueChange returns the first row below row whose value in the
+// column col differs from that of row, or nil.
+func nextValueChange(row *RowPtr, col int) *RowPtr {
+	value := cellText(row, col)
+	for p := row.Next(); p != nil; p = p.Next() {
+		if cellText(p, col) != value {
+			return p
+		}
+	}
+	return nil
+}
+
+// prevValueChange returns the first row of the group of the same values
+// in the column col containing row. When row is already the first one,
+// it returns the first row of the previous group. It returns nil when
+// row is the first row of the data.
+func prevValueChange(row *RowPtr, col int) *RowPtr {
+	p := row.Prev()
+	if p == nil {
+		return nil
+	}
+	value := cellText(p, col)
+	for {
+		prev := p.Prev()
+		if prev == nil || cellText(prev, col) != value {
+			return p
+		}
+		p = prev
+	}
+}
+
+// isMarked returns true when the cell (row,col) is modified or changed
+// from the last commit by `:gitdiff`
+func (app *_Application) isMarked(row *uncsv.Row, col int) bool {
+	return row.Cell[col].Modified() || app.gitChanged(row, col)
+}
+
+// nextModified returns the first marked cell after the cell (row,col)
+func (app *_Application) nextModified(row *RowPtr, col int) (*RowPtr, int) {
+	for col++; row != nil; row, col = row.Next(), 0 {
+		for ; col < len(row.Cell); col++ {
+			if app.isMarked(row.Row, col) {
+				return row, col
+			}
+		}
+	}
+	return nil, 0
+}
+
+// prevModified returns the last marked cell before the cell (row,col)
+func (app *_Application) prevModified(row *RowPtr, col int) (*RowPtr, int) {
+	for col--; row != nil; {
+		for ; col >= 0; col-- {
+			if col < len(row.Cell) && app.isMarked(row.Row, col) {
+				return row, col
+			}
+		}
+		if row = row.Prev(); row != nil {
+			col = len(row.Cell) - 1
+		}
+	}
+	return nil, 0
+}
+
+func isEmptyCell(row *RowPtr, col int) bool {
+	return col < len(row.Cell) && row.Cell[col].Text() == ""
+}
+
+// nextEmptyInColumn returns the next row whose cell in the column col is empty
+func nextEmptyInColumn(row *RowPtr, col int) *RowPtr {
+	for p := row.Next(); p != nil; p = p.Next() {
+		if isEmptyCell(p, col) {
+			return p
+		}
+	}
+	return nil
+}
+
+// prevEmptyInColumn returns the previous row whose cell in the column col is empty
+func prevEmptyInColumn(row *RowPtr, col int) *RowPtr {
+	for p := row.Prev(); p != nil; p = p.Prev() {
+		if isEmptyCell(p, col) {
+			return p
+		}
+	}
+	return nil
+}
+
+// emptyInRow returns the column of the next (dir=1) or previous (dir=-1)
+// empty cell in the row, or -1
+func emptyInRow(row *RowPtr, col, dir int) int {
+	for c := col + dir; 0 <= c && c < len(row.Cell); c += dir {
+		if isEmptyCell(row, c) {
+			return c
+		}
+	}
+	return -1
+}
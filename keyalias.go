@@ -0,0 +1,41 @@
+package csvi
+
+import (
+	"github.com/nyaosorg/go-readline-ny/keys"
+)
+
+const (
+	keyCtrlHome = "\x1B[1;5H"
+	keyCtrlEnd  = "\x1B[1;5F"
+)
+
+// keyAliases maps the escape sequences sent for the same key by
+// various terminals, or in the application keypad mode, to the ones
+// handled by the editor.
+var keyAliases = map[string]string{
+	"\x1B[1~": keys.Home, // linux console, screen, tmux
+	"\x1B[7~": keys.Home, // rxvt
+	"\x1BOH":  keys.Home, // xterm (application mode)
+	"\x1B[4~": keys.End,
+	"\x1B[8~": keys.End,
+	"\x1BOF":  keys.End,
+	"\x1B[7^": keyCtrlHome, // rxvt
+	"\x1B[8^": keyCtrlEnd,
+	"\x1B[I":  keys.PageUp, // FreeBSD console (cons25)
+	"\x1BOy":  keys.PageUp, // 9 on the numeric keypad (application mode)
+	"\x1B[G":  keys.PageDown,
+	"\x1BOs":  keys.PageDown, // 3 on the numeric keypad
+	"\x1BOA":  keys.Up,
+	"\x1BOB":  keys.Down,
+	"\x1BOC":  keys.Right,
+	"\x1BOD":  keys.Left,
+	"\x1BOM":  keys.Enter, // Enter on the numeric keypad
+}
+
+// normalizeKey returns the key handled by the editor for key.
+func normalizeKey(key string) string {
+	if alias, ok := keyAliases[key]; ok {
+		return alias
+	}
+	return key
+}
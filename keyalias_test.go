@@ -0,0 +1,26 @@
+package csvi
+
+import (
+	"testing"
+
+	"github.com/nyaosorg/go-readline-ny/keys"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	for key, expect := range map[string]string{
+		"\x1B[1~": keys.Home,
+		"\x1BOF":  keys.End,
+		"\x1BOA":  keys.Up,
+		"\x1B[7^": keyCtrlHome,
+		"\x1B[I":  keys.PageUp,
+		"\x1BOy":  keys.PageUp,
+		"\x1B[G":  keys.PageDown,
+		"\x1BOs":  keys.PageDown,
+		"j":       "j",
+		keys.End:  keys.End,
+	} {
+		if result := normalizeKey(key); result != expect {
+			t.Fatalf("%q: expect %q but %q", key, expect, result)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package csvi
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/nyaosorg/go-readline-ny/keys"
+)
+
+// listBox draws title and lines over the grid and lets the user move the
+// selection with j/k. It returns the first other key pressed and the index
+// selected at that time. On return, the terminal cursor is back on the
+// status line, so the caller has only to clear the view cache.
+func (app *_Application) listBox(title string, lines []string, index, lfCount, screenWidth, screenHeight int) (string, int, error) {
+	rows := screenHeight - 2
+	if rows < 1 {
+		rows = 1
+	}
+	start := 0
+	for {
+		if index >= len(lines) {
+			index = len(lines) - 1
+		}
+		if index < 0 {
+			index = 0
+		}
+		if index < start {
+			start = index
+		} else if index >= start+rows {
+			start = index - rows + 1
+		}
+		up(lfCount, app.out)
+		io.WriteString(app.out, _ANSI_YELLOW)
+		io.WriteString(app.out, runewidth.Truncate(title, screenWidth-1, ""))
+		io.WriteString(app.out, _ANSI_ERASE_LINE)
+		for i := start; i < start+rows; i++ {
+			io.WriteString(app.out, "\r\n")
+			if i < len(lines) {
+				if i == index {
+					io.WriteString(app.out, bodyColorStyle.Cursor[0])
+				}
+				io.WriteString(app.out, runewidth.Truncate(app.replaceControls(lines[i]), screenWidth-1, ""))
+			}
+			io.WriteString(app.out, _ANSI_ERASE_LINE)
+		}
+		if diff := rows - lfCount; diff > 0 {
+			fmt.Fprintf(app.out, "\r\x1B[%dA", diff)
+		} else if diff < 0 {
+			fmt.Fprintf(app.out, "\r\x1B[%dB", -diff)
+		}
+		ch, err := app.GetKey()
+		if err != nil {
+			return "", index, err
+		}
+		ch = normalizeKey(ch)
+		switch ch {
+		case "j", keys.Down, keys.CtrlN:
+			index++
+		case "k", keys.Up, keys.CtrlP:
+			index--
+		default:
+			return ch, index, nil
+		}
+	}
+}
@@ -2,14 +2,16 @@ package csvi
 
 import (
 	"bufio"
-	"container/list"
 	"fmt"
 	"io"
 	"os"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/nyaosorg/go-readline-ny"
 	"github.com/nyaosorg/go-readline-ny/keys"
@@ -18,6 +20,60 @@ import (
 	"github.com/hymkor/csvi/uncsv"
 )
 
+// idleInterval is the interval to call Config.OnIdle
+const idleInterval = 100 * time.Millisecond
+
+// frameInterval is the minimum interval of the frames drawn while the
+// motion keys are repeated
+const frameInterval = time.Second / 30
+
+// motionKeys are the keys only moving the cursor, whose repeats are
+// coalesced into a frame
+var motionKeys = map[string]bool{
+	"j": true, keys.Down: true, keys.CtrlN: true, keys.Enter: true,
+	"k": true, keys.Up: true, keys.CtrlP: true,
+	"h": true, keys.Left: true, keys.CtrlB: true, keys.ShiftTab: true,
+	"l": true, keys.Right: true, keys.CtrlF: true, keys.CtrlI: true,
+	" ": true, keys.PageDown: true,
+	"b": true, keys.PageUp: true,
+}
+
+// isMotionKey returns true when key moves the cursor only. Capturing
+// the frames draws all of them. Enter in PickMode and Space in MultiPick
+// pick the rows instead.
+func (cfg *Config) isMotionKey(key string) bool {
+	if cfg.CaptureFrames != nil {
+		return false
+	}
+	key = normalizeKey(key)
+	if (key == keys.Enter && cfg.PickMode) || (key == " " && cfg.MultiPick) {
+		return false
+	}
+	_, mapped := cfg.KeyMap[key]
+	return !mapped && motionKeys[key]
+}
+
+// frameNow returns the time to decide whether the frame is drawn
+func (cfg *Config) frameNow() time.Time {
+	if cfg.frameClock != nil {
+		return cfg.frameClock()
+	}
+	return time.Now()
+}
+
+// recoverTerminal is deferred to recover from the panic in the event loop.
+// It shows the cursor and resets the colors left by the screen drawn
+// halfway, and then prints the panic with the stack to the standard error.
+func recoverTerminal(out io.Writer, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	io.WriteString(out, _ANSI_RESET+_ANSI_CURSOR_ON+"\n")
+	fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", r, debug.Stack())
+	*err = fmt.Errorf("panic: %v", r)
+}
+
 const (
 	_ANSI_CURSOR_OFF = "\x1B[?25l"
 	_ANSI_CURSOR_ON  = "\x1B[?25h"
@@ -29,6 +85,15 @@ const (
 
 	_ANSI_UNDERLINE_ON  = "\x1B[4m"
 	_ANSI_UNDERLINE_OFF = "\x1B[24m"
+	_ANSI_REVERSE_ON    = "\x1B[7m"
+	_ANSI_REVERSE_OFF   = "\x1B[27m"
+	_ANSI_RED_ON        = "\x1B[31m"
+
+	// _OSC_LINK_ON makes the text a hyperlink on the terminals supporting
+	// OSC 8, which draw it in their own way apart from the underline of
+	// the modified cells. The others ignore it.
+	_OSC_LINK_ON  = "\x1B]8;;%s\x1B\\"
+	_OSC_LINK_OFF = "\x1B]8;;\x1B\\"
 )
 
 type _ColorStyle struct {
@@ -49,30 +114,74 @@ var headColorStyle = _ColorStyle{
 	Odd:    [...]string{"\x1B[40;36;1m", "\x1B[22m"},
 }
 
-var replaceTable = strings.NewReplacer(
-	"\r", "\u240D",
-	"\x1B", "\u241B",
-	"\n", "\u240A",
-	"\t", "\u2409")
+// displayText converts the text of a cell into the form to draw.
+// The cell cut by MaxCellLength of uncsv.Mode is marked with TruncatedMarker.
+func (cfg *Config) displayText(cell uncsv.Cell) string {
+	text := cell.Text()
+	if cell.Truncated() {
+		text += cfg.TruncatedMarker
+	}
+	if cfg.NormalizeNFC {
+		text = norm.NFC.String(text)
+	}
+	if cfg.ShowBlank {
+		text = visualizeBlank(text)
+	} else if cfg.StrictGrid && text == "" {
+		text = emptyCellMark
+	}
+	return cfg.replaceControls(cfg.escapeBidi(text))
+}
+
+// truncate cuts text in width with TruncateMarker
+func (cfg *Config) truncate(text string, width int) string {
+	ss, _ := cutStrInWidth(text, width)
+	if len(ss) < len(text) && cfg.TruncateMarker != "" {
+		ss, _ = cutStrInWidth(text, width-runewidth.StringWidth(cfg.TruncateMarker))
+		ss += cfg.TruncateMarker
+	}
+	return ss
+}
 
-// See. en.wikipedia.org/wiki/Unicode_control_characters#Control_pictures
+// lineBadge returns the mark like `⤶3` drawn after the text of a cell
+// containing line breaks, with the number of its lines
+func lineBadge(text string) string {
+	n := strings.Count(text, "\n")
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("⤶%d", n+1)
+}
 
+// drawLine draws a row on one screen line. When wrapLine is zero or more,
+// the texts of cells are wrapped in their widths and only the wrapLine-th
+// line of them is drawn. It returns the number of screen lines which the
+// row requires. firstCol is the index of the column drawn from, whose
+// cell is csvs[0] or csvs[1] after the pinned column. The cells of header
+// lines are not checked by the date layouts.
 func drawLine(
+	cfg *Config,
+	row *uncsv.Row,
 	csvs []uncsv.Cell,
+	firstCol int,
+	header bool,
 	cellWidth int,
 	screenWidth int,
 	cursorPos int,
+	wrapLine int,
 	reverse bool,
 	style *_ColorStyle,
-	out io.Writer) {
+	marks *cellMarks,
+	out io.Writer) int {
 
 	if len(csvs) <= 0 && cursorPos >= 0 {
 		io.WriteString(out, style.Cursor[0])
 		io.WriteString(out, "\x1B[K")
 		io.WriteString(out, style.Cursor[1])
-		return
+		return 1
 	}
 	i := 0
+	x := cfg.gutterWidth()
+	height := 1
 
 	if reverse {
 		io.WriteString(out, style.Odd[0])
@@ -88,28 +197,86 @@ func drawLine(
 		text := cursor.Text()
 		csvs = csvs[1:]
 		nextI := i + 1
+		col := cfg.columnAt(firstCol, i)
 
-		cw := cellWidth
-		for len(csvs) > 0 && csvs[0].Text() == "" && nextI != cursorPos {
-			cw += cellWidth
+		cw := cfg.columnWidth(col, cellWidth)
+		for len(csvs) > 0 && csvs[0].Text() == "" && nextI != cursorPos && cfg.mergesEmptyCells() {
+			cw += cfg.columnWidth(cfg.columnAt(firstCol, nextI), cellWidth)
 			csvs = csvs[1:]
 			nextI++
 		}
-		if cw > screenWidth || len(csvs) <= 0 {
+		last := cw > screenWidth || len(csvs) <= 0
+		if last {
 			cw = screenWidth
 		}
-		text = replaceTable.Replace(text)
-		ss, _ := cutStrInWidth(text, cw)
+		tw := cw
+		if !last {
+			tw -= runewidth.StringWidth(cfg.ColumnSeparator)
+		}
+		text = cfg.displayText(cursor)
+		if i == cursorPos && wrapLine < 0 && cfg.cellOffset > 0 {
+			text = skipChars(text, cfg.cellOffset)
+		}
+		var ss string
+		var bar string
+		var isBar bool
+		if !header {
+			bar, isBar = cfg.progressText(col, cursor.Text(), tw)
+		}
+		if isBar {
+			if wrapLine <= 0 {
+				ss = bar
+			}
+		} else if wrapLine < 0 {
+			if badge := lineBadge(cursor.Text()); badge != "" && runewidth.StringWidth(badge) < tw {
+				ss = cfg.truncate(text, tw-runewidth.StringWidth(badge)) + badge
+			} else {
+				ss = cfg.truncate(text, tw)
+			}
+		} else {
+			lines := wrapInWidth(text, tw)
+			if wrapLine < len(lines) {
+				ss = lines[wrapLine]
+			}
+			height = max(height, len(lines))
+		}
 		if i == cursorPos {
 			io.WriteString(out, style.Cursor[0])
 		}
-		if cursor.Modified() {
+		invalid := !header && marks != nil && !marks.valid(col, cursor.Text())
+		if invalid {
+			io.WriteString(out, _ANSI_RED_ON)
+		}
+		painted := ""
+		if !header && !invalid && i != cursorPos {
+			painted = cfg.cellStyle(col, cursor.Text())
+			io.WriteString(out, painted)
+		}
+		underline := cursor.Modified() || (!header && marks != nil && marks.changed(row, col))
+		if underline {
 			io.WriteString(out, _ANSI_UNDERLINE_ON)
 		}
+		link := cellURL(cursor.Text())
+		if link != "" {
+			fmt.Fprintf(out, _OSC_LINK_ON, link)
+		}
 		io.WriteString(out, ss)
-		if cursor.Modified() {
+		if link != "" {
+			io.WriteString(out, _OSC_LINK_OFF)
+		}
+		if underline {
 			io.WriteString(out, _ANSI_UNDERLINE_OFF)
 		}
+		if invalid || painted != "" {
+			// restore the colors of the cursor or the row
+			if i == cursorPos {
+				io.WriteString(out, style.Cursor[0])
+			} else if reverse {
+				io.WriteString(out, style.Odd[0])
+			} else {
+				io.WriteString(out, style.Even[0])
+			}
+		}
 		if i == cursorPos {
 			io.WriteString(out, "\x1B[K")
 			if reverse {
@@ -122,12 +289,17 @@ func drawLine(
 		if screenWidth <= 0 {
 			break
 		}
-		fmt.Fprintf(out, "\x1B[%dG", nextI*cellWidth+1)
+		if !last && cfg.ColumnSeparator != "" {
+			fmt.Fprintf(out, "\x1B[%dG%s", x+tw+1, cfg.ColumnSeparator)
+		}
+		x += cw
+		fmt.Fprintf(out, "\x1B[%dG", x+1)
 		if i == cursorPos {
 			io.WriteString(out, "\x1B[K")
 		}
 		i = nextI
 	}
+	return height
 }
 
 func up(n int, out io.Writer) {
@@ -140,28 +312,45 @@ func up(n int, out io.Writer) {
 	}
 }
 
-func drawPage(page func(func([]uncsv.Cell) bool), cellWidth, csrpos, csrlin, w, h int, style *_ColorStyle, cache map[int]string, out io.Writer) int {
+func drawPage(cfg *Config, page func(func(*uncsv.Row, []uncsv.Cell) bool), firstCol int, header bool, cellWidth, csrpos, csrlin, w, h int, wrap bool, style *_ColorStyle, marks *cellMarks, cache map[int]string, out io.Writer) int {
 	reverse := false
 	count := 0
+	lines := 0
 	lfCount := 0
-	page(func(record []uncsv.Cell) bool {
-		if count >= h {
+	page(func(row *uncsv.Row, record []uncsv.Cell) bool {
+		if lines >= h {
 			return false
 		}
-		if count > 0 {
-			lfCount++
-			io.WriteString(out, "\r\n") // "\r" is for Linux and go-tty
-		}
 		cursorPos := -1
 		if count == csrlin {
 			cursorPos = csrpos
 		}
-		var buffer strings.Builder
-		drawLine(record, cellWidth, w, cursorPos, reverse, style, &buffer)
-		line := buffer.String()
-		if f := cache[count]; f != line {
-			io.WriteString(out, line)
-			cache[count] = line
+		wrapLine := -1
+		if wrap {
+			wrapLine = 0
+		}
+		for {
+			if lines > 0 {
+				lfCount++
+				io.WriteString(out, "\r\n") // "\r" is for Linux and go-tty
+			}
+			var buffer strings.Builder
+			if wrapLine <= 0 {
+				buffer.WriteString(cfg.gutter(row))
+			} else {
+				buffer.WriteString(cfg.gutter(nil))
+			}
+			height := drawLine(cfg, row, record, firstCol, header, cellWidth, w, cursorPos, wrapLine, reverse, style, marks, &buffer)
+			line := buffer.String()
+			if f := cache[lines]; f != line {
+				io.WriteString(out, line)
+				cache[lines] = line
+			}
+			lines++
+			if wrapLine < 0 || wrapLine+1 >= height || lines >= h {
+				break
+			}
+			wrapLine++
 		}
 		reverse = !reverse
 		count++
@@ -180,9 +369,18 @@ func cellsAfter(cells []uncsv.Cell, n int) []uncsv.Cell {
 	}
 }
 
+// cellMarks tells how to mark the cells of the body besides the modified ones
+type cellMarks struct {
+	// valid returns false for the cells drawn in red
+	valid func(col int, text string) bool
+	// changed returns true for the cells underlined as the modified ones
+	changed func(row *uncsv.Row, col int) bool
+}
+
 type _View struct {
 	headCache map[int]string
 	bodyCache map[int]string
+	marks     *cellMarks
 }
 
 func newView() *_View {
@@ -197,21 +395,37 @@ func (v *_View) clearCache() {
 	clear(v.bodyCache)
 }
 
-func (v *_View) Draw(header, startRow, cursorRow *RowPtr, cellWidth, headerLines, startCol, cursorCol, screenHeight, screenWidth int, out io.Writer) int {
+func (v *_View) Draw(cfg *Config, header, startRow, cursorRow *RowPtr, cellWidth, startCol, cursorCol, screenHeight, screenWidth int, out io.Writer) int {
 	// print header
+	headerLines := cfg.HeaderLines
 	lfCount := 0
-	if h := headerLines; h > 0 {
-		enum := func(callback func([]uncsv.Cell) bool) {
+	if h := cfg.headerHeight(); h > 0 {
+		enum := func(callback func(*uncsv.Row, []uncsv.Cell) bool) {
 			for i := 0; i < h && header != nil; i++ {
-				if !callback(cellsAfter(header.Cell, startCol)) {
+				if !callback(nil, cfg.cellsFrom(header.Cell, startCol)) {
 					return
 				}
 				header = header.Next()
 			}
 		}
-		lfCount = drawPage(enum, cellWidth, cursorCol-startCol, cursorRow.lnum, screenWidth-1, h, &headColorStyle, v.headCache, out)
+		csrlin := cursorRow.Index()
+		if headerLines <= 0 {
+			pseudo := cfg.pseudoHeader(header, cursorRow)
+			enum = func(callback func(*uncsv.Row, []uncsv.Cell) bool) {
+				callback(nil, cfg.cellsFrom(pseudo.Cell, startCol))
+			}
+			csrlin = -1
+		}
+		lfCount = drawPage(cfg, enum, startCol, true, cellWidth, cfg.screenCol(cursorCol, startCol), csrlin, screenWidth-1, h, false, &headColorStyle, nil, v.headCache, out)
+		if rule := cfg.HeaderRule; rule != "" {
+			if w := runewidth.StringWidth(rule); w > 0 {
+				io.WriteString(out, cfg.gutter(nil)+strings.Repeat(rule, (screenWidth-1)/w))
+			}
+			io.WriteString(out, _ANSI_ERASE_LINE+"\r\n")
+			lfCount++
+		}
 	}
-	if startRow.lnum < headerLines {
+	if startRow.Index() < headerLines {
 		for i := 0; i < headerLines && startRow != nil; i++ {
 			startRow = startRow.Next()
 		}
@@ -221,9 +435,9 @@ func (v *_View) Draw(header, startRow, cursorRow *RowPtr, cellWidth, headerLines
 	}
 	p := startRow.Clone()
 	// print body
-	enum := func(callback func([]uncsv.Cell) bool) {
+	enum := func(callback func(*uncsv.Row, []uncsv.Cell) bool) {
 		for p != nil {
-			if !callback(cellsAfter(p.Cell, startCol)) {
+			if !callback(p.Row, cfg.cellsFrom(p.Cell, startCol)) {
 				return
 			}
 			p = p.Next()
@@ -237,7 +451,16 @@ func (v *_View) Draw(header, startRow, cursorRow *RowPtr, cellWidth, headerLines
 			Odd:    bodyColorStyle.Even,
 		}
 	}
-	return lfCount + drawPage(enum, cellWidth, cursorCol-startCol, cursorRow.lnum-startRow.lnum, screenWidth-1, screenHeight-1, style, v.bodyCache, out)
+	lfCount += drawPage(cfg, enum, startCol, false, cellWidth, cfg.screenCol(cursorCol, startCol), cursorRow.Index()-startRow.Index(), screenWidth-1, screenHeight-1, cfg.Wrap, style, v.marks, v.bodyCache, out)
+	if cfg.Summary != "" {
+		// not cached because the line moves with the number of rows drawn
+		row := cfg.summaryRow(cellWidth)
+		enum := func(callback func(*uncsv.Row, []uncsv.Cell) bool) {
+			callback(nil, cfg.cellsFrom(row.Cell, startCol))
+		}
+		lfCount += drawPage(cfg, enum, startCol, true, cellWidth, -1, -1, screenWidth-1, 1, false, &headColorStyle, nil, map[int]string{}, out)
+	}
+	return lfCount
 }
 
 func (app *_Application) YesNo(message string) bool {
@@ -248,42 +471,91 @@ func (app *_Application) YesNo(message string) bool {
 	return err == nil && ch == "y"
 }
 
-func first[T any](value T, _ error) T {
-	return value
-}
+// DefaultInitialRows is the number of rows read before the screen is
+// drawn first when Config.InitialRows is zero.
+const DefaultInitialRows = 100
+
+// DefaultReadAheadRows is the number of rows read at once while no keys
+// are typed when Config.ReadAheadRows is zero.
+const DefaultReadAheadRows = 100
 
-func printStatusLine(out io.Writer, mode *uncsv.Mode, cursorRow *RowPtr, cursorCol int, screenWidth int) {
-	n := 0
+// DefaultStatusFormat is the template of the status line used when
+// Config.StatusFormat is empty.
+const DefaultStatusFormat = "{sep}{eol}{enc}{warn}{heatmap}({col}{offset},{row}/{rows}){header}: {cell}"
+
+func (app *_Application) printStatusLine(out io.Writer, cursorRow *RowPtr, cursorCol int, screenWidth int) {
+	mode := app.Mode
+	sep := ""
 	if mode.Comma == '\t' {
-		n += first(io.WriteString(out, "[TSV]"))
+		sep = "[TSV]"
 	} else if mode.Comma == ',' {
-		n += first(io.WriteString(out, "[CSV]"))
+		sep = "[CSV]"
 	}
+	eol := ""
 	switch cursorRow.Term {
 	case "\r\n":
-		n += first(io.WriteString(out, "[CRLF]"))
+		eol = "[CRLF]"
 	case "\n":
-		n += first(io.WriteString(out, "[LF]"))
+		eol = "[LF]"
 	case "":
-		n += first(io.WriteString(out, "[EOF]"))
+		eol = "[EOF]"
+	}
+	if app.mixedEOL() {
+		eol += "[MIXED]"
 	}
+	enc := ""
 	if mode.HasBom() {
-		n += first(io.WriteString(out, "[BOM]"))
+		enc = "[BOM]"
 	}
 	if mode.NonUTF8 {
 		if mode.IsUTF16LE() {
-			n += first(io.WriteString(out, "[16LE]"))
+			enc += "[16LE]"
 		} else if mode.IsUTF16BE() {
-			n += first(io.WriteString(out, "[16BE]"))
+			enc += "[16BE]"
+		} else if name := mode.EncodingName(); name != "" {
+			enc += "[" + name + "]"
 		} else {
-			n += first(io.WriteString(out, "[ANSI]"))
+			enc += "[ANSI]"
 		}
 	}
-	if 0 <= cursorCol && cursorCol < len(cursorRow.Cell) {
-		n += first(fmt.Fprintf(out, "(%d,%d/%d): ",
-			cursorCol+1,
-			cursorRow.lnum+1,
-			cursorRow.list.Len()))
+	colName := app.columnName(cursorCol)
+	header := ""
+	if colName != "" {
+		header = "[" + app.replaceControls(colName) + "]"
+	}
+	modified := ""
+	if app.dirty {
+		modified = "[+]"
+	}
+	filter := ""
+	if pattern := app.registers[registerSearch]; pattern != "" {
+		filter = "[/" + app.replaceControls(pattern) + "]"
+	}
+	format := app.StatusFormat
+	if format == "" {
+		format = DefaultStatusFormat
+	}
+	fields := strings.NewReplacer(
+		"{file}", app.replaceControls(app.escapeBidi(app.Filename)),
+		"{sep}", sep,
+		"{eol}", eol,
+		"{enc}", enc,
+		"{warn}", app.warningStatus(),
+		"{col}", fmt.Sprint(cursorCol+1),
+		"{offset}", app.offsetStatus(),
+		"{heatmap}", app.heatStatus(),
+		"{colname}", colName,
+		"{header}", header,
+		"{row}", fmt.Sprint(cursorRow.Index()+1),
+		"{rows}", fmt.Sprint(cursorRow.list.Len()),
+		"{modified}", modified,
+		"{filter}", filter)
+	before, after, hasCell := strings.Cut(format, "{cell}")
+	before = fields.Replace(before)
+	after = fields.Replace(after)
+	io.WriteString(out, before)
+	n := runewidth.StringWidth(before) + runewidth.StringWidth(after)
+	if hasCell && 0 <= cursorCol && cursorCol < len(cursorRow.Cell) {
 		var buffer strings.Builder
 		buffer.WriteString(cursorRow.Cell[cursorCol].SourceText(mode))
 		if cursorCol < len(cursorRow.Cell)-1 {
@@ -293,8 +565,13 @@ func printStatusLine(out io.Writer, mode *uncsv.Mode, cursorRow *RowPtr, cursorC
 		} else { // EOF
 			buffer.WriteString("\u2592")
 		}
-		io.WriteString(out, runewidth.Truncate(replaceTable.Replace(buffer.String()), screenWidth-n, "..."))
+		cell := runewidth.Truncate(app.replaceControls(app.escapeBidi(buffer.String())), screenWidth-n, "...")
+		if pattern := app.registers[registerSearch]; pattern != "" {
+			cell = strings.ReplaceAll(cell, pattern, _ANSI_REVERSE_ON+pattern+_ANSI_REVERSE_OFF)
+		}
+		io.WriteString(out, cell)
 	}
+	io.WriteString(out, after)
 }
 
 type Pilot interface {
@@ -317,6 +594,17 @@ type CellValidatedEvent struct {
 	Col  int
 }
 
+// SaveEvent is passed to Saver
+type SaveEvent struct {
+	*Result
+	// Filename is the name entered on the prompt of `w`
+	Filename string
+	// Force is true when the data is saved to the original file
+	// without the prompt by `W`, `ZZ` or `:x`. The file should be
+	// overwritten without the confirmation.
+	Force bool
+}
+
 type KeyEventArgs struct {
 	*_Application
 	CursorRow *RowPtr
@@ -334,6 +622,259 @@ type Config struct {
 	Message         string
 	KeyMap          map[string]func(*KeyEventArgs) (*CommandResult, error)
 	OnCellValidated func(*CellValidatedEvent) (string, error)
+	// OnCellStyle returns the escape sequence like "\x1B[31m" to paint the
+	// cell of the body, or "" for the default colors. The colors of the row
+	// are restored after the cell.
+	OnCellStyle func(*CellStyleEvent) string
+	// OnCellProgress returns the fraction from 0 to 1 of the cell of the
+	// body drawn as a progress bar, and false to draw the text as usual.
+	// Unlike ProgressColumns, the bar can be chosen for each cell and
+	// its value does not have to be the text.
+	OnCellProgress func(*CellStyleEvent) (float64, bool)
+	// OnMessage is called with MessageInfo, MessageWarning or MessageError
+	// and the text of the messages shown on the status line, the problems
+	// found in reading the data, and the messages of Batch.
+	OnMessage func(level, text string)
+	// OnIdle is called about every 100 milliseconds while no keys are
+	// typed, so that the host application can push new rows and so on.
+	// A non-nil CommandResult redraws the screen with its Message or quits.
+	OnIdle func(*KeyEventArgs) (*CommandResult, error)
+	// Session receives the rows appended by other goroutines
+	Session *Session
+	// Record is written the keys, the lines typed and the changes of the
+	// screen size in JSON Lines. NewReplayPilot reproduces the session.
+	Record io.Writer
+	// CaptureFrames is written the frames, which are the texts written to
+	// the terminal before each key or line is read, after the lines like
+	// `--- frame 1 ---`. With AutoPilot, they can be compared with golden
+	// files to test KeyMap and so on.
+	CaptureFrames io.Writer
+	// DebugLog is written the keys, the time to draw the frames, the rows
+	// fetched and the memory statistics in JSON Lines with log/slog
+	// to diagnose the performance.
+	DebugLog io.Writer
+
+	// Filename is the name of the file being edited and is shown as {file}
+	Filename string
+	// StatusFormat is the template of the status line.
+	// The fields {file}, {sep}, {eol}, {enc}, {col}, {offset}, {colname},
+	// {header}, {row}, {rows}, {modified}, {filter}, {warn} and {cell} are
+	// replaced.
+	// When it is empty, DefaultStatusFormat is used.
+	StatusFormat string
+	// SetTitle enables to show the filename and whether it is modified
+	// on the title of the terminal window
+	SetTitle bool
+	// PreviewLines is the number of lines under the status line
+	// to show the whole text of the current cell. Zero disables them.
+	PreviewLines int
+	// Wrap enables to wrap long texts of cells in their widths.
+	// Then a row may occupy two or more screen lines.
+	Wrap bool
+	// TruncateMarker is drawn at the end of a cell whose text is cut
+	// because it is wider than the column
+	TruncateMarker string
+	// ColumnSeparator is drawn at the right end of each column
+	ColumnSeparator string
+	// HeaderRule is repeated to draw a horizontal rule under the header
+	HeaderRule string
+	// ShowBlank enables to draw empty cells as `·` and spaces in cells
+	// containing only white spaces as `␣`. Empty cells are not merged
+	// with the previous one then.
+	ShowBlank bool
+	// StrictGrid enables to draw every column in its own slot with `·`
+	// for empty cells, which are merged with the previous one otherwise.
+	StrictGrid bool
+	// CellScroll enables h and l to scroll the text of the current cell
+	// wider than the column by a character before moving the cursor to
+	// the next column. The number of characters scrolled out is shown as
+	// {offset} on the status line.
+	CellScroll bool
+	// ColumnMinWidth and ColumnMaxWidth are the minimum and the maximum
+	// widths of the columns given by the header names or the 1-based
+	// column numbers. They clamp CellWidth or the width derived by
+	// AutoWidthRows.
+	ColumnMinWidth map[string]int
+	ColumnMaxWidth map[string]int
+	// Summary draws the row summarizing the numeric columns of the rows
+	// loaded under the rows: SummarySpark for the sparklines of the values
+	// in the order of rows, or SummaryStats for `min..max avg`.
+	Summary string
+	// ProgressColumns are the header names or the 1-based numbers of the
+	// columns whose cells of percentages like `42` or `42%` are drawn as
+	// progress bars. The host application can update the cells by SetCell
+	// in OnIdle to show the live status.
+	ProgressColumns []string
+	// NoConfirmQuit and NoConfirmOverwrite disable the confirmations
+	// before quitting and before overwriting an existing file.
+	NoConfirmQuit      bool
+	NoConfirmOverwrite bool
+	// ConfirmDeleteRow enables the confirmation before deleting a row by D
+	ConfirmDeleteRow bool
+	// OnConfirm replaces the question `[y/n]` on the status line of the
+	// confirmations. It returns true to go ahead.
+	OnConfirm func(*ConfirmEvent) bool
+	// AutoWidthRows is the number of rows after the header lines which
+	// decide the widths of the columns with the header lines when the
+	// screen is drawn first: each column is as wide as the widest text in
+	// them (4 to 40). When it is zero, all columns are drawn in CellWidth.
+	AutoWidthRows int
+	// Elastic enables to widen the columns when all of them are narrower
+	// than the screen. ElasticColumn, the header name or the 1-based
+	// column number, takes all the spare width. When it is empty, the
+	// columns share it in proportion to their widths except for the ones
+	// limited by ColumnMaxWidth.
+	Elastic       bool
+	ElasticColumn string
+	// AmbiguousWidth is the width of East Asian Ambiguous characters.
+	// When it is neither 1 nor 2, the width is measured on the terminal.
+	AmbiguousWidth int
+	// NormalizeNFC enables to draw texts normalized in NFC.
+	// The data themselves are not changed.
+	NormalizeNFC bool
+	// EscapeBidi enables to draw bidirectional control characters
+	// as <U+XXXX> so that they do not reorder the rest of the row.
+	EscapeBidi bool
+	// ControlHex enables to draw control characters as <0x07>
+	// instead of the Unicode control pictures
+	ControlHex bool
+	// ControlPictures overrides the texts to draw control characters
+	ControlPictures map[rune]string
+
+	// DetectEncoding enables to guess the encoding when the data is not
+	// UTF-8 and no encodings are specified, and to ask the user to confirm it.
+	DetectEncoding bool
+	// ReadAllOnQuit makes Edit read the rest of the data before it returns,
+	// so that the Result has all rows even when the user quits early.
+	ReadAllOnQuit bool
+	// PickMode makes Enter quit with the row and the cell at the cursor in
+	// Result.Picked and Result.PickedCell. The data can not be edited as
+	// ReadOnly.
+	PickMode bool
+	// MultiPick enables PickMode where Space checks or unchecks the row at
+	// the cursor, and Enter quits with the rows checked in Result.Checked.
+	MultiPick bool
+	// LockHeaderRows keeps the cursor out of the HeaderLines rows, so that
+	// it starts on the first body row and k stops on it.
+	LockHeaderRows bool
+	// StartRow and StartCol are the 1-based position of the cursor at first.
+	// Zero means the first row or column.
+	StartRow int
+	StartCol int
+	// StartColumn is the name on the header or the 1-based number of the
+	// column where the cursor starts. It takes precedence over StartCol.
+	StartColumn string
+	// StartSearch is searched at first and the cursor starts on the cell found
+	StartSearch string
+	// PseudoHeader is drawn as the header when HeaderLines is zero.
+	// PseudoHeaderLetter draws the names of columns like A, B, C ...
+	// and PseudoHeaderFirst pins a copy of the first row.
+	PseudoHeader string
+	// StartCommand is executed as typed after `:` at first
+	StartCommand string
+	// RowTemplate is the default values of the cells of rows added by
+	// `o` and `O`. {today} and {now} in them are replaced with the date
+	// and the time.
+	RowTemplate []string
+	// AutoIncrement is the name on the header or the 1-based number of
+	// the column filled with the maximum integer in it plus one on `o`
+	// and `O`
+	AutoIncrement string
+	// CreatedColumn is filled with the time on `o` and `O`, and
+	// ModifiedColumn is updated with the time whenever a cell of the row
+	// is changed. They are the names on the header or 1-based numbers.
+	CreatedColumn  string
+	ModifiedColumn string
+	// TimestampLayout is the layout of the time in the format of
+	// the time package. When it is empty, DefaultTimestampLayout is used.
+	TimestampLayout string
+	// AuditLog receives every edit as a line of JSON with the time,
+	// the user, the action, the row, the column, the old and new texts.
+	AuditLog io.Writer
+	// GitCommit makes `w`, `W`, `ZZ` and `:x` ask a message and commit
+	// the file saved to the git repository containing it
+	GitCommit bool
+	// NewFile makes Edit ask the delimiter, the names of the columns and
+	// the number of columns to create a new document when in is nil
+	NewFile bool
+	// SetupEditor is called with the line editor of go-readline-ny
+	// before each prompt of the default Pilot starts, so that the key
+	// bindings, the colors and so on can be customized.
+	SetupEditor func(*readline.Editor)
+	// InitialRows is the number of rows read before the screen is drawn
+	// first. When it is zero, DefaultInitialRows is used.
+	InitialRows int
+	// ReadAheadRows is the number of rows read at once while no keys are
+	// typed. When it is zero, DefaultReadAheadRows is used.
+	ReadAheadRows int
+	// ResidentRows is the number of rows kept in memory around the cursor
+	// in the read-only mode. The other rows are evicted and read again from
+	// the reader given to Edit, which has to be io.ReaderAt not yet read
+	// like *os.File. When it is zero, all rows are kept.
+	ResidentRows int
+	// Saver saves the data on `w` instead of FileSaver, the default one.
+	// The data is regarded as saved when it returns nil, and stays
+	// modified when it returns ErrNotSaved or other errors.
+	// Result.WriteFile and Result.Each are available in it.
+	Saver Saver
+	// OnSave is called with the filename entered on the prompt of `w`
+	// instead of writing the file.
+	//
+	// Deprecated: use Saver. OnSave is used as SaveFunc when Saver is nil.
+	OnSave func(*SaveEvent) error
+
+	// Strict records the problems of the data on reading like unterminated
+	// quotes, bare quotes inside fields, NUL bytes and overlong lines.
+	// Their number is shown as {warn} on the status line and `:warnings`
+	// lists them with the cells cut by MaxCellLength of uncsv.Mode, which
+	// are recorded without Strict.
+	Strict bool
+
+	controlReplacer *strings.Replacer
+	encodingGuess   string
+	// frameClock replaces time.Now to coalesce the motion keys in the tests
+	frameClock func() time.Time
+	// pinned is true while the column pinnedCol is drawn at the left end
+	// when it is scrolled out by P or `:pin`
+	pinned    bool
+	pinnedCol int
+	// columnWidths are the widths of the columns different from CellWidth
+	// by AutoWidthRows, ColumnMinWidth and ColumnMaxWidth
+	columnWidths map[int]int
+	// autoWidths are the widths derived by AutoWidthRows
+	autoWidths map[int]int
+	// cellOffset is the number of characters of the current cell scrolled
+	// out to the left with CellScroll
+	cellOffset int
+	// progressCols are the columns of ProgressColumns
+	progressCols map[int]bool
+	// summary keeps the summaries of the columns drawn with Summary
+	summary *summary
+	// outliers is the range of the numbers of the column out of which are
+	// highlighted by `:outliers`
+	outliers *outlierRange
+	// heat is the heatmap of the column painted by `:heatmap`
+	heat *heatmap
+	// columnNames are the names of the columns given to OnCellStyle and
+	// OnCellProgress
+	columnNames []string
+	// checked are the rows checked by Space in MultiPick
+	checked map[*uncsv.Row]bool
+	// blame are the commits drawn in the gutter by `:blame`
+	blame map[*uncsv.Row]*blameCommit
+}
+
+// reservedLines returns the number of screen lines not used by the body
+// except for the status line.
+func (cfg *Config) reservedLines() int {
+	n := cfg.headerHeight() + cfg.PreviewLines
+	if cfg.headerHeight() > 0 && cfg.HeaderRule != "" {
+		n++
+	}
+	if cfg.Summary != "" {
+		n++
+	}
+	return n
 }
 
 func (cfg Config) validate(row *RowPtr, col int, text string) (string, error) {
@@ -341,7 +882,7 @@ func (cfg Config) validate(row *RowPtr, col int, text string) (string, error) {
 		return text, nil
 	}
 	return cfg.OnCellValidated(&CellValidatedEvent{
-		Row:  row.lnum,
+		Row:  row.Index(),
 		Col:  col,
 		Text: text,
 	})
@@ -355,15 +896,61 @@ func (cfg Config) Main(mode *uncsv.Mode, in io.Reader, out io.Writer) (*Result,
 
 func (cfg Config) Edit(in io.Reader, out io.Writer) (*Result, error) {
 	if in == nil {
-		return cfg.edit(nil, out)
+		return cfg.edit(nil, nil, nil, out)
 	}
 	reader, ok := in.(*bufio.Reader)
+	var spill *rowSpill
+	var counter *countingReader
 	if !ok {
+		if spill = cfg.newSpill(in); spill != nil {
+			counter = &countingReader{r: in}
+			in = counter
+		}
 		reader = bufio.NewReader(in)
 	}
-	return cfg.edit(func() (*uncsv.Row, error) {
-		return uncsv.ReadLine(reader, cfg.Mode)
-	}, out)
+	if cfg.DetectEncoding {
+		cfg.guessEncoding(reader)
+	}
+	parse := &parseState{}
+	if spill != nil {
+		doc := NewDocument(cfg.Mode)
+		cfg.Mode = doc.mode
+		doc.csvLines.spill = spill
+		return cfg.edit(doc, func() (*uncsv.Row, error) {
+			spill.next = counter.n - int64(reader.Buffered())
+			row, err := cfg.readLine(reader, parse)
+			if err != nil && (err != io.EOF || isEmptyRow(row)) {
+				spill.next = -1
+			}
+			return row, err
+		}, parse, out)
+	}
+	return cfg.edit(nil, func() (*uncsv.Row, error) {
+		return cfg.readLine(reader, parse)
+	}, parse, out)
+}
+
+// EditRows edits the rows given instead of reading a text. The cells keep
+// their originals, so the cells whose originals are set by
+// uncsv.Cell.SetOriginal are drawn as modified ones.
+func (cfg Config) EditRows(rows []*uncsv.Row, out io.Writer) (*Result, error) {
+	if len(rows) <= 0 {
+		return cfg.edit(nil, nil, nil, out)
+	}
+	return cfg.edit(nil, func() (*uncsv.Row, error) {
+		row := rows[0]
+		if rows = rows[1:]; len(rows) <= 0 {
+			return row, io.EOF
+		}
+		return row, nil
+	}, nil, out)
+}
+
+// EditDocument edits the rows of doc, which are changed directly.
+// The cells are made in the mode of doc instead of Config.Mode.
+func (cfg Config) EditDocument(doc *Document, out io.Writer) (*Result, error) {
+	cfg.Mode = doc.mode
+	return cfg.edit(doc, nil, nil, out)
 }
 
 func isEmptyRow(row *uncsv.Row) bool {
@@ -384,8 +971,20 @@ const (
 	msgColumnFixed   = "The order of Columns is fixed !"
 )
 
+// bodyRow returns the first body row instead of row on the header when
+// LockHeaderRows is set and the body row is loaded.
+func (cfg *Config) bodyRow(row *RowPtr) *RowPtr {
+	if !cfg.LockHeaderRows {
+		return row
+	}
+	for row.Index() < cfg.HeaderLines && row.Next() != nil {
+		row = row.Next()
+	}
+	return row
+}
+
 func (cfg *Config) checkWriteProtect(cursorRow *RowPtr) string {
-	if cfg.ProtectHeader && cursorRow.lnum < cfg.HeaderLines {
+	if cfg.ProtectHeader && cursorRow.Index() < cfg.HeaderLines {
 		return msgProtectHeader
 	}
 	if cfg.ReadOnly {
@@ -405,7 +1004,7 @@ func (cfg *Config) checkWriteProtectAndColumn(cursorRow *RowPtr) string {
 }
 
 func (app *_Application) readlineAndValidate(prompt, text string, row *RowPtr, col int) (string, error) {
-	candidates := makeCandidate(row.lnum-1, col, row)
+	candidates := makeCandidate(row.Index()-1, col, row)
 	for {
 		var err error
 		text, err = app.Config.Pilot.ReadLine(app.out, prompt, text, candidates)
@@ -420,14 +1019,26 @@ func (app *_Application) readlineAndValidate(prompt, text string, row *RowPtr, c
 	}
 }
 
-func (cfg *Config) edit(fetch func() (*uncsv.Row, error), out io.Writer) (*Result, error) {
+// edit is the body of Edit and its variants. parse records the problems
+// found by fetch and may be nil when fetch does not record them.
+func (cfg *Config) edit(doc *Document, fetch func() (*uncsv.Row, error), parse *parseState, out io.Writer) (result *Result, err error) {
+	// registered first to run after the terminal mode is restored
+	defer recoverTerminal(out, &err)
+
 	if cfg.KeyMap == nil {
 		cfg.KeyMap = make(map[string]func(*KeyEventArgs) (*CommandResult, error))
 	}
+	if cfg.MultiPick {
+		cfg.PickMode = true
+	}
+	if cfg.PickMode {
+		cfg.ReadOnly = true
+	}
 
 	mode := cfg.Mode
 	if mode == nil {
 		mode = &uncsv.Mode{}
+		cfg.Mode = mode
 	}
 
 	cellWidth := cfg.CellWidth
@@ -435,29 +1046,70 @@ func (cfg *Config) edit(fetch func() (*uncsv.Row, error), out io.Writer) (*Resul
 		cellWidth = 14
 	}
 
+	regs := registers{}
+	recent := &recentFiles{}
 	pilot := cfg.Pilot
 	if pilot == nil {
 		var err error
-		pilot, err = newManualCtl()
+		pilot, err = newManualCtl(cfg.SetupEditor, regs, recent)
 		if err != nil {
 			return nil, err
 		}
 		defer pilot.Close()
 		cfg.Pilot = pilot
 	}
-	if _, ok := out.(*os.File); ok {
-		if err := pilot.Calibrate(); err != nil {
-			return nil, err
+	if cfg.Record != nil {
+		pilot = newRecorder(pilot, cfg.Record)
+		cfg.Pilot = pilot
+	}
+	switch cfg.AmbiguousWidth {
+	case 1:
+		runewidth.DefaultCondition.EastAsianWidth = false
+	case 2:
+		runewidth.DefaultCondition.EastAsianWidth = true
+	default:
+		if _, ok := out.(*os.File); ok {
+			if err := pilot.Calibrate(); err != nil {
+				return nil, err
+			}
 		}
 	}
+	if cfg.CaptureFrames != nil {
+		capture := &frameCapturer{Pilot: pilot, w: cfg.CaptureFrames}
+		out = io.MultiWriter(out, &capture.frame)
+		pilot = capture
+		cfg.Pilot = pilot
+	}
+	if doc == nil {
+		doc = NewDocument(mode)
+	}
 	app := &_Application{
-		Config:   cfg,
-		csvLines: list.New(),
-		out:      out,
-		Pilot:    pilot,
+		Config:      cfg,
+		Document:    doc,
+		out:         out,
+		Pilot:       pilot,
+		registers:   regs,
+		recentFiles: recent,
+		parseState:  parse,
+	}
+	if app.parseState == nil {
+		app.parseState = &parseState{}
+	}
+	if cfg.encodingGuess != "" {
+		if m := app.confirmEncoding(cfg.encodingGuess); m != "" {
+			cfg.Message = m
+		}
+	}
+	initialRows := cfg.InitialRows
+	if initialRows <= 0 {
+		initialRows = DefaultInitialRows
+	}
+	readAhead := cfg.ReadAheadRows
+	if readAhead <= 0 {
+		readAhead = DefaultReadAheadRows
 	}
 	if fetch != nil {
-		for i := 0; i < 100; i++ {
+		for i := 0; i < initialRows; i++ {
 			row, err := fetch()
 			if err != nil {
 				if err != io.EOF {
@@ -473,17 +1125,21 @@ func (cfg *Config) edit(fetch func() (*uncsv.Row, error), out io.Writer) (*Resul
 				break
 			}
 		}
-	} else {
+	} else if app.Len() > 0 {
+		// the document given by EditDocument
+	} else if !cfg.NewFile || !app.newFileWizard() {
 		newRow := uncsv.NewRow(mode)
 		app.Push(&newRow)
 	}
+	if cfg.AutoWidthRows > 0 {
+		app.guessColumnWidths()
+	}
 	cursorCol := 0
 	cursorRow := app.Front()
 	startRow := app.Front()
 	startCol := 0
 
-	lastSearch := searchForward
-	lastSearchRev := searchBackward
+	lastForward := true
 	lastWord := ""
 	var lastWidth, lastHeight int
 
@@ -493,66 +1149,402 @@ func (cfg *Config) edit(fetch func() (*uncsv.Row, error), out io.Writer) (*Resul
 	defer keyWorker.Close()
 
 	view := newView()
+	view.marks = &cellMarks{valid: app.isValidCell, changed: app.gitChanged}
+	defer app.restoreTitle()
+	dbg := newDebugLog(cfg.DebugLog)
+	if fetch != nil {
+		fetch = dbg.watchFetch(fetch, app.Len)
+	}
+	var debugOverlay bool
+
+	fetchWhile := func(more func() bool) error {
+		if fetch == nil {
+			return nil
+		}
+		io.WriteString(out, _ANSI_YELLOW+"\rWait a moment for reading data..."+_ANSI_ERASE_LINE)
+		for more() {
+			row, err := fetch()
+			if err != nil && err != io.EOF {
+				return err
+			}
+			if err == io.EOF {
+				fetch = nil
+				if !isEmptyRow(row) {
+					app.Push(row)
+				}
+				return nil
+			}
+			app.Push(row)
+		}
+		return nil
+	}
+	fetchAll := func() error {
+		return fetchWhile(func() bool { return true })
+	}
+	var pendingKeys []string
+	var index searchIndex
+	var searchHistory Candidate
+	var pendingErr error
+
+	// search finds word. When it searches forward and word is not
+	// found in the rows loaded, it reads the rest until a row contains word.
+	// Reading is cancelled by Ctrl-C or ESC. Other keys typed meanwhile
+	// are processed after the search.
+	search := func(forward bool, row *RowPtr, col int, word string) (*RowPtr, int, error) {
+		notFound := fmt.Errorf("%s: not found", word)
+		if index.valid(app, word) {
+			hit, ok := index.backward(row.Index(), col)
+			if forward {
+				hit, ok = index.forward(row.Index(), col)
+			}
+			if ok {
+				if r, err := app.rowAt(hit.lnum + 1); err == nil {
+					return r, hit.col, nil
+				}
+			}
+		} else {
+			index.reset(app, word)
+		}
+		f := searchBackward
+		if forward {
+			f = searchForward
+		}
+		if r, c := f(row, col, word); r != nil {
+			return r, c, nil
+		}
+		if fetch == nil || !forward {
+			return nil, 0, notFound
+		}
+		var found *RowPtr
+		var foundCol int
+		var fetchErr error
+		progress := time.Now().Add(time.Second / 4)
+		work := func() bool {
+			if fetch == nil {
+				return false
+			}
+			row, err := fetch()
+			if err != nil {
+				fetch = nil
+				if err != io.EOF {
+					fetchErr = err
+					return false
+				}
+				if isEmptyRow(row) {
+					return false
+				}
+			}
+			app.Push(row)
+			if r, c := searchForward(app.Back(), -1, word); r != nil {
+				found, foundCol = r, c
+				return false
+			}
+			if time.Now().After(progress) {
+				fmt.Fprintf(out, "\r%s%s: searching in %d rows... (Ctrl-C to cancel)%s",
+					_ANSI_YELLOW, word, app.Len(), _ANSI_ERASE_LINE)
+				progress = time.Now().Add(time.Second / 4)
+			}
+			return true
+		}
+		for {
+			key, typed, err := keyWorker.Work(work)
+			if err != nil {
+				// The error is reported on reading the next key
+				pendingErr = err
+				for work() {
+				}
+				break
+			}
+			if !typed {
+				break
+			}
+			if key == keys.CtrlC || key == keys.Escape {
+				return nil, 0, fmt.Errorf("%s: search cancelled", word)
+			}
+			pendingKeys = append(pendingKeys, key)
+		}
+		if fetchErr != nil {
+			return nil, 0, fetchErr
+		}
+		if found == nil {
+			return nil, 0, notFound
+		}
+		return found, foundCol, nil
+	}
+
+	// streamToEnd reads the rest of the data showing the number of rows
+	// read. It returns false when it is cancelled by Ctrl-C or ESC.
+	// Other keys typed meanwhile are processed after it.
+	streamToEnd := func() (bool, error) {
+		var fetchErr error
+		progress := time.Now().Add(time.Second / 4)
+		work := func() bool {
+			if fetch == nil {
+				return false
+			}
+			row, err := fetch()
+			if err != nil {
+				fetch = nil
+				if err != io.EOF {
+					fetchErr = err
+					return false
+				}
+				if isEmptyRow(row) {
+					return false
+				}
+			}
+			app.Push(row)
+			if time.Now().After(progress) {
+				fmt.Fprintf(out, "\r%sreading %d rows... (Ctrl-C to cancel)%s",
+					_ANSI_YELLOW, app.Len(), _ANSI_ERASE_LINE)
+				progress = time.Now().Add(time.Second / 4)
+			}
+			return true
+		}
+		for fetch != nil {
+			key, typed, err := keyWorker.Work(work)
+			if err != nil {
+				pendingErr = err
+				for work() {
+				}
+				break
+			}
+			if !typed {
+				break
+			}
+			if key == keys.CtrlC || key == keys.Escape {
+				return false, fetchErr
+			}
+			pendingKeys = append(pendingKeys, key)
+		}
+		return true, fetchErr
+	}
+
+	readAllOnQuit := func() error {
+		if !cfg.ReadAllOnQuit {
+			return nil
+		}
+		return fetchAll()
+	}
+
+	if cfg.StartRow > 0 {
+		if err := fetchWhile(func() bool { return app.Len() < cfg.StartRow }); err != nil {
+			return nil, err
+		}
+		for cursorRow.Index() < cfg.StartRow-1 && cursorRow.Next() != nil {
+			cursorRow = cursorRow.Next()
+		}
+	}
+	if cfg.StartCol > 0 {
+		cursorCol = cfg.StartCol - 1
+	}
+	if cfg.StartColumn != "" {
+		if col, err := app.columnIndex(cfg.StartColumn); err != nil {
+			cfg.Message = fmt.Sprintf("%s: %s", cfg.StartColumn, err.Error())
+		} else {
+			cursorCol = col
+		}
+	}
+	if cfg.StartSearch != "" {
+		if r, _ := searchForward(app.Front(), -1, cfg.StartSearch); r == nil {
+			if err := fetchWhile(func() bool {
+				r, _ := searchForward(app.Back(), -1, cfg.StartSearch)
+				return r == nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+		lastWord = cfg.StartSearch
+		app.registers[registerSearch] = lastWord
+		if r, c := searchForward(app.Front(), -1, lastWord); r != nil {
+			cursorRow = r
+			cursorCol = c
+		} else {
+			cfg.Message = fmt.Sprintf("%s: not found", lastWord)
+		}
+	}
+	if cfg.LockHeaderRows {
+		if app.Len() <= cfg.HeaderLines {
+			if err := fetchWhile(func() bool { return app.Len() <= cfg.HeaderLines }); err != nil {
+				return nil, err
+			}
+		}
+		cursorRow = cfg.bodyRow(cursorRow)
+	}
+	if L := len(cursorRow.Cell); cursorCol >= L {
+		cursorCol = max(L-1, 0)
+	}
+	if cursorRow.Index() > 0 {
+		startRow = cursorRow.Clone()
+		startCol = cursorCol
+	}
+
+	quitResult := func() *Result {
+		return &Result{
+			_Application: app,
+			Row:          cursorRow.Index() + 1,
+			Col:          cursorCol + 1,
+			Search:       lastWord,
+			Saved:        app.saved,
+		}
+	}
 
 	message := cfg.Message
+	var idleResult *CommandResult
+	var idleErr error
+	nextIdleTime := time.Now()
+	// polling keeps calling the work between keys after reading all rows
+	polling := cfg.OnIdle != nil || cfg.Session != nil
+	level := MessageInfo
 	var killbuffer string
+	var lastEdit *editRecord
+	startCommand := cfg.StartCommand
+	// The frames are not drawn while the motion keys are typed faster
+	// than frameInterval, but once after the burst of them.
+	var lfCount int
+	var motion bool
+	var nextFrame time.Time
+	offsetLnum, offsetCol := cursorRow.Index(), cursorCol
 	for {
 		screenWidth, screenHeight, err := pilot.Size()
 		if err != nil {
 			return nil, err
 		}
-		screenHeight -= cfg.HeaderLines
+		screenHeight -= cfg.reservedLines()
+		screenWidth -= cfg.gutterWidth()
 		if lastWidth != screenWidth || lastHeight != screenHeight {
 			view.clearCache()
 			lastWidth = screenWidth
 			lastHeight = screenHeight
 			io.WriteString(out, _ANSI_CURSOR_OFF)
 		}
-		cols := (screenWidth - 1) / cellWidth
+		app.updateColumnWidths(cellWidth, screenWidth)
+		app.updateProgressColumns()
+		app.updateColumnNames()
+		app.updateHeatmap()
+		app.updateOutliers()
+		app.updateSummary()
+		app.evictRows(cursorRow)
+		app.updateTitle()
 
-		lfCount := view.Draw(app.Front(), startRow, cursorRow, cellWidth, cfg.HeaderLines, startCol, cursorCol, screenHeight, screenWidth, out)
+		if motion && message == "" && len(pendingKeys) == 0 && pendingErr == nil && cfg.frameNow().Before(nextFrame) {
+			if key, typed, err := keyWorker.Wait(nextFrame.Sub(cfg.frameNow())); typed {
+				if err != nil {
+					pendingErr = err
+				} else {
+					pendingKeys = append(pendingKeys, key)
+				}
+			}
+		}
+		skipFrame := motion && message == "" && len(pendingKeys) > 0 &&
+			cfg.isMotionKey(pendingKeys[0]) && cfg.frameNow().Before(nextFrame)
+		if !skipFrame {
+			drawStart := time.Now()
+			lfCount = view.Draw(cfg, app.Front(), startRow, cursorRow, cellWidth, startCol, cursorCol, screenHeight, screenWidth, out)
+			dbg.draw(time.Since(drawStart), app.Len())
+			io.WriteString(out, _ANSI_YELLOW)
+			if message != "" {
+				cfg.notify(level, message)
+				io.WriteString(out, runewidth.Truncate(message, screenWidth-1, ""))
+			} else if 0 <= cursorRow.Index() && cursorRow.Index() < app.Len() {
+				app.printStatusLine(out, cursorRow, cursorCol, screenWidth)
+			}
+			if debugOverlay {
+				text := runewidth.Truncate(dbg.overlay(app.Len(), fetch != nil), screenWidth-1, "")
+				io.WriteString(out, _ANSI_ERASE_LINE)
+				fmt.Fprintf(out, "\x1B[%dG\x1B[7m%s", screenWidth-runewidth.StringWidth(text), text)
+			}
+			io.WriteString(out, _ANSI_RESET)
+			io.WriteString(out, _ANSI_ERASE_SCRN_AFTER)
+			app.printPreview(cursorRow, cursorCol, screenWidth)
+			nextFrame = cfg.frameNow().Add(frameInterval)
+		}
 		repaint := func() {
 			up(lfCount, out)
-			lfCount = view.Draw(app.Front(), startRow, cursorRow, cellWidth, cfg.HeaderLines, startCol, cursorCol, screenHeight, screenWidth, out)
-		}
-
-		io.WriteString(out, _ANSI_YELLOW)
-		if message != "" {
-			io.WriteString(out, runewidth.Truncate(message, screenWidth-1, ""))
-		} else if 0 <= cursorRow.lnum && cursorRow.lnum < app.Len() {
-			printStatusLine(out, mode, cursorRow, cursorCol, screenWidth)
+			lfCount = view.Draw(cfg, app.Front(), startRow, cursorRow, cellWidth, startCol, cursorCol, screenHeight, screenWidth, out)
 		}
-		io.WriteString(out, _ANSI_RESET)
-		io.WriteString(out, _ANSI_ERASE_SCRN_AFTER)
 
 		const interval = 4
 		displayUpdateTime := time.Now().Add(time.Second / interval)
 
-		ch, err := keyWorker.GetOr(func() bool {
-			if fetch == nil {
-				return false
-			}
-			row, err := fetch()
-			if err != nil {
-				fetch = nil
-				if err != io.EOF || isEmptyRow(row) {
+		ch := ":"
+		if startCommand != "" {
+			// execute the command given at first
+		} else if len(pendingKeys) > 0 {
+			ch, pendingKeys = pendingKeys[0], pendingKeys[1:]
+		} else if pendingErr != nil {
+			err = pendingErr
+		} else {
+			work := func() bool {
+				indexing := index.step(app)
+				if cfg.OnIdle != nil && time.Now().After(nextIdleTime) {
+					idleResult, idleErr = cfg.OnIdle(&KeyEventArgs{
+						CursorRow:    cursorRow,
+						CursorCol:    cursorCol,
+						_Application: app,
+					})
+					nextIdleTime = time.Now().Add(idleInterval)
+					if idleResult != nil || idleErr != nil {
+						return false
+					}
+				}
+				if fetch == nil && cfg.Session != nil && cfg.Session.flush(app) > 0 {
+					// redraw with the rows appended
+					idleResult = &CommandResult{}
 					return false
 				}
+				if fetch == nil {
+					if !indexing && polling {
+						// keep polling OnIdle without spinning
+						time.Sleep(idleInterval / 10)
+						return true
+					}
+					return indexing
+				}
+				var err error
+				for i := 0; i < readAhead && err == nil; i++ {
+					var row *uncsv.Row
+					row, err = fetch()
+					if err != nil {
+						fetch = nil
+						if err != io.EOF || isEmptyRow(row) {
+							return false
+						}
+					}
+					app.Push(row)
+				}
+				if message == "" && (err == io.EOF || time.Now().After(displayUpdateTime)) {
+					io.WriteString(out, "\r"+_ANSI_YELLOW)
+					app.printStatusLine(out, cursorRow, cursorCol, screenWidth)
+					io.WriteString(out, _ANSI_ERASE_LINE)
+					displayUpdateTime = time.Now().Add(time.Second / interval)
+				}
+				return err != io.EOF || polling
 			}
-			app.Push(row)
-			if message == "" && (err == io.EOF || time.Now().After(displayUpdateTime)) {
-				io.WriteString(out, "\r"+_ANSI_YELLOW)
-				printStatusLine(out, mode, cursorRow, cursorCol, screenWidth)
-				io.WriteString(out, _ANSI_RESET)
-				io.WriteString(out, _ANSI_ERASE_SCRN_AFTER)
-				displayUpdateTime = time.Now().Add(time.Second / interval)
-			}
-			return err != io.EOF
-		})
+			if !polling {
+				ch, err = keyWorker.GetOr(work)
+			} else {
+				ch, _, err = keyWorker.Work(work)
+			}
+		}
+		if err == nil {
+			err = idleErr
+		}
 		if err != nil {
 			return nil, err
 		}
-		message = ""
+		ch = normalizeKey(ch)
+		dbg.key(ch)
+		motion = cfg.isMotionKey(ch)
+		message, level = "", MessageInfo
+		if idleResult != nil {
+			// no keys are typed: only redraw with the result of OnIdle or the rows appended
+			if idleResult.Quit {
+				return quitResult(), readAllOnQuit()
+			}
+			message, idleResult = idleResult.Message, nil
+			ch = ""
+		}
 
 		if handler, ok := cfg.KeyMap[ch]; ok {
 			e := &KeyEventArgs{
@@ -561,20 +1553,52 @@ func (cfg *Config) edit(fetch func() (*uncsv.Row, error), out io.Writer) (*Resul
 				_Application: app,
 			}
 			cmdResult, err := handler(e)
-			if err != nil || cmdResult.Quit {
-				return &Result{_Application: app}, err
+			if err != nil {
+				return quitResult(), err
+			}
+			if cmdResult.Quit {
+				return quitResult(), readAllOnQuit()
 			}
 			message = cmdResult.Message
 		} else {
 			switch ch {
+			case keys.F12:
+				debugOverlay = !debugOverlay
+			case "P":
+				message = cfg.togglePin(cursorCol)
 			case keys.CtrlL:
 				view.clearCache()
 			case "q", keys.Escape:
-				if cfg.ReadOnly || app.YesNo("Quit Sure ? [y/n]") {
+				if cfg.ReadOnly || cfg.NoConfirmQuit || app.confirm(ConfirmQuit, "Quit Sure ? [y/n]") {
+					io.WriteString(out, "\n")
+					return quitResult(), readAllOnQuit()
+				}
+			case "Z":
+				if ch2, err := app.GetKey(); err != nil || ch2 != "Z" {
+					break
+				}
+				if quit, err := saveIfDirty(app, fetchAll); err != nil {
+					message, level = err.Error(), MessageError
+				} else if quit {
 					io.WriteString(out, "\n")
-					return &Result{_Application: app}, nil
+					return quitResult(), readAllOnQuit()
 				}
+				view.clearCache()
 			case "j", keys.Down, keys.CtrlN, keys.Enter:
+				if ch == keys.Enter && cfg.PickMode {
+					if cursorRow.Index() < cfg.HeaderLines {
+						message = "the header can not be picked"
+						break
+					}
+					io.WriteString(out, "\n")
+					r := quitResult()
+					r.Picked = cursorRow.Row
+					r.Checked = app.checkedRows()
+					if cursorCol < len(cursorRow.Cell) {
+						r.PickedCell = cursorRow.Cell[cursorCol].Text()
+					}
+					return r, readAllOnQuit()
+				}
 				if next := cursorRow.Next(); next != nil {
 					cursorRow = next
 				}
@@ -583,29 +1607,148 @@ func (cfg *Config) edit(fetch func() (*uncsv.Row, error), out io.Writer) (*Resul
 					cursorRow = prev
 				}
 			case "h", keys.Left, keys.CtrlB, keys.ShiftTab:
-				if cursorCol > 0 {
+				if ch != keys.ShiftTab && cfg.cellOffset > 0 {
+					cfg.cellOffset--
+				} else if cursorCol > 0 {
 					cursorCol--
 				}
 			case "l", keys.Right, keys.CtrlF, keys.CtrlI:
-				cursorCol++
-			case "0", "^", keys.CtrlA:
+				if ch != keys.CtrlI && cfg.canScrollCell(cursorRow, startCol, cursorCol, cellWidth, screenWidth) {
+					cfg.cellOffset++
+				} else {
+					cursorCol++
+				}
+			case "0", "^", keys.CtrlA, keys.Home:
 				cursorCol = 0
-			case "$", keys.CtrlE:
+			case "$", keys.CtrlE, keys.End:
 				cursorCol = len(cursorRow.Cell) - 1
-			case "<":
+			case " ", keys.PageDown:
+				if ch == " " && cfg.MultiPick {
+					if cursorRow.Index() >= cfg.HeaderLines {
+						cfg.toggleCheck(cursorRow.Row)
+					}
+					if next := cursorRow.Next(); next != nil {
+						cursorRow = next
+					}
+					break
+				}
+				for i := 1; i < screenHeight-1; i++ {
+					next := cursorRow.Next()
+					if next == nil {
+						break
+					}
+					cursorRow = next
+					if s := startRow.Next(); s != nil {
+						startRow = s
+					}
+				}
+			case "b", keys.PageUp:
+				for i := 1; i < screenHeight-1; i++ {
+					prev := cursorRow.Prev()
+					if prev == nil {
+						break
+					}
+					cursorRow = prev
+					if s := startRow.Prev(); s != nil {
+						startRow = s
+					}
+				}
+			case "}":
+				if next := nextValueChange(cursorRow, cursorCol); next != nil {
+					cursorRow = next
+				} else {
+					message = "no different values below"
+				}
+			case "{":
+				if prev := prevValueChange(cursorRow, cursorCol); prev != nil {
+					cursorRow = prev
+				}
+			case "]":
+				if r, c := app.nextModified(cursorRow, cursorCol); r != nil {
+					cursorRow, cursorCol = r, c
+				} else {
+					message = "no modified cells below"
+				}
+			case "[":
+				if r, c := app.prevModified(cursorRow, cursorCol); r != nil {
+					cursorRow, cursorCol = r, c
+				} else {
+					message = "no modified cells above"
+				}
+			case "*":
+				next := cfg.nextOutlier(cursorRow, cursorCol)
+				if next == nil && fetch != nil && cfg.outliers != nil && cfg.outliers.col == cursorCol {
+					// read the rest until an outlier by the range so far
+					loaded := app.Len()
+					if err := fetchWhile(func() bool {
+						last := app.Back()
+						return app.Len() <= loaded || cursorCol >= len(last.Cell) ||
+							!cfg.isOutlier(cursorCol, last.Cell[cursorCol].Text())
+					}); err != nil {
+						return nil, err
+					}
+					app.updateOutliers()
+					next = cfg.nextOutlier(cursorRow, cursorCol)
+				}
+				if next != nil {
+					cursorRow = next
+				} else if cfg.outliers == nil || cfg.outliers.col != cursorCol {
+					message = "no outliers checked in the current column (:outliers)"
+				} else {
+					message = "no outliers below"
+				}
+			case "#":
+				if prev := cfg.prevOutlier(cursorRow, cursorCol); prev != nil {
+					cursorRow = prev
+				} else if cfg.outliers == nil || cfg.outliers.col != cursorCol {
+					message = "no outliers checked in the current column (:outliers)"
+				} else {
+					message = "no outliers above"
+				}
+			case "e":
+				if next := nextEmptyInColumn(cursorRow, cursorCol); next != nil {
+					cursorRow = next
+				} else {
+					message = "no empty cells below"
+				}
+			case "E":
+				if prev := prevEmptyInColumn(cursorRow, cursorCol); prev != nil {
+					cursorRow = prev
+				} else {
+					message = "no empty cells above"
+				}
+			case ")":
+				if c := emptyInRow(cursorRow, cursorCol, 1); c >= 0 {
+					cursorCol = c
+				} else {
+					message = "no empty cells on the right"
+				}
+			case "(":
+				if c := emptyInRow(cursorRow, cursorCol, -1); c >= 0 {
+					cursorCol = c
+				} else {
+					message = "no empty cells on the left"
+				}
+			case "<", "g", keyCtrlHome:
 				cursorRow = app.Front()
 				startRow = app.Front()
 				cursorCol = 0
 				startCol = 0
-			case ">", "G":
+			case ">", "G", keyCtrlEnd:
+				completed, err := streamToEnd()
+				if err != nil {
+					message, level = err.Error(), MessageError
+				} else if !completed {
+					message = fmt.Sprintf("reading cancelled at %d rows", app.Len())
+				}
 				cursorRow = app.Back()
 			case "n":
 				if lastWord == "" {
 					break
 				}
-				r, c := lastSearch(cursorRow, cursorCol, lastWord)
-				if r == nil {
-					message = fmt.Sprintf("%s: not found", lastWord)
+				r, c, err := search(lastForward, cursorRow, cursorCol, lastWord)
+				if err != nil {
+					message, level = err.Error(), MessageError
 					break
 				}
 				cursorRow = r
@@ -614,83 +1757,94 @@ func (cfg *Config) edit(fetch func() (*uncsv.Row, error), out io.Writer) (*Resul
 				if lastWord == "" {
 					break
 				}
-				r, c := lastSearchRev(cursorRow, cursorCol, lastWord)
-				if r == nil {
-					message = fmt.Sprintf("%s: not found", lastWord)
+				r, c, err := search(!lastForward, cursorRow, cursorCol, lastWord)
+				if err != nil {
+					message, level = err.Error(), MessageError
 					break
 				}
 				cursorRow = r
 				cursorCol = c
 			case "/", "?":
-				var err error
 				view.clearCache()
-				lastWord, err = pilot.ReadLine(out, ch, "", nil)
+				word, err := pilot.ReadLine(out, ch, "", searchHistory)
 				if err != nil {
 					if err != readline.CtrlC {
-						message = err.Error()
+						message, level = err.Error(), MessageError
 					}
 					break
 				}
-				if ch == "/" {
-					lastSearch = searchForward
-					lastSearchRev = searchBackward
-				} else {
-					lastSearch = searchBackward
-					lastSearchRev = searchForward
-				}
-				r, c := lastSearch(cursorRow, cursorCol, lastWord)
-				if r == nil {
-					message = fmt.Sprintf("%s: not found", lastWord)
-					break
+				// An empty pattern means the last one
+				if word != "" {
+					lastWord = word
+					searchHistory = append(slices.DeleteFunc(searchHistory, func(s string) bool {
+						return s == word
+					}), word)
+					app.registers[registerSearch] = word
 				}
-				cursorRow = r
-				cursorCol = c
-			case "o":
-				if cfg.ProtectHeader && cursorRow.lnum+1 < cfg.HeaderLines {
-					message = msgProtectHeader
+				if lastWord == "" {
 					break
 				}
-				if cfg.ReadOnly {
-					message = msgReadOnly
+				lastForward = ch == "/"
+				r, c, err := search(lastForward, cursorRow, cursorCol, lastWord)
+				if err != nil {
+					message, level = err.Error(), MessageError
 					break
 				}
-				newRow := uncsv.NewRow(mode)
-				newRow.Term = cursorRow.Term
-				if cursorRow.Term == "" {
-					cursorRow.Term = mode.DefaultTerm
-				}
-				if cfg.FixColumn {
-					for len(newRow.Cell) < len(cursorRow.Cell) {
-						newRow.Insert(0, "", mode)
+				cursorRow = r
+				cursorCol = c
+			case ":":
+				view.clearCache()
+				line := startCommand
+				startCommand = ""
+				if line == "" {
+					var err error
+					line, err = pilot.ReadLine(out, ":", "", nil)
+					if err != nil {
+						if err != readline.CtrlC {
+							message, level = err.Error(), MessageError
+						}
+						break
 					}
 				}
-				cursorRow = cursorRow.InsertAfter(&newRow)
-				repaint()
-				view.clearCache()
-				newCol := cursorCol
-				if cursorCol >= len(cursorRow.Cell) {
-					newCol = len(cursorRow.Cell) - 1
+				e := &exCommandArgs{
+					KeyEventArgs: &KeyEventArgs{
+						CursorRow:    cursorRow,
+						CursorCol:    cursorCol,
+						_Application: app,
+					},
+					view:         view,
+					fetchAll:     fetchAll,
+					loading:      func() bool { return fetch != nil },
+					lfCount:      lfCount,
+					screenWidth:  screenWidth,
+					screenHeight: screenHeight + cfg.reservedLines(),
 				}
-				if text, err := app.readlineAndValidate("new line>", "", cursorRow, newCol); err == nil {
-					cursorRow.Replace(newCol, text, mode)
+				message, err = e.run(line)
+				if err != nil {
+					message, level = err.Error(), MessageError
+				} else if e.quit {
+					io.WriteString(out, "\n")
+					return quitResult(), readAllOnQuit()
 				}
-			case "O":
-				if m := cfg.checkWriteProtect(cursorRow); m != "" {
-					message = m
+				cursorRow = e.CursorRow
+				cursorCol = e.CursorCol
+			case "o", "O":
+				if m := app.editProtected(ch, cursorRow); m != "" {
+					message, level = m, MessageWarning
 					break
 				}
 				startPrevP := startRow.Prev()
-				newRow := uncsv.NewRow(mode)
-				if cfg.FixColumn {
-					for len(newRow.Cell) < len(cursorRow.Cell) {
-						newRow.Insert(0, "", mode)
-					}
+				newRow, err := app.newRowFor(cursorRow, nil, fetchAll)
+				if err != nil {
+					return nil, err
 				}
-				cursorRow = cursorRow.InsertBefore(&newRow)
-				if startPrevP != nil {
-					startRow = startPrevP.Next()
-				} else {
-					startRow = app.Front()
+				cursorRow = app.insertRow(cursorRow, newRow, ch == "o")
+				if ch == "O" {
+					if startPrevP != nil {
+						startRow = startPrevP.Next()
+					} else {
+						startRow = app.Front()
+					}
 				}
 				repaint()
 				view.clearCache()
@@ -698,153 +1852,186 @@ func (cfg *Config) edit(fetch func() (*uncsv.Row, error), out io.Writer) (*Resul
 				if cursorCol >= len(cursorRow.Cell) {
 					newCol = len(cursorRow.Cell) - 1
 				}
-				if text, err := app.readlineAndValidate("new line>", "", cursorRow, newCol); err == nil {
+				if text, err := app.readlineAndValidate("new line>", cursorRow.Cell[newCol].Text(), cursorRow, newCol); err == nil {
 					cursorRow.Replace(newCol, text, mode)
 				}
+				app.auditRow("insert-row", cursorRow, cursorRow.Row)
+				lastEdit = &editRecord{key: ch, cells: cellTexts(cursorRow.Row)}
 			case "D":
-				if m := cfg.checkWriteProtect(cursorRow); m != "" {
-					message = m
-					break
-				}
-				if app.Len() <= 1 {
+				if m := app.editProtected(ch, cursorRow); m != "" {
+					message, level = m, MessageWarning
 					break
 				}
 				startPrevP := startRow.Prev()
-				prevP := cursorRow.Prev()
-				removedRow := cursorRow.Remove()
-				app.removedRows = append(app.removedRows, removedRow)
-				if prevP == nil {
-					cursorRow = app.Front()
-				} else if next := prevP.Next(); next != nil {
-					cursorRow = next
-				} else {
-					cursorRow = prevP
-					cursorRow.Term = removedRow.Term
+				next, deleted := app.deleteRow(cursorRow)
+				if !deleted {
+					break
 				}
+				cursorRow = next
+				lastEdit = &editRecord{key: "D"}
 				if startPrevP == nil {
 					startRow = app.Front()
 				} else {
 					startRow = startPrevP.Next()
 				}
 			case "i":
-				if m := cfg.checkWriteProtectAndColumn(cursorRow); m != "" {
-					message = m
+				if m := app.editProtected(ch, cursorRow); m != "" {
+					message, level = m, MessageWarning
 					break
 				}
 				view.clearCache()
-				if text, err := app.readlineAndValidate("insert cell>", "", cursorRow, cursorCol); err == nil {
-					if cells := cursorRow.Cell; len(cells) == 1 && cells[0].Text() == "" {
-						cursorRow.Replace(cursorCol, text, mode)
-					} else {
-						cursorRow.Insert(cursorCol, text, mode)
-						cursorCol++
-					}
+				if text, err := app.readlineAndValidate(app.cellPrompt("insert", cursorCol), "", cursorRow, cursorCol); err == nil {
+					cursorCol = app.insertCell(cursorRow, cursorCol, text, false)
+					lastEdit = &editRecord{key: "i", text: text}
 				}
 			case "a":
-				if m := cfg.checkWriteProtectAndColumn(cursorRow); m != "" {
-					message = m
+				if m := app.editProtected(ch, cursorRow); m != "" {
+					message, level = m, MessageWarning
 					break
 				}
-				if cells := cursorRow.Cell; len(cells) == 1 && cells[0].Text() == "" {
-					// current column is the last one and it is empty
-					view.clearCache()
-					if text, err := app.readlineAndValidate("append cell>", "", cursorRow, cursorCol+1); err == nil {
-						cursorRow.Replace(cursorCol, text, mode)
-					}
-				} else {
+				empty := len(cursorRow.Cell) == 1 && cursorRow.Cell[0].Text() == ""
+				if !empty {
+					// the empty cell drawn while the text is typed
 					cursorCol++
 					cursorRow.Insert(cursorCol, "", mode)
 					repaint()
-					view.clearCache()
-					if text, err := app.readlineAndValidate("append cell>", "", cursorRow, cursorCol+1); err != nil {
-						// cancel
-						cursorRow.Delete(cursorCol)
-						cursorCol--
-					} else {
-						cursorRow.Replace(cursorCol, text, mode)
-					}
+				}
+				view.clearCache()
+				text, err := app.readlineAndValidate(app.cellPrompt("append", cursorCol+1), "", cursorRow, cursorCol+1)
+				if !empty {
+					cursorRow.Delete(cursorCol)
+					cursorCol--
+				}
+				if err == nil {
+					cursorCol = app.insertCell(cursorRow, cursorCol, text, true)
+					lastEdit = &editRecord{key: "a", text: text}
 				}
 			case "r", "R", keys.F2:
-				if m := cfg.checkWriteProtect(cursorRow); m != "" {
-					message = m
+				if m := app.editProtected("r", cursorRow); m != "" {
+					message, level = m, MessageWarning
 					break
 				}
-				cursor := &cursorRow.Cell[cursorCol]
-				q := cursor.IsQuoted()
+				cursor := cursorRow.Cell[cursorCol]
 				view.clearCache()
-				if text, err := app.readlineAndValidate("replace cell>", cursor.Text(), cursorRow, cursorCol); err == nil {
-					cursorRow.Replace(cursorCol, text, mode)
-					if q {
-						*cursor = cursor.Quote(mode)
-					}
+				app.printCellSource(cursor, screenWidth)
+				if text, err := app.readlineAndValidate(app.cellPrompt("replace", cursorCol), cursor.Text(), cursorRow, cursorCol); err == nil {
+					app.replaceCell(cursorRow, cursorCol, text)
+					app.registers[registerReplace] = text
+					lastEdit = &editRecord{key: "r", text: text}
 				}
 			case "u":
-				cursorRow.Cell[cursorCol].Restore(mode)
+				if m := app.editProtected("r", cursorRow); m != "" {
+					message, level = m, MessageWarning
+					break
+				}
+				old := cursorRow.Cell[cursorCol].Text()
+				if !app.restoreFromHead(cursorRow, cursorCol) {
+					if !cursorRow.Cell[cursorCol].Modified() {
+						message = "the cell is not modified"
+						break
+					}
+					cursorRow.Cell[cursorCol].Restore(mode)
+				}
+				app.setDirty()
+				app.audit("restore", cursorRow, cursorCol, old, cursorRow.Cell[cursorCol].Text())
+				app.touch(cursorRow, cursorCol)
 			case "y":
 				killbuffer = cursorRow.Cell[cursorCol].Text()
+				app.registers[registerYank] = killbuffer
 				message = "yanked the current cell: " + killbuffer
 			case "p":
-				if m := cfg.checkWriteProtect(cursorRow); m != "" {
-					message = m
+				if m := app.editProtected("r", cursorRow); m != "" {
+					message, level = m, MessageWarning
 					break
 				}
-				cursorRow.Replace(cursorCol, killbuffer, mode)
+				app.replaceCell(cursorRow, cursorCol, killbuffer)
+				lastEdit = &editRecord{key: "r", text: killbuffer}
 				message = "pasted: " + killbuffer
 			case "d", "x":
-				if m := cfg.checkWriteProtectAndColumn(cursorRow); m != "" {
-					message = m
+				if m := app.editProtected("d", cursorRow); m != "" {
+					message, level = m, MessageWarning
 					break
 				}
-				if len(cursorRow.Cell) <= 1 {
-					cursorRow.Replace(0, "", mode)
-				} else {
-					cursorRow.Delete(cursorCol)
-				}
+				app.deleteCell(cursorRow, cursorCol)
+				lastEdit = &editRecord{key: "d"}
 			case "\"":
-				cursor := &cursorRow.Cell[cursorCol]
-				if cursor.IsQuoted() {
-					cursorRow.Replace(cursorCol, cursor.Text(), mode)
+				if m := app.editProtected(ch, cursorRow); m != "" {
+					message, level = m, MessageWarning
+					break
+				}
+				app.toggleQuote(cursorRow, cursorCol)
+				lastEdit = &editRecord{key: `"`}
+			case ".":
+				if lastEdit == nil {
+					message = "no edits to repeat"
+					break
+				}
+				startPrevP := startRow.Prev()
+				cursorRow, cursorCol, err = app.repeatEdit(lastEdit, cursorRow, cursorCol, fetchAll)
+				if err != nil {
+					message, level = err.Error(), MessageError
+				}
+				if startPrevP == nil {
+					startRow = app.Front()
+				} else if next := startPrevP.Next(); next != nil {
+					startRow = next
 				} else {
-					*cursor = cursor.Quote(mode)
+					startRow = startPrevP
 				}
+				view.clearCache()
 			case "w":
-				if fetch != nil {
-					io.WriteString(out, _ANSI_YELLOW+"\rw: Wait a moment for reading all data..."+_ANSI_ERASE_LINE)
-					for {
-						row, err := fetch()
-						if err != nil && err != io.EOF {
-							return nil, err
-						}
-						app.Push(row)
-						if err == io.EOF {
-							break
-						}
-					}
+				if err := fetchAll(); err != nil {
+					return nil, err
 				}
 				if err := cmdWrite(app); err != nil {
-					message = err.Error()
+					message, level = err.Error(), MessageError
+				}
+				view.clearCache()
+			case "W":
+				if err := fetchAll(); err != nil {
+					return nil, err
+				}
+				if err := cmdQuickSave(app); err != nil {
+					message, level = err.Error(), MessageError
 				}
 				view.clearCache()
+			case "X":
+				if m, err := app.openLink(cursorRow.Cell[cursorCol].Text()); err != nil {
+					message, level = err.Error(), MessageError
+				} else {
+					message, level = m, MessageWarning
+				}
 			}
 		}
+		cursorRow = cfg.bodyRow(cursorRow)
 		if L := len(cursorRow.Cell); L <= 0 {
 			cursorCol = 0
 		} else if cursorCol >= L {
 			cursorCol = L - 1
 		}
-		if cursorRow.lnum < startRow.lnum {
+		if cursorRow.Index() < startRow.Index() {
 			startRow = cursorRow.Clone()
-		} else if cursorRow.lnum >= startRow.lnum+screenHeight-1 {
-			goal := cursorRow.lnum - (screenHeight - 1) + 1
-			for startRow = cursorRow.Clone(); startRow.lnum > goal; {
+		} else if cfg.Wrap {
+			startRow = scrollForWrap(cfg, startRow, cursorRow, cellWidth, cfg.HeaderLines, startCol, cursorCol, screenHeight-1, screenWidth)
+		} else if cursorRow.Index() >= startRow.Index()+screenHeight-1 {
+			goal := cursorRow.Index() - (screenHeight - 1) + 1
+			for startRow = cursorRow.Clone(); startRow.Index() > goal; {
 				startRow = startRow.Prev()
 			}
 		}
 		if cursorCol < startCol {
 			startCol = cursorCol
-		} else if cursorCol >= startCol+cols {
-			startCol = cursorCol - cols + 1
+		} else {
+			for startCol < cursorCol && !cfg.isColumnVisible(cursorCol, startCol, cellWidth, screenWidth-1) {
+				startCol++
+			}
+		}
+		if cursorRow.Index() != offsetLnum || cursorCol != offsetCol {
+			// the text scrolled by CellScroll returns on leaving the cell
+			cfg.cellOffset = 0
+			offsetLnum, offsetCol = cursorRow.Index(), cursorCol
 		}
 		up(lfCount, out)
+		lfCount = 0
 	}
 }
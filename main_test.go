@@ -0,0 +1,180 @@
+package csvi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nyaosorg/go-readline-ny/keys"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// runEdit runs cfg.Edit on data typing the keys of script separated by
+// `|`, and returns the result and the text written to the terminal.
+// The data is read as CSV when cfg.Mode is nil.
+func runEdit(t *testing.T, cfg *Config, script, data string) (*Result, string) {
+	t.Helper()
+	if cfg.Mode == nil {
+		cfg.Mode = &uncsv.Mode{Comma: ','}
+	}
+	cfg.Pilot = NewAutoPilot(script)
+	var out strings.Builder
+	result, err := cfg.Edit(strings.NewReader(data), &out)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return result, out.String()
+}
+
+func TestRecoverTerminal(t *testing.T) {
+	stderr := os.Stderr
+	tmp, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	os.Stderr = tmp
+	defer func() {
+		os.Stderr = stderr
+		tmp.Close()
+	}()
+
+	var out strings.Builder
+	cfg := Config{
+		Mode:  &uncsv.Mode{Comma: ','},
+		Pilot: NewAutoPilot("j|q|y"),
+		KeyMap: map[string]func(*KeyEventArgs) (*CommandResult, error){
+			"j": func(e *KeyEventArgs) (*CommandResult, error) {
+				panic("broken key")
+			},
+		},
+	}
+	_, err = cfg.Edit(strings.NewReader("a,b\nc,d\n"), &out)
+	if err == nil || !strings.Contains(err.Error(), "broken key") {
+		t.Fatalf("the panic is not returned as the error: %v", err)
+	}
+	if !strings.HasSuffix(out.String(), _ANSI_RESET+_ANSI_CURSOR_ON+"\n") {
+		t.Fatalf("the terminal is not restored: %q", out.String())
+	}
+	log, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(string(log), "panic: broken key") || !strings.Contains(string(log), "goroutine") {
+		t.Fatalf("the stack is not printed: %q", log)
+	}
+}
+
+func TestCoalesceMotionKeys(t *testing.T) {
+	var log strings.Builder
+	// the clock stops to type all keys within a frame
+	stopped := time.Now()
+	cfg := &Config{
+		DebugLog:   &log,
+		frameClock: func() time.Time { return stopped },
+	}
+	_, out := runEdit(t, cfg, strings.Repeat("j|", 20)+"l|q|y", strings.Repeat("a,b\n", 30))
+	// the first frame and the one after the burst
+	if n := strings.Count(log.String(), `"msg":"draw"`); n != 2 {
+		t.Fatalf("%d frames are drawn for 21 keys typed at once", n)
+	}
+	if !strings.Contains(out, "(2,21/30)") {
+		t.Fatalf("the last frame is not drawn: %q", out)
+	}
+}
+
+func TestLineBadge(t *testing.T) {
+	_, out := runEdit(t, &Config{}, "q|y", "\"a\nb\nc\",\"long\ntext of the cell\",z\n")
+	if !strings.Contains(out, "a␊b␊c⤶3") {
+		t.Fatalf("the number of lines is not drawn: %q", out)
+	}
+	if !strings.Contains(out, "long␊text of⤶2") {
+		t.Fatalf("the badge is not drawn in the truncated cell: %q", out)
+	}
+}
+
+func TestStatusFileControls(t *testing.T) {
+	cfg := &Config{
+		Filename:     "a\x1B[2Jb.csv",
+		StatusFormat: "<{file}>",
+	}
+	_, out := runEdit(t, cfg, "q|y", "a,b\n")
+	if strings.Contains(out, "\x1B[2J") || !strings.Contains(out, "<a␛[2Jb.csv>") {
+		t.Fatalf("the control characters of {file} are not replaced: %q", out)
+	}
+}
+
+func TestLockHeaderRows(t *testing.T) {
+	var lnums []int
+	cfg := &Config{
+		HeaderLines:    1,
+		LockHeaderRows: true,
+		KeyMap: map[string]func(*KeyEventArgs) (*CommandResult, error){
+			"@": func(e *KeyEventArgs) (*CommandResult, error) {
+				lnums = append(lnums, e.CursorRow.Index())
+				return &CommandResult{}, nil
+			},
+		},
+	}
+	runEdit(t, cfg, "@|k|@|j|k|k|@|<|@|q|y", "name,value\na,1\nb,2\n")
+	if fmt.Sprint(lnums) != "[1 1 1 1]" {
+		t.Fatalf("expect the cursor always on the row 1, but %v", lnums)
+	}
+}
+
+func TestStartColumn(t *testing.T) {
+	var pos string
+	cfg := &Config{
+		HeaderLines: 1,
+		StartRow:    3,
+		StartColumn: "value",
+		KeyMap: map[string]func(*KeyEventArgs) (*CommandResult, error){
+			"@": func(e *KeyEventArgs) (*CommandResult, error) {
+				pos = fmt.Sprintf("%d:%d", e.CursorRow.Index(), e.CursorCol)
+				return &CommandResult{}, nil
+			},
+		},
+	}
+	runEdit(t, cfg, "@|q|y", "name,unit,value\na,kg,1\nb,g,2\n")
+	if pos != "2:2" {
+		t.Fatalf("expect 2:2, but %s", pos)
+	}
+}
+
+func TestResultState(t *testing.T) {
+	for _, script := range []string{"/|2|j|q|y", "/|2|j|w|out.csv|q|y"} {
+		cfg := &Config{
+			Saver: SaveFunc(func(*SaveEvent) error { return nil }),
+		}
+		result, _ := runEdit(t, cfg, script, "a,1\nb,2\nc,3\n")
+		saved := strings.Contains(script, "|w|")
+		if result.Row != 3 || result.Col != 2 || result.Search != "2" || result.Saved != saved {
+			t.Fatalf("%s: expect 3,2,\"2\",%v, but %d,%d,%q,%v",
+				script, saved, result.Row, result.Col, result.Search, result.Saved)
+		}
+	}
+}
+
+func TestIsMotionKey(t *testing.T) {
+	for _, c := range []struct {
+		cfg    Config
+		key    string
+		expect bool
+	}{
+		{Config{}, keys.Enter, true},
+		{Config{}, "\x1BOM", true},
+		{Config{}, " ", true},
+		{Config{PickMode: true}, keys.Enter, false},
+		{Config{PickMode: true}, "\x1BOM", false},
+		{Config{PickMode: true}, " ", true},
+		{Config{PickMode: true, MultiPick: true}, " ", false},
+		{Config{PickMode: true, MultiPick: true}, "j", true},
+	} {
+		if result := c.cfg.isMotionKey(c.key); result != c.expect {
+			t.Fatalf("%q (PickMode=%v,MultiPick=%v): expect %v but %v",
+				c.key, c.cfg.PickMode, c.cfg.MultiPick, c.expect, result)
+		}
+	}
+}
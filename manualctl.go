@@ -21,12 +21,18 @@ import (
 
 type _ManualCtl struct {
 	*tty.TTY
+	setup     func(*readline.Editor)
+	registers registers
+	recent    *recentFiles
 }
 
-func newManualCtl() (_ManualCtl, error) {
+func newManualCtl(setup func(*readline.Editor), regs registers, recent *recentFiles) (_ManualCtl, error) {
 	var rc _ManualCtl
 	var err error
 
+	rc.setup = setup
+	rc.registers = regs
+	rc.recent = recent
 	rc.TTY, err = tty.Open()
 	return rc, err
 }
@@ -86,8 +92,14 @@ func (m _ManualCtl) ReadLine(out io.Writer, prompt, defaultStr string, c Candida
 		})
 	}
 
+	editor.BindKey(keys.CtrlR, m.registers.insertCommand())
+	editor.BindKey(keys.CtrlS, m.registers.searchCommand())
+
 	defer io.WriteString(out, _ANSI_CURSOR_OFF)
 	editor.BindKey(keys.Escape, readline.CmdInterrupt)
+	if m.setup != nil {
+		m.setup(editor)
+	}
 	return editor.ReadLine(context.Background())
 }
 
@@ -96,6 +108,7 @@ func (m _ManualCtl) GetFilename(out io.Writer, prompt, defaultStr string) (strin
 	editor := &readline.Editor{
 		Writer:  out,
 		Default: defaultStr,
+		History: m.recent.names,
 		Cursor:  len(defaultStr) - len(filepath.Ext(defaultStr)),
 		PromptWriter: func(w io.Writer) (int, error) {
 			return fmt.Fprintf(w, "\r\x1B[0;33;40;1m%s%s", prompt, _ANSI_ERASE_LINE)
@@ -106,10 +119,13 @@ func (m _ManualCtl) GetFilename(out io.Writer, prompt, defaultStr string) (strin
 		Coloring: &skk.Coloring{},
 	}
 	editor.BindKey(keys.CtrlI, completion.CmdCompletionOrList{
-		Completion: completion.File{},
+		Completion: fileCompletion{recent: m.recent},
 	})
 
 	defer io.WriteString(out, _ANSI_CURSOR_OFF)
 	editor.BindKey(keys.Escape, readline.CmdInterrupt)
+	if m.setup != nil {
+		m.setup(editor)
+	}
 	return editor.ReadLine(context.Background())
 }
@@ -0,0 +1,143 @@
+package csvi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["map"] = &exCommand{
+		help:  "replace the values of the current column by the pairs of the first two columns of FILE, or of the columns KEY and VALUE (map FILE [KEY VALUE] | map -undo)",
+		batch: true,
+		run:   cmdMap,
+	}
+}
+
+// fileColumnIndex returns the index of the column in header by the name
+// or the 1-based number, and true when it is found by the name.
+func fileColumnIndex(header *uncsv.Row, name string) (int, bool, error) {
+	for i, c := range header.Cell {
+		if c.Text() == name {
+			return i, true, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 {
+		return n - 1, false, nil
+	}
+	return -1, false, fmt.Errorf("%s: %w", name, errNoSuchColumn)
+}
+
+// readMapping reads the pairs of the columns key and value of fname.
+// When they are names on the first line, the line is not a pair.
+// The file is read as CSV when its name ends with .csv, otherwise as TSV.
+func readMapping(fname, key, value string) (map[string]string, error) {
+	fd, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	mode := &uncsv.Mode{Comma: '\t'}
+	if strings.EqualFold(filepath.Ext(fname), ".csv") {
+		mode.Comma = ','
+	}
+	rows, err := uncsv.ReadAll(fd, mode)
+	if err != nil {
+		return nil, err
+	}
+	if L := len(rows); L > 0 && isEmptyRow(&rows[L-1]) {
+		rows = rows[:L-1]
+	}
+	if len(rows) <= 0 {
+		return nil, fmt.Errorf("%s: empty", fname)
+	}
+	keyCol, valueCol := 0, 1
+	if key != "" {
+		var byName1, byName2 bool
+		keyCol, byName1, err = fileColumnIndex(&rows[0], key)
+		if err != nil {
+			return nil, err
+		}
+		valueCol, byName2, err = fileColumnIndex(&rows[0], value)
+		if err != nil {
+			return nil, err
+		}
+		if byName1 || byName2 {
+			rows = rows[1:]
+		}
+	}
+	pairs := map[string]string{}
+	for _, row := range rows {
+		if keyCol >= len(row.Cell) || valueCol >= len(row.Cell) {
+			continue
+		}
+		k := row.Cell[keyCol].Text()
+		if _, ok := pairs[k]; !ok {
+			pairs[k] = row.Cell[valueCol].Text()
+		}
+	}
+	return pairs, nil
+}
+
+func cmdMap(e *exCommandArgs) (string, error) {
+	const usage = "usage: map FILE [KEY VALUE] | map -undo"
+	if e.Args == "-undo" {
+		return e.undoColumnEdit()
+	}
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	args := strings.Fields(e.Args)
+	var key, value string
+	switch len(args) {
+	case 1:
+	case 3:
+		key, value = args[1], args[2]
+	default:
+		return usage, nil
+	}
+	fname, _ := expandHome(args[0])
+	pairs, err := readMapping(fname, key, value)
+	if err != nil {
+		return "", err
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	col := e.CursorCol
+	var rows []*RowPtr
+	var texts []string
+	unmapped := 0
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() < e.HeaderLines || col >= len(p.Cell) {
+			continue
+		}
+		old := p.Cell[col].Text()
+		text, ok := pairs[old]
+		if !ok {
+			unmapped++
+			continue
+		}
+		if text == old {
+			continue
+		}
+		text, err := e.validate(p, col, text)
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", p.Index()+1, err)
+		}
+		rows = append(rows, p)
+		texts = append(texts, text)
+	}
+	if len(rows) <= 0 {
+		return fmt.Sprintf("no cells change (%d value(s) not found in %s)", unmapped, fname), nil
+	}
+	if e.Config.Pilot != nil && !e.confirm(ConfirmCommand, fmt.Sprintf("replace %d cell(s) (%d value(s) not found) ? [y/n]", len(rows), unmapped)) {
+		return "", nil
+	}
+	e.setColumn(col, rows, texts)
+	return fmt.Sprintf("replaced %d cell(s), %d value(s) not found (`:map -undo` restores them)", len(rows), unmapped), nil
+}
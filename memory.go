@@ -0,0 +1,125 @@
+package csvi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["mem"] = &exCommand{
+		help: "show the number of rows loaded, the memory in use and the chunks evicted",
+		run:  cmdMem,
+	}
+}
+
+// formatBytes returns n in KiB, MiB or GiB
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	value := float64(n) / unit
+	for _, suffix := range []string{"KiB", "MiB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f%s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1fGiB", value)
+}
+
+func cmdMem(e *exCommandArgs) (string, error) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	state := "all loaded"
+	if e.loading() {
+		state = "loading"
+	}
+	message := fmt.Sprintf("%d rows (%s), heap %s, system %s",
+		e.Len(), state, formatBytes(m.HeapAlloc), formatBytes(m.Sys))
+	if L := e.csvLines; L.spill != nil {
+		evicted := 0
+		for _, chunk := range L.chunks {
+			if chunk == nil {
+				evicted++
+			}
+		}
+		message += fmt.Sprintf(", %d of %d chunk(s) evicted", evicted, len(L.chunks))
+	}
+	return message, nil
+}
+
+// countingReader counts the bytes read to know the offsets of the rows
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// newSpill returns rowSpill to evict the rows of in, or nil when they are
+// not evicted: ResidentRows is zero, the data is not read-only, or in can
+// not be read again at any offset like pipes.
+func (cfg *Config) newSpill(in io.Reader) *rowSpill {
+	if cfg.ResidentRows <= 0 || !(cfg.ReadOnly || cfg.PickMode || cfg.MultiPick) {
+		return nil
+	}
+	source, ok := in.(io.ReaderAt)
+	if !ok {
+		return nil
+	}
+	if s, ok := in.(io.Seeker); ok {
+		if offset, err := s.Seek(0, io.SeekCurrent); err != nil || offset != 0 {
+			return nil
+		}
+	}
+	return &rowSpill{
+		source: source,
+		read: func(r *bufio.Reader) (*uncsv.Row, error) {
+			return uncsv.ReadLine(r, cfg.Mode)
+		},
+		next: -1,
+		keep: max(cfg.ResidentRows/rowChunkSize/2, 1),
+	}
+}
+
+// pinnedRow returns true when the row has to stay in memory: its cells are
+// modified, or it is the key of the state of the rows.
+func (app *_Application) pinnedRow(row *uncsv.Row) bool {
+	if app.checked[row] || app.blame[row] != nil {
+		return true
+	}
+	if _, ok := app.gitBase[row]; ok {
+		return true
+	}
+	return slices.ContainsFunc(row.Cell, uncsv.Cell.Modified)
+}
+
+// evictRows evicts the chunks of the rows more than ResidentRows/2 rows
+// away from the cursor. They are read again from the file when they are
+// needed.
+func (app *_Application) evictRows(cursor *RowPtr) {
+	L := app.csvLines
+	if L.spill == nil || cursor == nil {
+		return
+	}
+	cursor.resolve()
+	L.spill.cursor = cursor.chunk
+	for c, chunk := range L.chunks {
+		if chunk == nil || (c >= cursor.chunk-L.spill.keep && c <= cursor.chunk+L.spill.keep) {
+			continue
+		}
+		if !slices.ContainsFunc(chunk, app.pinnedRow) {
+			L.evict(c)
+		}
+	}
+}
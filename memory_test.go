@@ -0,0 +1,72 @@
+package csvi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestFormatBytes(t *testing.T) {
+	for _, c := range []struct {
+		n      uint64
+		expect string
+	}{
+		{1023, "1023B"},
+		{1024, "1.0KiB"},
+		{1024*1024 - 1, "1024.0KiB"},
+		{1024 * 1024, "1.0MiB"},
+		{1024*1024*1024 - 1, "1024.0MiB"},
+		{1024 * 1024 * 1024, "1.0GiB"},
+		{3 * 1024 * 1024 * 1024 / 2, "1.5GiB"},
+	} {
+		if result := formatBytes(c.n); result != c.expect {
+			t.Fatalf("%d: expect %q but %q", c.n, c.expect, result)
+		}
+	}
+}
+
+func TestResidentRows(t *testing.T) {
+	const rows = rowChunkSize*4 + 100
+	var data strings.Builder
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&data, "%d,\"value\n%d\"\n", i, i)
+	}
+	var evicted []int
+	cfg := &Config{
+		ReadOnly:     true,
+		ResidentRows: 1,
+		KeyMap: map[string]func(*KeyEventArgs) (*CommandResult, error){
+			"@": func(e *KeyEventArgs) (*CommandResult, error) {
+				n := 0
+				for _, chunk := range e.csvLines.chunks {
+					if chunk == nil {
+						n++
+					}
+				}
+				evicted = append(evicted, n)
+				lnum := 0
+				e.Each(func(row *uncsv.Row) bool {
+					if row.Cell[0].Text() != strconv.Itoa(lnum) ||
+						row.Cell[1].Text() != fmt.Sprintf("value\n%d", lnum) {
+						t.Fatalf("row %d: read %q", lnum, row.Cell[0].Text())
+					}
+					lnum++
+					return true
+				})
+				if lnum != rows {
+					t.Fatalf("expect %d rows but %d", rows, lnum)
+				}
+				return &CommandResult{}, nil
+			},
+		},
+	}
+	// The first and the last chunks are always kept, and the chunks evicted
+	// once are not read again until their rows are drawn.
+	runEdit(t, cfg, "G|@|g|@|q|y", data.String())
+	if fmt.Sprint(evicted) != "[3 3]" {
+		t.Fatalf("expect 3 chunks evicted, but %v", evicted)
+	}
+}
@@ -0,0 +1,276 @@
+package csvi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// mergeConflict is a cell changed differently by both sides.
+// ours or theirs is nil when the side has deleted the row.
+type mergeConflict struct {
+	ours     *uncsv.Cell
+	theirs   *uncsv.Cell
+	resolved bool
+}
+
+// Merge is the rows of two sides changed from their base and merged.
+// The cells changed differently by both sides are the conflicts, which are
+// underlined as modified cells and resolved on the editor by KeyMap.
+type Merge struct {
+	Rows      []*uncsv.Row
+	mode      *uncsv.Mode
+	header    int
+	keyCol    int
+	conflicts map[*uncsv.Row]map[int]*mergeConflict
+}
+
+var errHeaderMismatch = errors.New("the headers of the files differ")
+
+// MergeRows merges ours and theirs changed from base. The rows under the
+// header of headerLines rows are matched by the column key, which is the
+// header name or the 1-based column number.
+func MergeRows(base, ours, theirs []uncsv.Row, headerLines int, key string, mode *uncsv.Mode) (*Merge, error) {
+	m := &Merge{
+		mode:      mode,
+		header:    headerLines,
+		keyCol:    -1,
+		conflicts: map[*uncsv.Row]map[int]*mergeConflict{},
+	}
+	for i := 0; i < headerLines; i++ {
+		if !sameRow(rowAtIndex(base, i), rowAtIndex(ours, i)) ||
+			!sameRow(rowAtIndex(ours, i), rowAtIndex(theirs, i)) {
+			return nil, errHeaderMismatch
+		}
+	}
+	if n, err := strconv.Atoi(key); err == nil && n >= 1 {
+		m.keyCol = n - 1
+	} else if headerLines > 0 && len(ours) > 0 {
+		for i, c := range ours[0].Cell {
+			if c.Text() == key {
+				m.keyCol = i
+				break
+			}
+		}
+	}
+	if m.keyCol < 0 {
+		return nil, fmt.Errorf("%s: %w", key, errNoSuchColumn)
+	}
+	m.merge(base, ours, theirs)
+	return m, nil
+}
+
+func rowAtIndex(rows []uncsv.Row, i int) *uncsv.Row {
+	if i < len(rows) {
+		return &rows[i]
+	}
+	return nil
+}
+
+// indexRows maps the key of each row to the row. The second and later
+// rows with the same key are told apart by the number of the occurrence.
+func (m *Merge) indexRows(rows []uncsv.Row) (map[string]*uncsv.Row, []string) {
+	index := map[string]*uncsv.Row{}
+	keys := []string{}
+	count := map[string]int{}
+	for i := m.header; i < len(rows); i++ {
+		key := cellAt(&rows[i], m.keyCol).Text()
+		count[key]++
+		if n := count[key]; n > 1 {
+			key += "\x00" + strconv.Itoa(n)
+		}
+		index[key] = &rows[i]
+		keys = append(keys, key)
+	}
+	return index, keys
+}
+
+func cellAt(row *uncsv.Row, col int) uncsv.Cell {
+	if row != nil && col < len(row.Cell) {
+		return row.Cell[col]
+	}
+	return uncsv.Cell{}
+}
+
+func sameRow(a, b *uncsv.Row) bool {
+	width := 0
+	if a != nil {
+		width = len(a.Cell)
+	}
+	if b != nil {
+		width = max(width, len(b.Cell))
+	}
+	for i := 0; i < width; i++ {
+		if cellAt(a, i).Text() != cellAt(b, i).Text() {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Merge) addConflict(row *uncsv.Row, col int, c *mergeConflict) {
+	if m.conflicts[row] == nil {
+		m.conflicts[row] = map[int]*mergeConflict{}
+	}
+	m.conflicts[row][col] = c
+}
+
+// mergeRow merges the cells of ours and theirs changed from base,
+// which is nil when both sides have added the row.
+func (m *Merge) mergeRow(base, ours, theirs *uncsv.Row) *uncsv.Row {
+	width := max(len(ours.Cell), len(theirs.Cell))
+	row := &uncsv.Row{Cell: make([]uncsv.Cell, width), Term: ours.Term}
+	for i := 0; i < width; i++ {
+		b, o, t := cellAt(base, i), cellAt(ours, i), cellAt(theirs, i)
+		switch {
+		case o.Text() == t.Text() || t.Text() == b.Text():
+			row.Cell[i] = o
+		case o.Text() == b.Text():
+			row.Cell[i] = t
+		default:
+			row.Cell[i] = o
+			row.Cell[i].SetOriginal(b)
+			m.addConflict(row, i, &mergeConflict{ours: &o, theirs: &t})
+		}
+	}
+	return row
+}
+
+// keepChanged keeps the row changed by one side and deleted by the other
+// side as conflicts.
+func (m *Merge) keepChanged(base, changed *uncsv.Row, oursChanged bool) *uncsv.Row {
+	row := &uncsv.Row{Cell: make([]uncsv.Cell, len(changed.Cell)), Term: changed.Term}
+	copy(row.Cell, changed.Cell)
+	for i := range row.Cell {
+		b := cellAt(base, i)
+		if row.Cell[i].Text() == b.Text() {
+			continue
+		}
+		c := row.Cell[i]
+		row.Cell[i].SetOriginal(b)
+		if oursChanged {
+			m.addConflict(row, i, &mergeConflict{ours: &c})
+		} else {
+			m.addConflict(row, i, &mergeConflict{theirs: &c})
+		}
+	}
+	return row
+}
+
+func (m *Merge) merge(base, ours, theirs []uncsv.Row) {
+	for i := 0; i < m.header && i < len(ours); i++ {
+		m.Rows = append(m.Rows, &ours[i])
+	}
+	baseIndex, _ := m.indexRows(base)
+	oursIndex, oursKeys := m.indexRows(ours)
+	theirsIndex, theirsKeys := m.indexRows(theirs)
+	for _, key := range oursKeys {
+		b, o, t := baseIndex[key], oursIndex[key], theirsIndex[key]
+		switch {
+		case t != nil:
+			m.Rows = append(m.Rows, m.mergeRow(b, o, t))
+		case b == nil: // added by ours
+			m.Rows = append(m.Rows, o)
+		case !sameRow(o, b): // changed by ours and deleted by theirs
+			m.Rows = append(m.Rows, m.keepChanged(b, o, true))
+		}
+	}
+	for _, key := range theirsKeys {
+		if oursIndex[key] != nil {
+			continue
+		}
+		b, t := baseIndex[key], theirsIndex[key]
+		switch {
+		case b == nil: // added by theirs
+			m.Rows = append(m.Rows, t)
+		case !sameRow(t, b): // changed by theirs and deleted by ours
+			m.Rows = append(m.Rows, m.keepChanged(b, t, false))
+		}
+	}
+	// the last row of a side may not be the last one any more
+	for i := 0; i+1 < len(m.Rows); i++ {
+		if m.Rows[i].Term == "" {
+			m.Rows[i].Term = m.mode.DefaultTerm
+			if m.Rows[i].Term == "" {
+				m.Rows[i].Term = "\n"
+			}
+		}
+	}
+}
+
+// Unresolved returns the number of the conflicts not resolved yet
+func (m *Merge) Unresolved() int {
+	n := 0
+	for _, cols := range m.conflicts {
+		for _, c := range cols {
+			if !c.resolved {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func conflictText(c *uncsv.Cell) string {
+	if c == nil {
+		return "(row deleted)"
+	}
+	return c.Text()
+}
+
+// accept resolves the conflict under the cursor with the side chosen
+func (m *Merge) accept(e *KeyEventArgs, ours bool) (*CommandResult, error) {
+	c := m.conflicts[e.CursorRow.Row][e.CursorCol]
+	if c == nil {
+		return &CommandResult{Message: "not a conflict"}, nil
+	}
+	side := c.theirs
+	if ours {
+		side = c.ours
+	}
+	if side == nil {
+		if e.Len() <= 1 {
+			return &CommandResult{Message: "the last row can not be removed"}, nil
+		}
+		row := e.CursorRow.Row
+		if _, ok := e.deleteRow(e.CursorRow); !ok {
+			return &CommandResult{Message: "the row is not removed"}, nil
+		}
+		for _, c := range m.conflicts[row] {
+			c.resolved = true
+		}
+		return &CommandResult{
+			Message: fmt.Sprintf("the row is removed (%d conflict(s) left)", m.Unresolved()),
+		}, nil
+	}
+	for len(e.CursorRow.Cell) <= e.CursorCol {
+		e.CursorRow.Cell = append(e.CursorRow.Cell, uncsv.Cell{})
+	}
+	e.replaceCell(e.CursorRow, e.CursorCol, side.Text())
+	c.resolved = true
+	return &CommandResult{
+		Message: fmt.Sprintf("accepted %s (%d conflict(s) left)", conflictText(side), m.Unresolved()),
+	}, nil
+}
+
+func (m *Merge) show(e *KeyEventArgs) (*CommandResult, error) {
+	c := m.conflicts[e.CursorRow.Row][e.CursorCol]
+	if c == nil {
+		return &CommandResult{Message: "not a conflict"}, nil
+	}
+	return &CommandResult{
+		Message: fmt.Sprintf("<ours: %s  >theirs: %s", conflictText(c.ours), conflictText(c.theirs)),
+	}, nil
+}
+
+// KeyMap returns the keys for Config.KeyMap resolving the conflict under
+// the cursor: `<` accepts ours, `>` accepts theirs and `=` shows both.
+func (m *Merge) KeyMap() map[string]func(*KeyEventArgs) (*CommandResult, error) {
+	return map[string]func(*KeyEventArgs) (*CommandResult, error){
+		"<": func(e *KeyEventArgs) (*CommandResult, error) { return m.accept(e, true) },
+		">": func(e *KeyEventArgs) (*CommandResult, error) { return m.accept(e, false) },
+		"=": m.show,
+	}
+}
@@ -0,0 +1,142 @@
+package csvi
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestMergeRows(t *testing.T) {
+	mode := &uncsv.Mode{Comma: ','}
+	read := func(s string) []uncsv.Row {
+		rows, err := uncsv.ReadAll(strings.NewReader(s), mode)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		return rows[:len(rows)-1] // the empty row after the last terminator
+	}
+	const base = "id,name,age\n1,bob,3\n2,ann,4\n3,cat,5\n"
+	for _, c := range []struct {
+		name      string
+		ours      string
+		theirs    string
+		key       string
+		expect    string
+		conflicts int
+		err       error
+	}{
+		{
+			name:   "clean",
+			ours:   "id,name,age\n1,bob,30\n2,ann,4\n3,cat,5\n4,dan,6\n",
+			theirs: "id,name,age\n1,BOB,3\n3,cat,5\n5,eve,7\n",
+			key:    "id",
+			expect: "id,name,age\n1,BOB,30\n3,cat,5\n4,dan,6\n5,eve,7\n",
+		},
+		{
+			name:      "conflict",
+			ours:      "id,name,age\n1,bob,30\n2,ann,4\n3,cat,5\n",
+			theirs:    "id,name,age\n1,bob,31\n2,ann,4\n3,cat,5\n",
+			key:       "1",
+			expect:    "id,name,age\n1,bob,30\n2,ann,4\n3,cat,5\n",
+			conflicts: 1,
+		},
+		{
+			name:      "changed and deleted",
+			ours:      "id,name,age\n1,bob,3\n2,ANN,4\n3,cat,5\n",
+			theirs:    "id,name,age\n1,bob,3\n3,cat,5\n",
+			key:       "id",
+			expect:    "id,name,age\n1,bob,3\n2,ANN,4\n3,cat,5\n",
+			conflicts: 1,
+		},
+		{
+			name:   "missing key cells",
+			ours:   "id,name,age\n1,bob,3\n2,ann,4\n3,cat,5\n",
+			theirs: "id,name,age\n1,bob,3\n2,ann,4\n3,cat,5\n,\n",
+			key:    "age",
+			expect: "id,name,age\n1,bob,3\n2,ann,4\n3,cat,5\n,\n",
+		},
+		{
+			name:   "no key column",
+			ours:   base,
+			theirs: base,
+			key:    "email",
+			err:    errNoSuchColumn,
+		},
+		{
+			name:   "header mismatch",
+			ours:   base,
+			theirs: "id,name,years\n1,bob,3\n",
+			key:    "id",
+			err:    errHeaderMismatch,
+		},
+	} {
+		m, err := MergeRows(read(base), read(c.ours), read(c.theirs), 1, c.key, mode)
+		if c.err != nil {
+			if !errors.Is(err, c.err) {
+				t.Fatalf("%s: expect %v but %v", c.name, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", c.name, err.Error())
+		}
+		var out strings.Builder
+		rows := m.Rows
+		mode.DumpBy(func() *uncsv.Row {
+			if len(rows) <= 0 {
+				return nil
+			}
+			row := rows[0]
+			rows = rows[1:]
+			return row
+		}, &out)
+		if out.String() != c.expect {
+			t.Fatalf("%s: expect %q but %q", c.name, c.expect, out.String())
+		}
+		if n := m.Unresolved(); n != c.conflicts {
+			t.Fatalf("%s: expect %d conflict(s) but %d", c.name, c.conflicts, n)
+		}
+	}
+}
+
+func TestMergeAccept(t *testing.T) {
+	mode := &uncsv.Mode{Comma: ','}
+	read := func(s string) []uncsv.Row {
+		rows, err := uncsv.ReadAll(strings.NewReader(s), mode)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		return rows[:len(rows)-1]
+	}
+	base := read("id,age\n1,3\n2,4\n")
+	ours := read("id,age\n1,30\n2,40\n")
+	theirs := read("id,age\n1,31\n")
+	m, err := MergeRows(base, ours, theirs, 1, "id", mode)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cfg := &Config{
+		Mode:        mode,
+		HeaderLines: 1,
+		KeyMap:      m.KeyMap(),
+		Pilot:       NewAutoPilot("j|l|>|j|>|q|y"),
+	}
+	result, err := cfg.EditRows(m.Rows, io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var out strings.Builder
+	result.Dump(&out)
+	if expect := "id,age\n1,31\n"; out.String() != expect {
+		t.Fatalf("expect %q but %q", expect, out.String())
+	}
+	if n := m.Unresolved(); n != 0 {
+		t.Fatalf("expect all conflicts resolved, but %d left", n)
+	}
+	if !result.Modified() {
+		t.Fatal("expect the rows modified by accepting theirs")
+	}
+}
@@ -4,14 +4,22 @@ import (
 	"strings"
 
 	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
 )
 
+// cutStrInWidth returns the longest prefix of s whose width is at most
+// cellwidth and its width. It never cuts a grapheme cluster such as an
+// emoji ZWJ sequence or a character followed by combining marks.
 func cutStrInWidth(s string, cellwidth int) (string, int) {
 	w := 0
-	for n, c := range s {
-		w1 := runewidth.RuneWidth(c)
+	state := -1
+	rest := s
+	for rest != "" {
+		var cluster string
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		w1 := runewidth.StringWidth(cluster)
 		if w+w1 > cellwidth {
-			return s[:n], w
+			return s[:len(s)-len(rest)-len(cluster)], w
 		}
 		w += w1
 	}
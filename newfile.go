@@ -0,0 +1,79 @@
+package csvi
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+var delimiterCandidates = Candidate{",", "tab", ";", "|"}
+
+func delimiterName(c byte) string {
+	if c == '\t' {
+		return "tab"
+	}
+	return string(rune(c))
+}
+
+// newFileWizard asks the delimiter, the names of the columns and
+// the number of columns, and pushes the rows of the new document.
+// It returns false when the user cancels it.
+func (app *_Application) newFileWizard() bool {
+	defer io.WriteString(app.out, "\r"+_ANSI_ERASE_LINE)
+	mode := app.Mode
+
+	d, err := app.Pilot.ReadLine(app.out, "new file. delimiter>", delimiterName(mode.Comma), delimiterCandidates)
+	if err != nil {
+		return false
+	}
+	switch d = strings.TrimSpace(d); strings.ToLower(d) {
+	case "tab", `\t`:
+		mode.Comma = '\t'
+	default:
+		if len(d) == 1 {
+			mode.Comma = d[0]
+		}
+	}
+
+	names, err := app.Pilot.ReadLine(app.out, "column names (separated by commas)>", "", nil)
+	if err != nil {
+		return false
+	}
+	if mode.DefaultTerm == "" {
+		mode.DefaultTerm = "\n"
+	}
+	width := 1
+	if names = strings.TrimSpace(names); names != "" {
+		header := uncsv.NewRow(mode)
+		for i, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			if i == 0 {
+				header.Replace(0, name, mode)
+			} else {
+				header.Insert(i, name, mode)
+			}
+		}
+		app.Push(&header)
+		width = len(header.Cell)
+		if app.HeaderLines <= 0 {
+			app.HeaderLines = 1
+		}
+	} else {
+		n, err := app.Pilot.ReadLine(app.out, "number of columns>", "1", nil)
+		if err != nil {
+			return false
+		}
+		if v, err := strconv.Atoi(strings.TrimSpace(n)); err == nil && v > 1 {
+			width = v
+		}
+	}
+	row := uncsv.NewRow(mode)
+	for len(row.Cell) < width {
+		row.Insert(0, "", mode)
+	}
+	app.Push(&row)
+	app.setDirty()
+	return true
+}
@@ -0,0 +1,15 @@
+package csvi
+
+// Levels of the messages given to Config.OnMessage
+const (
+	MessageInfo    = "info"
+	MessageWarning = "warning"
+	MessageError   = "error"
+)
+
+// notify gives the message to Config.OnMessage if it is set
+func (cfg *Config) notify(level, text string) {
+	if cfg.OnMessage != nil && text != "" {
+		cfg.OnMessage(level, text)
+	}
+}
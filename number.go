@@ -0,0 +1,145 @@
+package csvi
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func init() {
+	exCommands["num"] = &exCommand{
+		help:  "normalize the numbers of the current column (num strip|dot|comma|pad N | num -undo)",
+		batch: true,
+		run:   cmdNumber,
+	}
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// parseNumber returns the value of the text of a cell like `1,234.5` or
+// `$ 12`, or false when it is not a number.
+func parseNumber(s string) (float64, bool) {
+	value, err := strconv.ParseFloat(stripNumber(s), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
+
+var thousandsPattern = regexp.MustCompile(`^[-+]?\d{1,3}(,\d{3})+(\.\d*)?$`)
+
+// stripNumber removes currency symbols, spaces and thousands separators
+// from `$ 1,234.5`. Commas not separating thousands are kept.
+func stripNumber(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.Is(unicode.Sc, r) {
+			return -1
+		}
+		return r
+	}, s)
+	if thousandsPattern.MatchString(s) {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	return s
+}
+
+// decimalCommaToPoint makes `1.234,5` `1234.5`. Texts without commas
+// are kept because `1.234` may be either of them.
+func decimalCommaToPoint(s string) string {
+	if !strings.Contains(s, ",") {
+		return s
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(s, ".", ""), ",", ".")
+}
+
+// decimalPointToComma makes `1234.5` `1234,5`
+func decimalPointToComma(s string) string {
+	if !isNumber(s) {
+		return s
+	}
+	return strings.ReplaceAll(s, ".", ",")
+}
+
+// padNumber pads the integer part of s with zeros to width digits
+// after the sign.
+func padNumber(s string, width int) string {
+	if !isNumber(s) {
+		return s
+	}
+	sign := ""
+	if s[0] == '-' || s[0] == '+' {
+		sign, s = s[:1], s[1:]
+	}
+	integer, _, _ := strings.Cut(s, ".")
+	if n := width - len(integer); n > 0 {
+		s = strings.Repeat("0", n) + s
+	}
+	return sign + s
+}
+
+func cmdNumber(e *exCommandArgs) (string, error) {
+	const usage = "usage: num strip|dot|comma|pad N | num -undo"
+	if e.Args == "-undo" {
+		return e.undoColumnEdit()
+	}
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	var convert func(string) string
+	switch args := strings.Fields(e.Args); {
+	case len(args) == 1 && args[0] == "strip":
+		convert = stripNumber
+	case len(args) == 1 && args[0] == "dot":
+		convert = decimalCommaToPoint
+	case len(args) == 1 && args[0] == "comma":
+		convert = decimalPointToComma
+	case len(args) == 2 && args[0] == "pad":
+		width, err := strconv.Atoi(args[1])
+		if err != nil || width <= 0 {
+			return usage, nil
+		}
+		convert = func(s string) string { return padNumber(s, width) }
+	default:
+		return usage, nil
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	col := e.CursorCol
+	var rows []*RowPtr
+	var texts []string
+	skipped := 0
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() < e.HeaderLines || col >= len(p.Cell) {
+			continue
+		}
+		old := p.Cell[col].Text()
+		text := convert(old)
+		if text == old {
+			continue
+		}
+		if s := strings.ReplaceAll(text, ",", "."); !isNumber(s) {
+			// not a number: leave it as it is
+			skipped++
+			continue
+		}
+		text, err := e.validate(p, col, text)
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", p.Index()+1, err)
+		}
+		rows = append(rows, p)
+		texts = append(texts, text)
+	}
+	if len(rows) <= 0 {
+		return fmt.Sprintf("no cells change (%d cell(s) not numbers)", skipped), nil
+	}
+	e.setColumn(col, rows, texts)
+	return fmt.Sprintf("modified %d cell(s), skipped %d cell(s) not numbers (`:num -undo` restores them)",
+		len(rows), skipped), nil
+}
@@ -0,0 +1,25 @@
+package csvi
+
+import (
+	"testing"
+)
+
+func TestNumberConversion(t *testing.T) {
+	for _, c := range [][2]string{
+		{stripNumber("$ 1,234.50"), "1234.50"},
+		{stripNumber("€12"), "12"},
+		{stripNumber("1,5"), "1,5"},
+		{decimalCommaToPoint("1.5"), "1.5"},
+		{decimalCommaToPoint("1.234,5"), "1234.5"},
+		{decimalPointToComma("1234.5"), "1234,5"},
+		{decimalPointToComma("v1.2.3"), "v1.2.3"},
+		{padNumber("42", 5), "00042"},
+		{padNumber("-3.14", 3), "-003.14"},
+		{padNumber("abc", 5), "abc"},
+		{padNumber("123456", 3), "123456"},
+	} {
+		if c[0] != c[1] {
+			t.Fatalf("expect %q but %q", c[1], c[0])
+		}
+	}
+}
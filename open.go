@@ -0,0 +1,82 @@
+package csvi
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	exCommands["open"] = &exCommand{
+		help: "open the URL or the file of the current cell with the application of the system (same as X)",
+		run: func(e *exCommandArgs) (string, error) {
+			if e.CursorCol >= len(e.CursorRow.Cell) {
+				return "", errNoLink
+			}
+			return e.openLink(e.CursorRow.Cell[e.CursorCol].Text())
+		},
+	}
+}
+
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?|ftp|file)://[^\s"'<>\x00-\x1F\x7F]+|\bmailto:[^\s"'<>\x00-\x1F\x7F]+`)
+
+var errNoLink = errors.New("no URLs or files in the current cell")
+
+// cellURL returns the first URL in text, which the cell links to with
+// OSC 8, or "" when there is none
+func cellURL(text string) string {
+	if !strings.Contains(text, ":") {
+		return ""
+	}
+	return urlPattern.FindString(text)
+}
+
+// linkTarget returns the first URL in text, or the path of the file named
+// text. A relative path is found from the directory of the file edited.
+func (app *_Application) linkTarget(text string) (string, error) {
+	if url := urlPattern.FindString(text); url != "" {
+		return url, nil
+	}
+	path := strings.TrimSpace(text)
+	if path == "" {
+		return "", errNoLink
+	}
+	path, _ = expandHome(path)
+	if !filepath.IsAbs(path) && app.Filename != "" && !strings.Contains(app.Filename, "://") {
+		path = filepath.Join(filepath.Dir(app.Filename), path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", errNoLink
+	}
+	return filepath.Abs(path)
+}
+
+// openCommand returns the command to open target with the application
+// associated by the system.
+func openCommand(target string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("rundll32.exe", "url.dll,FileProtocolHandler", target)
+	case "darwin":
+		return exec.Command("open", target)
+	default:
+		return exec.Command("xdg-open", target)
+	}
+}
+
+func (app *_Application) openLink(text string) (string, error) {
+	target, err := app.linkTarget(text)
+	if err != nil {
+		return "", err
+	}
+	cmd := openCommand(target)
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	go cmd.Wait()
+	return "opened " + target, nil
+}
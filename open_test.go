@@ -0,0 +1,31 @@
+package csvi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinkTarget(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "report.txt"), nil, 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+	app := &_Application{Config: &Config{Filename: filepath.Join(dir, "index.csv")}}
+	for text, expect := range map[string]string{
+		"see https://example.com/a?b=c now": "https://example.com/a?b=c",
+		"mailto:someone@example.com":        "mailto:someone@example.com",
+		"report.txt":                        filepath.Join(dir, "report.txt"),
+	} {
+		result, err := app.linkTarget(text)
+		if err != nil {
+			t.Fatalf("%s: %s", text, err.Error())
+		}
+		if result != expect {
+			t.Fatalf("%s: expect %s but %s", text, expect, result)
+		}
+	}
+	if _, err := app.linkTarget("no link"); err == nil {
+		t.Fatal("no link must be an error")
+	}
+}
@@ -0,0 +1,175 @@
+package csvi
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+func init() {
+	exCommands["outliers"] = &exCommand{
+		help: "highlight the numbers of the current column more than K (default 3) standard deviations from the mean, or out of 1.5 IQR from the quartiles with iqr. * and # move to them (outliers [K|iqr|off])",
+		run:  cmdOutliers,
+	}
+}
+
+// defaultOutlierSigma is K of `:outliers` without the argument
+const defaultOutlierSigma = 3
+
+// outlierStyle is the colors of the outliers: white on magenta
+const outlierStyle = "\x1B[45;97m"
+
+// outlierRange keeps the column checked by `:outliers` and the range of
+// the numbers out of which are outliers. k is the number of the standard
+// deviations, or zero for the IQR. The rows loaded later update the range.
+type outlierRange struct {
+	col       int
+	k         float64
+	values    []float64
+	lo, hi    float64
+	scanned   int
+	editCount int
+}
+
+// updateOutliers scans the rows not scanned yet and calculates the range
+// again, or scans all rows again after they are modified.
+func (app *_Application) updateOutliers() {
+	r := app.outliers
+	if r == nil {
+		return
+	}
+	if r.editCount != app.editCount {
+		*r = outlierRange{col: r.col, k: r.k, editCount: app.editCount}
+	}
+	if r.scanned >= app.Len() {
+		return
+	}
+	n := len(r.values)
+	p, err := app.rowAt(r.scanned + 1)
+	for ; err == nil && p != nil; p = p.Next() {
+		r.scanned++
+		if p.Index() < app.HeaderLines || r.col >= len(p.Cell) {
+			continue
+		}
+		if v, ok := parseNumber(p.Cell[r.col].Text()); ok {
+			r.values = append(r.values, v)
+		}
+	}
+	if len(r.values) <= n {
+		return
+	}
+	if r.k > 0 {
+		r.lo, r.hi = sigmaRange(r.values, r.k)
+	} else {
+		r.lo, r.hi = iqrRange(r.values)
+	}
+}
+
+// isOutlier returns true when the text of the cell of the column col is
+// a number out of the range
+func (cfg *Config) isOutlier(col int, text string) bool {
+	r := cfg.outliers
+	if r == nil || col != r.col || len(r.values) <= 0 {
+		return false
+	}
+	value, ok := parseNumber(text)
+	return ok && (value < r.lo || value > r.hi)
+}
+
+// sigmaRange returns the range within k standard deviations from the mean
+func sigmaRange(values []float64, k float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	sd := math.Sqrt(squares / float64(len(values)))
+	return mean - k*sd, mean + k*sd
+}
+
+// quantile returns the q-quantile of the sorted values with the linear
+// interpolation
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (sorted[i+1]-sorted[i])*(pos-float64(i))
+}
+
+// iqrRange returns the range within 1.5 IQR from the first and the third
+// quartiles
+func iqrRange(values []float64) (float64, float64) {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	iqr := q3 - q1
+	return q1 - 1.5*iqr, q3 + 1.5*iqr
+}
+
+// nextOutlier returns the next row whose cell of the column col is an
+// outlier
+func (cfg *Config) nextOutlier(row *RowPtr, col int) *RowPtr {
+	for p := row.Next(); p != nil; p = p.Next() {
+		if p.Index() >= cfg.HeaderLines && col < len(p.Cell) && cfg.isOutlier(col, p.Cell[col].Text()) {
+			return p
+		}
+	}
+	return nil
+}
+
+// prevOutlier returns the previous row whose cell of the column col is
+// an outlier
+func (cfg *Config) prevOutlier(row *RowPtr, col int) *RowPtr {
+	for p := row.Prev(); p != nil; p = p.Prev() {
+		if p.Index() >= cfg.HeaderLines && col < len(p.Cell) && cfg.isOutlier(col, p.Cell[col].Text()) {
+			return p
+		}
+	}
+	return nil
+}
+
+func cmdOutliers(e *exCommandArgs) (string, error) {
+	const usage = "usage: outliers [K|iqr|off]"
+	if e.Args == "off" {
+		e.outliers = nil
+		e.view.clearCache()
+		return "outliers off", nil
+	}
+	k := float64(defaultOutlierSigma)
+	if e.Args == "iqr" {
+		k = 0
+	} else if e.Args != "" {
+		var err error
+		if k, err = strconv.ParseFloat(e.Args, 64); err != nil || k <= 0 {
+			return usage, nil
+		}
+	}
+	old := e.outliers
+	r := &outlierRange{col: e.CursorCol, k: k, editCount: e.editCount}
+	e.outliers = r
+	e.updateOutliers()
+	if len(r.values) <= 0 {
+		e.outliers = old
+		return "outliers: no numbers in the current column", nil
+	}
+	e.view.clearCache()
+	count := 0
+	for _, v := range r.values {
+		if v < r.lo || v > r.hi {
+			count++
+		}
+	}
+	message := fmt.Sprintf("%d outlier(s) out of %s..%s", count,
+		formatSummaryNumber(r.lo), formatSummaryNumber(r.hi))
+	if e.loading() {
+		message += " in the rows loaded so far"
+	}
+	return message + " (* and # move to them)", nil
+}
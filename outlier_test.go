@@ -0,0 +1,64 @@
+package csvi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOutliers(t *testing.T) {
+	var lnums []int
+	cfg := &Config{
+		HeaderLines: 1,
+		KeyMap: map[string]func(*KeyEventArgs) (*CommandResult, error){
+			"@": func(e *KeyEventArgs) (*CommandResult, error) {
+				lnums = append(lnums, e.CursorRow.Index())
+				return &CommandResult{}, nil
+			},
+		},
+	}
+	data := "id,n\na,5\nb,5\nc,100\nd,5\ne,5\nf,5\ng,5\nh,5\ni,5\nj,-90\n"
+	_, out := runEdit(t, cfg, "l|:|outliers 2|*|@|*|#|@|q|y", data)
+	if fmt.Sprint(lnums) != "[3 3]" {
+		t.Fatalf("expect the cursor on the row 3, but %v", lnums)
+	}
+	if !strings.Contains(out, outlierStyle+"100\x1B") {
+		t.Fatalf("the outlier is not highlighted: %q", out)
+	}
+	if strings.Contains(out, outlierStyle+"5\x1B") {
+		t.Fatalf("the other cells are highlighted: %q", out)
+	}
+}
+
+func TestOutliersFollowRows(t *testing.T) {
+	var lnums []int
+	cfg := &Config{
+		HeaderLines: 1,
+		InitialRows: 4,
+		KeyMap: map[string]func(*KeyEventArgs) (*CommandResult, error){
+			"@": func(e *KeyEventArgs) (*CommandResult, error) {
+				lnums = append(lnums, e.CursorRow.Index())
+				return &CommandResult{}, nil
+			},
+		},
+	}
+	// 100 is not loaded on `:outliers` and not an outlier after the edit
+	data := "id,n\na,5\nb,6\nc,5\nd,6\ne,5\nf,6\ng,5\nh,100\ni,5\n"
+	_, out := runEdit(t, cfg, "l|:|outliers 2|*|@|r|5|g|l|*|@|q|y", data)
+	if fmt.Sprint(lnums) != "[8 0]" {
+		t.Fatalf("expect the cursor on the row 8 and then 0, but %v", lnums)
+	}
+	if !strings.Contains(out, "no outliers below") {
+		t.Fatalf("the range is not updated by the edit: %q", out)
+	}
+}
+
+func TestOutlierRange(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	if lo, hi := iqrRange(values); lo != -2.5 || hi != 11.5 {
+		t.Fatalf("expect -2.5..11.5, but %g..%g", lo, hi)
+	}
+	if lo, hi := sigmaRange([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 2); lo != 1 || hi != 9 {
+		t.Fatalf("expect 1..9, but %g..%g", lo, hi)
+	}
+}
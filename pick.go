@@ -0,0 +1,69 @@
+package csvi
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// checkMark is drawn in the gutter of the rows checked in MultiPick
+const checkMark = "✓"
+
+// gutterWidth returns the width of the columns drawn at the left of the
+// rows: the check marks in MultiPick and the commits by `:blame`
+func (cfg *Config) gutterWidth() int {
+	w := 0
+	if cfg.MultiPick {
+		w += runewidth.StringWidth(checkMark) + 1
+	}
+	if cfg.blame != nil {
+		w += blameWidth
+	}
+	return w
+}
+
+// gutter returns the text drawn in the gutter of the row. row is nil for
+// the lines other than the first one of the row
+func (cfg *Config) gutter(row *uncsv.Row) string {
+	var buffer strings.Builder
+	if cfg.MultiPick {
+		w := runewidth.StringWidth(checkMark) + 1
+		if row != nil && cfg.checked[row] {
+			buffer.WriteString(checkMark + strings.Repeat(" ", w-runewidth.StringWidth(checkMark)))
+		} else {
+			buffer.WriteString(strings.Repeat(" ", w))
+		}
+	}
+	if cfg.blame != nil {
+		buffer.WriteString(cfg.blameGutter(row))
+	}
+	return buffer.String()
+}
+
+// toggleCheck checks the row, or unchecks it when it is checked
+func (cfg *Config) toggleCheck(row *uncsv.Row) {
+	if cfg.checked == nil {
+		cfg.checked = map[*uncsv.Row]bool{}
+	}
+	if cfg.checked[row] {
+		delete(cfg.checked, row)
+	} else {
+		cfg.checked[row] = true
+	}
+}
+
+// checkedRows returns the rows checked in the order of the data
+func (app *_Application) checkedRows() []*uncsv.Row {
+	if len(app.checked) <= 0 {
+		return nil
+	}
+	var rows []*uncsv.Row
+	for p := app.Front(); p != nil; p = p.Next() {
+		if app.checked[p.Row] {
+			rows = append(rows, p.Row)
+		}
+	}
+	return rows
+}
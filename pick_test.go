@@ -0,0 +1,39 @@
+package csvi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPickMode(t *testing.T) {
+	cfg := &Config{
+		HeaderLines: 1,
+		PickMode:    true,
+	}
+	result, _ := runEdit(t, cfg, "x|j|l|\r", "name,value\na,1\nb,2\n")
+	if result.Picked == nil || result.Picked.Cell[0].Text() != "a" || result.PickedCell != "1" {
+		t.Fatalf("expect the row a and the cell 1 picked, but %v,%q", result.Picked, result.PickedCell)
+	}
+	if result.Modified() {
+		t.Fatal("the data is edited in the pick mode")
+	}
+}
+
+func TestMultiPick(t *testing.T) {
+	cfg := &Config{
+		HeaderLines: 1,
+		MultiPick:   true,
+	}
+	result, out := runEdit(t, cfg, "j| | |k| |k|x|\r", "name\na\nb\nc\n")
+	var names []string
+	for _, row := range result.Checked {
+		names = append(names, row.Cell[0].Text())
+	}
+	if fmt.Sprint(names) != "[a]" {
+		t.Fatalf("expect [a] checked, but %v", names)
+	}
+	if !strings.Contains(out, "✓ ") {
+		t.Fatalf("the check mark is not drawn: %q", out)
+	}
+}
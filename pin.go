@@ -0,0 +1,68 @@
+package csvi
+
+import (
+	"fmt"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["pin"] = &exCommand{
+		help: "pin the current column at the left end while it is scrolled out, or unpin it (same as P)",
+		run: func(e *exCommandArgs) (string, error) {
+			return e.togglePin(e.CursorCol), nil
+		},
+	}
+}
+
+// togglePin pins the column col, or unpins the column pinned
+func (cfg *Config) togglePin(col int) string {
+	if cfg.pinned {
+		cfg.pinned = false
+		return fmt.Sprintf("unpinned the column %d", cfg.pinnedCol+1)
+	}
+	cfg.pinned = true
+	cfg.pinnedCol = col
+	return fmt.Sprintf("pinned the column %d", col+1)
+}
+
+// pinShown returns true when the pinned column is scrolled out to the
+// left of startCol, and so its cell is drawn at the left end of the rows.
+func (cfg *Config) pinShown(startCol int) bool {
+	return cfg.pinned && cfg.pinnedCol < startCol
+}
+
+// cellsFrom returns the cells drawn on a screen line when the columns
+// are drawn from startCol
+func (cfg *Config) cellsFrom(cells []uncsv.Cell, startCol int) []uncsv.Cell {
+	rest := cellsAfter(cells, startCol)
+	if !cfg.pinShown(startCol) {
+		return rest
+	}
+	var label uncsv.Cell
+	if cfg.pinnedCol < len(cells) {
+		label = cells[cfg.pinnedCol]
+	}
+	return append([]uncsv.Cell{label}, rest...)
+}
+
+// columnAt returns the index of the column of the i-th cell returned by
+// cellsFrom(cells, startCol)
+func (cfg *Config) columnAt(startCol, i int) int {
+	if cfg.pinShown(startCol) {
+		if i == 0 {
+			return cfg.pinnedCol
+		}
+		return startCol + i - 1
+	}
+	return startCol + i
+}
+
+// screenCol returns the position of the column col in the cells returned
+// by cellsFrom
+func (cfg *Config) screenCol(col, startCol int) int {
+	if cfg.pinShown(startCol) {
+		return col - startCol + 1
+	}
+	return col - startCol
+}
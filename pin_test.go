@@ -0,0 +1,35 @@
+package csvi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPinColumn(t *testing.T) {
+	var data strings.Builder
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 12; c++ {
+			if c > 0 {
+				data.WriteByte(',')
+			}
+			fmt.Fprintf(&data, "r%dc%d", r, c)
+		}
+		data.WriteByte('\n')
+	}
+	var frames strings.Builder
+	cfg := &Config{
+		HeaderLines:   1,
+		CaptureFrames: &frames,
+	}
+	runEdit(t, cfg, "P|j|$|q|y", data.String())
+	parts := strings.Split(frames.String(), "--- frame ")
+	last := regexp.MustCompile("\x1B\\[[0-9;?]*[A-Za-z]").ReplaceAllString(parts[len(parts)-2], "")
+	if !strings.Contains(last, "\rr0c0r0c8r0c9") || !strings.Contains(last, "\nr1c0r1c8r1c9") {
+		t.Fatalf("the pinned column is not drawn at the left: %q", last)
+	}
+	if !strings.Contains(last, "r1c11") {
+		t.Fatalf("the cursor column is not drawn: %q", last)
+	}
+}
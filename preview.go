@@ -0,0 +1,70 @@
+package csvi
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// wrapInWidth splits s into lines whose width is at most width.
+func wrapInWidth(s string, width int) []string {
+	var lines []string
+	for s != "" {
+		line, _ := cutStrInWidth(s, width)
+		if line == "" {
+			// a character wider than the screen
+			break
+		}
+		lines = append(lines, line)
+		s = s[len(line):]
+	}
+	return lines
+}
+
+// printPreview prints the whole text of the current cell wrapped in
+// Config.PreviewLines lines under the status line, and moves the cursor
+// back to the status line.
+func (app *_Application) printPreview(cursorRow *RowPtr, cursorCol, screenWidth int) {
+	n := app.PreviewLines
+	if n <= 0 {
+		return
+	}
+	var lines []string
+	if 0 <= cursorCol && cursorCol < len(cursorRow.Cell) {
+		text := cursorRow.Cell[cursorCol].Text()
+		if app.NormalizeNFC {
+			text = norm.NFC.String(text)
+		}
+		text = app.replaceControls(app.escapeBidi(text))
+		lines = wrapInWidth(text, screenWidth-1)
+	}
+	if len(lines) > n {
+		last, _ := cutStrInWidth(lines[n-1], screenWidth-2)
+		lines[n-1] = last + "…"
+	}
+	for i := 0; i < n; i++ {
+		io.WriteString(app.out, "\r\n")
+		if i < len(lines) {
+			io.WriteString(app.out, lines[i])
+		}
+		io.WriteString(app.out, _ANSI_ERASE_LINE)
+	}
+	fmt.Fprintf(app.out, "\r\x1B[%dA", n)
+}
+
+// printCellSource shows the source text of the cell with its quotes and
+// escapes, and the original one if modified, on the line above the status
+// line. The line is repainted after editing because the view cache is cleared.
+func (app *_Application) printCellSource(cell uncsv.Cell, screenWidth int) {
+	text := "source: " + cell.SourceText(app.Mode)
+	if cell.Modified() {
+		text += "  original: " + cell.OriginalText(app.Mode)
+	}
+	io.WriteString(app.out, "\r\x1B[A"+_ANSI_YELLOW)
+	io.WriteString(app.out, runewidth.Truncate(app.replaceControls(app.escapeBidi(text)), screenWidth-1, ""))
+	io.WriteString(app.out, _ANSI_ERASE_LINE+"\r\x1B[B")
+}
@@ -0,0 +1,90 @@
+package csvi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// progressBlocks are the blocks filling a character of a progress bar by
+// eighths
+var progressBlocks = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// updateProgressColumns resolves Config.ProgressColumns with the current
+// header into progressCols.
+func (app *_Application) updateProgressColumns() {
+	if len(app.ProgressColumns) <= 0 {
+		app.progressCols = nil
+		return
+	}
+	cols := map[int]bool{}
+	for _, name := range app.ProgressColumns {
+		if col, err := app.columnIndex(name); err == nil {
+			cols[col] = true
+		}
+	}
+	app.progressCols = cols
+}
+
+// parsePercent returns the number of text like `42` or `42.5%` clamped
+// from 0 to 100
+func parsePercent(text string) (float64, bool) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "%")), 64)
+	if err != nil {
+		return 0, false
+	}
+	return min(max(value, 0), 100), true
+}
+
+// fullBlock returns the character filling a cell of bars: `#` when the
+// blocks are East Asian Ambiguous characters drawn in two cells
+func fullBlock() string {
+	if runewidth.StringWidth("█") != 1 {
+		return "#"
+	}
+	return "█"
+}
+
+// progressBar returns the bar of the percentage drawn in width with the
+// number at the right end. When width is too narrow for the bar, it
+// returns only the number.
+func progressBar(percent float64, width int) string {
+	label := fmt.Sprintf("%3.0f%%", percent)
+	barWidth := width - len(label) - 1
+	if barWidth <= 0 {
+		return label
+	}
+	full, part := fullBlock(), progressBlocks
+	if full != "█" {
+		part = nil
+	}
+	eighths := int(percent / 100 * float64(barWidth*8))
+	n := eighths / 8
+	bar := strings.Repeat(full, n)
+	if rest := eighths % 8; rest > 0 && len(part) > 0 {
+		bar += part[rest]
+		n++
+	}
+	return bar + strings.Repeat(" ", barWidth-n+1) + label
+}
+
+// progressText returns the progress bar drawn instead of the text of the
+// cell by OnCellProgress, or of the cell of the column col in
+// ProgressColumns. It returns false when the cell is not a progress bar.
+func (cfg *Config) progressText(col int, text string, width int) (string, bool) {
+	if cfg.OnCellProgress != nil {
+		if fraction, ok := cfg.OnCellProgress(cfg.cellEvent(col, text)); ok {
+			return progressBar(min(max(fraction, 0), 1)*100, width), true
+		}
+	}
+	if !cfg.progressCols[col] {
+		return "", false
+	}
+	percent, ok := parsePercent(text)
+	if !ok {
+		return "", false
+	}
+	return progressBar(percent, width), true
+}
@@ -0,0 +1,57 @@
+package csvi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOnCellProgress(t *testing.T) {
+	var texts []string
+	cfg := &Config{
+		HeaderLines: 1,
+		CellWidth:   12,
+		OnCellProgress: func(e *CellStyleEvent) (float64, bool) {
+			texts = append(texts, e.Text)
+			var done, total float64
+			if _, err := fmt.Sscanf(e.Text, "%g/%g", &done, &total); err != nil || total <= 0 {
+				return 0, false
+			}
+			return done / total, true
+		},
+	}
+	_, out := runEdit(t, cfg, "q|y", "job,status\nA,3/4\nB,failed\n")
+	for _, expect := range []string{" 75%", "failed"} {
+		if !strings.Contains(out, expect) {
+			t.Fatalf("%q is not drawn: %q", expect, out)
+		}
+	}
+	if slices.Contains(texts, "status") {
+		t.Fatalf("called for the header: %q", texts)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	for _, c := range []struct {
+		text   string
+		width  int
+		expect string
+	}{
+		{text: "50", width: 13, expect: "████      50%"},
+		{text: "30%", width: 9, expect: "█▏    30%"},
+		{text: "120", width: 10, expect: "█████ 100%"},
+		{text: "7", width: 4, expect: "  7%"},
+	} {
+		percent, ok := parsePercent(c.text)
+		if !ok {
+			t.Fatalf("%q: not parsed", c.text)
+		}
+		if result := progressBar(percent, c.width); result != c.expect {
+			t.Fatalf("progressBar(%q,%d): expect %q but %q", c.text, c.width, c.expect, result)
+		}
+	}
+	if _, ok := parsePercent("x"); ok {
+		t.Fatal("x is parsed as a percentage")
+	}
+}
@@ -0,0 +1,51 @@
+package csvi
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// Values of Config.PseudoHeader
+const (
+	PseudoHeaderLetter = "letter"
+	PseudoHeaderFirst  = "first"
+)
+
+// columnLetter returns the name of the column like spreadsheets: A, B, ... Z, AA, AB ...
+func columnLetter(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// headerHeight returns the number of lines of the header drawn
+func (cfg *Config) headerHeight() int {
+	if cfg.HeaderLines <= 0 && cfg.PseudoHeader != "" {
+		return 1
+	}
+	return cfg.HeaderLines
+}
+
+// pseudoHeader makes the row drawn as the header when HeaderLines is zero.
+// It does not belong to the data.
+func (cfg *Config) pseudoHeader(front, cursorRow *RowPtr) *uncsv.Row {
+	if cfg.PseudoHeader == PseudoHeaderFirst {
+		return front.Row
+	}
+	n := max(len(front.Cell), len(cursorRow.Cell))
+	var buffer bytes.Buffer
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buffer.WriteByte(cfg.Mode.Comma)
+		}
+		buffer.WriteString(columnLetter(i))
+	}
+	// Read as data so that the cells are not drawn as modified ones
+	mode := &uncsv.Mode{Comma: cfg.Mode.Comma}
+	row, _ := uncsv.ReadLine(bufio.NewReader(&buffer), mode)
+	return row
+}
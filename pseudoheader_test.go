@@ -0,0 +1,13 @@
+package csvi
+
+import (
+	"testing"
+)
+
+func TestColumnLetter(t *testing.T) {
+	for i, expect := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if result := columnLetter(i); result != expect {
+			t.Fatalf("columnLetter(%d): expect %s but %s", i, expect, result)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package csvi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/nyaosorg/go-readline-ny"
+)
+
+// pilotEvent is a line of the log written by Config.Record
+type pilotEvent struct {
+	// Op is one of "key", "line", "file", "size" and "calibrate"
+	Op     string `json:"op"`
+	Text   string `json:"text,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Err    string `json:"err,omitempty"`
+}
+
+// recorder is the Pilot writing the events of the Pilot wrapped to w
+type recorder struct {
+	Pilot
+	enc           *json.Encoder
+	width, height int
+}
+
+func newRecorder(pilot Pilot, w io.Writer) *recorder {
+	return &recorder{Pilot: pilot, enc: json.NewEncoder(w)}
+}
+
+func (r *recorder) write(op, text string, err error) {
+	e := &pilotEvent{Op: op, Text: text}
+	if err != nil {
+		e.Err = err.Error()
+	}
+	r.enc.Encode(e)
+}
+
+// Size records the size only when it changes
+func (r *recorder) Size() (int, int, error) {
+	w, h, err := r.Pilot.Size()
+	if err == nil && (w != r.width || h != r.height) {
+		r.width, r.height = w, h
+		r.enc.Encode(&pilotEvent{Op: "size", Width: w, Height: h})
+	}
+	return w, h, err
+}
+
+func (r *recorder) Calibrate() error {
+	err := r.Pilot.Calibrate()
+	if err == nil {
+		r.enc.Encode(&pilotEvent{Op: "calibrate", Width: runewidth.StringWidth("▽")})
+	}
+	return err
+}
+
+func (r *recorder) GetKey() (string, error) {
+	key, err := r.Pilot.GetKey()
+	r.write("key", key, err)
+	return key, err
+}
+
+func (r *recorder) ReadLine(out io.Writer, prompt, text string, c Candidate) (string, error) {
+	line, err := r.Pilot.ReadLine(out, prompt, text, c)
+	r.write("line", line, err)
+	return line, err
+}
+
+func (r *recorder) GetFilename(out io.Writer, prompt, name string) (string, error) {
+	fname, err := r.Pilot.GetFilename(out, prompt, name)
+	r.write("file", fname, err)
+	return fname, err
+}
+
+// replayPilot is the Pilot giving the events recorded by Config.Record
+type replayPilot struct {
+	events        []*pilotEvent
+	width, height int
+}
+
+// NewReplayPilot returns the Pilot which gives the keys, the lines and
+// the screen sizes in the log written by Config.Record in the same order,
+// so that the session can be reproduced.
+func NewReplayPilot(r io.Reader) (Pilot, error) {
+	p := &replayPilot{width: 80, height: 25}
+	dec := json.NewDecoder(r)
+	for {
+		var e pilotEvent
+		if err := dec.Decode(&e); err == io.EOF {
+			return p, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("replay: %w", err)
+		}
+		p.events = append(p.events, &e)
+	}
+}
+
+// skipSizes applies the size events at the top
+func (p *replayPilot) skipSizes() {
+	for len(p.events) > 0 && p.events[0].Op == "size" {
+		p.width, p.height = p.events[0].Width, p.events[0].Height
+		p.events = p.events[1:]
+	}
+}
+
+func (p *replayPilot) next(op string) (string, error) {
+	p.skipSizes()
+	if len(p.events) <= 0 {
+		return "", io.EOF
+	}
+	e := p.events[0]
+	if e.Op != op {
+		return "", fmt.Errorf("replay: %s is expected but %s is recorded", op, e.Op)
+	}
+	p.events = p.events[1:]
+	switch e.Err {
+	case "":
+		return e.Text, nil
+	case io.EOF.Error():
+		return e.Text, io.EOF
+	case readline.CtrlC.Error():
+		return e.Text, readline.CtrlC
+	default:
+		return e.Text, errors.New(e.Err)
+	}
+}
+
+func (p *replayPilot) Size() (int, int, error) {
+	p.skipSizes()
+	return p.width, p.height, nil
+}
+
+// Calibrate sets the width of East Asian Ambiguous characters recorded
+func (p *replayPilot) Calibrate() error {
+	p.skipSizes()
+	if len(p.events) > 0 && p.events[0].Op == "calibrate" {
+		runewidth.DefaultCondition.EastAsianWidth = p.events[0].Width >= 2
+		p.events = p.events[1:]
+	}
+	return nil
+}
+
+func (p *replayPilot) GetKey() (string, error) {
+	return p.next("key")
+}
+
+func (p *replayPilot) ReadLine(io.Writer, string, string, Candidate) (string, error) {
+	return p.next("line")
+}
+
+func (p *replayPilot) GetFilename(io.Writer, string, string) (string, error) {
+	return p.next("file")
+}
+
+func (p *replayPilot) Close() error {
+	return nil
+}
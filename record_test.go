@@ -0,0 +1,32 @@
+package csvi
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	var log strings.Builder
+	source := "a,b\nc,d\n"
+	result, _ := runEdit(t, &Config{Record: &log}, "j|r|X|q|y", source)
+	var expect strings.Builder
+	result.Dump(&expect)
+
+	pilot, err := NewReplayPilot(strings.NewReader(log.String()))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cfg := Config{Mode: &uncsv.Mode{Comma: ','}, Pilot: pilot}
+	result, err = cfg.Edit(strings.NewReader(source), io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var replayed strings.Builder
+	result.Dump(&replayed)
+	if expect.String() != "a,b\nX,d\n" || replayed.String() != expect.String() {
+		t.Fatalf("expect %q but %q", expect.String(), replayed.String())
+	}
+}
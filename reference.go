@@ -0,0 +1,51 @@
+package csvi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+)
+
+func init() {
+	exCommands["ref"] = &exCommand{
+		help: "copy the reference of the current cell like file.csv:123:4, or D123 with a1, to the clipboard (ref [a1])",
+		run:  cmdReference,
+	}
+}
+
+// cellReference returns the reference of the cell like `file.csv:123:4`
+// with the 1-based line number in the file and column number, or `D123`
+// with the row number when a1 is true.
+func (app *_Application) cellReference(row *RowPtr, col int, a1 bool) string {
+	if a1 {
+		return columnLetter(col) + strconv.Itoa(row.Index()+1)
+	}
+	name := "-"
+	if app.Filename != "" {
+		name = filepath.Base(app.Filename)
+	}
+	return fmt.Sprintf("%s:%d:%d", name, app.fileLine(row), col+1)
+}
+
+// setClipboard sets text to the clipboard of the terminal with OSC 52.
+// Terminals not supporting it ignore the sequence.
+func setClipboard(w io.Writer, text string) {
+	fmt.Fprintf(w, "\x1B]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+}
+
+func cmdReference(e *exCommandArgs) (string, error) {
+	var a1 bool
+	switch e.Args {
+	case "":
+	case "a1", "A1":
+		a1 = true
+	default:
+		return "usage: ref [a1]", nil
+	}
+	ref := e.cellReference(e.CursorRow, e.CursorCol, a1)
+	e.registers[registerYank] = ref
+	setClipboard(e.out, ref)
+	return "copied " + ref, nil
+}
@@ -0,0 +1,40 @@
+package csvi
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestCellReference(t *testing.T) {
+	cfg := &Config{Mode: &uncsv.Mode{Comma: ','}, Filename: filepath.Join("data", "file.csv")}
+	app, err := cfg.readAll(strings.NewReader(strings.Repeat("x\n", 123)), io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	row, err := app.rowAt(123)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if ref := app.cellReference(row, 3, false); ref != "file.csv:123:4" {
+		t.Fatalf("expect file.csv:123:4 but %s", ref)
+	}
+	if ref := app.cellReference(row, 3, true); ref != "D123" {
+		t.Fatalf("expect D123 but %s", ref)
+	}
+
+	// the lines in the cells above are counted, not the rows
+	app, err = cfg.readAll(strings.NewReader("a,\"b\r\nc\nd\"\r\ne,f\n"), io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if ref := app.cellReference(app.Back(), 1, false); ref != "file.csv:4:2" {
+		t.Fatalf("expect file.csv:4:2 but %s", ref)
+	}
+	if ref := app.cellReference(app.Back(), 1, true); ref != "B2" {
+		t.Fatalf("expect B2 but %s", ref)
+	}
+}
@@ -0,0 +1,94 @@
+package csvi
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/nyaosorg/go-readline-ny"
+)
+
+// registers holds the texts inserted into prompts by Ctrl-R and the name
+// of the register like vim. Each session of Edit has its own registers.
+//
+//	/  the last search pattern
+//	"  the text yanked last by y or :ref
+//	.  the text typed last to replace a cell by r
+type registers map[string]string
+
+const (
+	registerSearch  = "/"
+	registerYank    = "\""
+	registerReplace = "."
+)
+
+// insertCommand reads the name of a register and inserts its text
+func (r registers) insertCommand() readline.Command {
+	return readline.NewGoCommand("INSERT_REGISTER",
+		func(ctx context.Context, B *readline.Buffer) readline.Result {
+			key, err := B.GetKey()
+			if err != nil {
+				return readline.CONTINUE
+			}
+			if text, ok := r[key]; ok {
+				B.InsertAndRepaint(text)
+			}
+			return readline.CONTINUE
+		})
+}
+
+// searchCommand moves the cursor to the next occurrence of the last
+// search pattern in the text being edited, so that a long cell can be
+// edited at the text found on the screen.
+func (r registers) searchCommand() readline.Command {
+	return readline.NewGoCommand("SEARCH_IN_LINE",
+		func(ctx context.Context, B *readline.Buffer) readline.Result {
+			cells := make([]string, len(B.Buffer))
+			for i := range B.Buffer {
+				cells[i] = B.SubString(i, i+1)
+			}
+			if pos := nextMatchIn(cells, B.Cursor, r[registerSearch]); pos >= 0 {
+				B.Cursor = pos
+				B.RepaintAfterPrompt()
+			}
+			return readline.CONTINUE
+		})
+}
+
+// nextMatchIn returns the index of the first cell after cursor where
+// pattern starts, searching from the top after the end, or -1.
+// The cells are joined once and searched by their byte offsets.
+func nextMatchIn(cells []string, cursor int, pattern string) int {
+	if pattern == "" {
+		return -1
+	}
+	var buffer strings.Builder
+	offsets := make([]int, len(cells)+1)
+	for i, c := range cells {
+		offsets[i] = buffer.Len()
+		buffer.WriteString(c)
+	}
+	offsets[len(cells)] = buffer.Len()
+	text := buffer.String()
+
+	// find returns the cell where pattern starts in text[from:to] or -1
+	find := func(from, to int) int {
+		for from < to {
+			pos := strings.Index(text[from:], pattern)
+			if pos < 0 || from+pos >= to {
+				return -1
+			}
+			pos += from
+			if i, ok := slices.BinarySearch(offsets, pos); ok {
+				return i
+			}
+			from = pos + 1
+		}
+		return -1
+	}
+	start := offsets[min(cursor+1, len(cells))]
+	if i := find(start, len(text)); i >= 0 {
+		return i
+	}
+	return find(0, start)
+}
@@ -0,0 +1,52 @@
+package csvi
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestRegisters(t *testing.T) {
+	cfg := Config{Mode: &uncsv.Mode{Comma: ','}, Pilot: NewAutoPilot("y|/|b|r|X|q|y")}
+	first, err := cfg.Edit(strings.NewReader("a,b\n"), io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cfg = Config{Mode: &uncsv.Mode{Comma: ','}, Pilot: NewAutoPilot("q|y")}
+	second, err := cfg.Edit(strings.NewReader("a,b\n"), io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expect := "map[\":a .:X /:b]"
+	if r := fmt.Sprint(first.registers); r != expect {
+		t.Fatalf("expect %s but %s", expect, r)
+	}
+	if len(second.registers) != 0 {
+		t.Fatalf("the registers are shared: %v", second.registers)
+	}
+}
+
+func TestNextMatchIn(t *testing.T) {
+	cells := strings.Split("abcabcあいう", "")
+	for _, c := range []struct {
+		cursor  int
+		pattern string
+		expect  int
+	}{
+		{cursor: 9, pattern: "bc", expect: 1},
+		{cursor: 1, pattern: "bc", expect: 4},
+		{cursor: 4, pattern: "bc", expect: 1},
+		{cursor: 0, pattern: "いう", expect: 7},
+		{cursor: 7, pattern: "いう", expect: 7},
+		{cursor: 0, pattern: "\x81\x82", expect: -1},
+		{cursor: 0, pattern: "x", expect: -1},
+		{cursor: 0, pattern: "", expect: -1},
+	} {
+		if result := nextMatchIn(cells, c.cursor, c.pattern); result != c.expect {
+			t.Fatalf("nextMatchIn(%d,%q): expect %d but %d", c.cursor, c.pattern, c.expect, result)
+		}
+	}
+}
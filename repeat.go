@@ -0,0 +1,70 @@
+package csvi
+
+import (
+	"errors"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// editRecord is the last edit repeated by `.`
+type editRecord struct {
+	// key is one of "r" (replace and paste), "i", "a", "d", "D", "o",
+	// "O" and `"`
+	key string
+	// text is the text typed for "r", "i" and "a"
+	text string
+	// cells are the texts of the row inserted by "o" and "O"
+	cells []string
+}
+
+func cellTexts(row *uncsv.Row) []string {
+	texts := make([]string, len(row.Cell))
+	for i, c := range row.Cell {
+		texts[i] = c.Text()
+	}
+	return texts
+}
+
+// repeatEdit applies the edit again at the cell (row,col) and returns
+// the new position of the cursor.
+func (app *_Application) repeatEdit(edit *editRecord, row *RowPtr, col int, fetchAll func() error) (*RowPtr, int, error) {
+	if m := app.editProtected(edit.key, row); m != "" {
+		return row, col, errors.New(m)
+	}
+	switch edit.key {
+	case "r":
+		if col >= len(row.Cell) {
+			return row, col, nil
+		}
+		text, err := app.validate(row, col, edit.text)
+		if err != nil {
+			return row, col, err
+		}
+		app.replaceCell(row, col, text)
+	case "i", "a":
+		// validated at the column of the new cell as typed by "i" and "a"
+		target := col
+		if edit.key == "a" {
+			target++
+		}
+		text, err := app.validate(row, target, edit.text)
+		if err != nil {
+			return row, col, err
+		}
+		col = app.insertCell(row, col, text, edit.key == "a")
+	case "d":
+		app.deleteCell(row, col)
+	case "D":
+		row, _ = app.deleteRow(row)
+	case "o", "O":
+		newRow, err := app.newRowFor(row, edit.cells, fetchAll)
+		if err != nil {
+			return row, col, err
+		}
+		row = app.insertRow(row, newRow, edit.key == "o")
+		app.auditRow("insert-row", row, row.Row)
+	case `"`:
+		app.toggleQuote(row, col)
+	}
+	return row, col, nil
+}
@@ -0,0 +1,90 @@
+package csvi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestRepeatEdit(t *testing.T) {
+	cfg := &Config{Mode: &uncsv.Mode{Comma: ','}}
+	app, err := cfg.readAll(strings.NewReader("a,b\nc,d\ne,f\n"), io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	row := app.Front()
+	for _, edit := range []*editRecord{
+		{key: "r", text: "x,y"},
+		{key: "a", text: "z"},
+		{key: "o", cells: []string{"g", "h"}},
+		{key: "D"},
+	} {
+		row, _, err = app.repeatEdit(edit, row, 0, func() error { return nil })
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	var out strings.Builder
+	dump(app, &out)
+	expect := "\"x,y\",z,b\nc,d\ne,f\n"
+	if out.String() != expect {
+		t.Fatalf("expect %q but %q", expect, out.String())
+	}
+}
+
+func TestRepeatEditValidated(t *testing.T) {
+	var cols []int
+	cfg := &Config{
+		Mode: &uncsv.Mode{Comma: ','},
+		OnCellValidated: func(e *CellValidatedEvent) (string, error) {
+			cols = append(cols, e.Col)
+			if e.Text == "bad" {
+				return "", errors.New("rejected")
+			}
+			return strings.ToUpper(e.Text), nil
+		},
+	}
+	app, err := cfg.readAll(strings.NewReader("a,b\n"), io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	row := app.Front()
+	if _, _, err := app.repeatEdit(&editRecord{key: "i", text: "bad"}, row, 0, nil); err == nil {
+		t.Fatal("expect the error of OnCellValidated on repeating i")
+	}
+	if _, _, err := app.repeatEdit(&editRecord{key: "a", text: "z"}, row, 0, nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	var out strings.Builder
+	dump(app, &out)
+	if expect := "a,Z,b\n"; out.String() != expect {
+		t.Fatalf("expect %q but %q", expect, out.String())
+	}
+	if fmt.Sprint(cols) != "[0 1]" {
+		t.Fatalf("expect validated at the columns [0 1], but %v", cols)
+	}
+}
+
+func TestRepeatKeys(t *testing.T) {
+	for script, expect := range map[string]string{
+		"a|z|j|.|q|y":      "a,z,b\nc,d,z\n",
+		"l|i|z|j|.|q|y":    "a,z,b\nc,z,d\n",
+		"r|z|j|l|.|q|y":    "z,b\nc,z\n",
+		"\"|j|.|d|j|.|q|y": "\"a\",b\n\n",
+	} {
+		cfg := Config{Mode: &uncsv.Mode{Comma: ','}, Pilot: NewAutoPilot(script)}
+		result, err := cfg.Edit(strings.NewReader("a,b\nc,d\n"), io.Discard)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		var out strings.Builder
+		result.Dump(&out)
+		if out.String() != expect {
+			t.Fatalf("%s: expect %q but %q", script, expect, out.String())
+		}
+	}
+}
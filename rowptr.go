@@ -1,112 +1,403 @@
 package csvi
 
 import (
-	"container/list"
+	"bufio"
 	"io"
+	"math"
+	"slices"
 
 	"github.com/hymkor/csvi/uncsv"
 )
 
+// rowChunkSize is the number of rows in a chunk of rowList.
+// A chunk grows up to twice of it by insertions and is split then.
+const rowChunkSize = 1024
+
+// maxRowEdits is the number of the edits of rowList kept for RowPtr.
+// The older ones are trimmed and the pointers older than them search
+// their rows instead.
+const maxRowEdits = 4096
+
+// rowEdit is the insertion (delta=1) or the removal (delta=-1) of a row
+// at lnum
+type rowEdit struct {
+	lnum  int
+	delta int
+}
+
+// rowList holds rows in chunks of slices instead of a linked list
+// to reduce pointer-chasing and the overhead of GC.
+// edits are the insertions and the removals of rows except at the end.
+// base is the version of edits[0], and base plus their number is the
+// version. RowPtr of an older version replays the rest of them to keep
+// pointing its row as a linked list would.
+// terms counts the rows by their terminators.
+// While spill is set, the chunks far from the cursor are evicted.
+type rowList struct {
+	chunks [][]*uncsv.Row
+	length int
+	edits  []rowEdit
+	base   int
+	terms  map[string]int
+	spill  *rowSpill
+}
+
+// rowSpill reads the rows of the evicted chunks, whose slices are nil,
+// again from source. offsets are those of the first rows of the chunks
+// and sizes are the numbers of the rows of the evicted ones. next is the
+// offset of the row pushed next, or -1 when it is not read from source.
+// The chunks more than keep chunks away from the chunk of the cursor are
+// evicted as the rows are pushed.
+type rowSpill struct {
+	source  io.ReaderAt
+	read    func(*bufio.Reader) (*uncsv.Row, error)
+	next    int64
+	offsets []int64
+	sizes   []int
+	cursor  int
+	keep    int
+}
+
+func newRowList() *rowList {
+	return &rowList{terms: map[string]int{}}
+}
+
+func (L *rowList) Len() int {
+	return L.length
+}
+
+func (L *rowList) version() int {
+	return L.base + len(L.edits)
+}
+
+// logEdit appends e to edits and trims the oldest ones beyond maxRowEdits
+func (L *rowList) logEdit(e rowEdit) {
+	L.edits = append(L.edits, e)
+	if len(L.edits) >= maxRowEdits*2 {
+		n := len(L.edits) - maxRowEdits
+		L.edits = slices.Clone(L.edits[n:])
+		L.base += n
+	}
+}
+
+// index returns the line number of row, or lnum when it is not found
+func (L *rowList) index(row *uncsv.Row, lnum int) int {
+	n := 0
+	for c := range L.chunks {
+		L.load(c)
+		if o := slices.Index(L.chunks[c], row); o >= 0 {
+			return n + o
+		}
+		n += len(L.chunks[c])
+	}
+	return lnum
+}
+
+// chunkLen returns the number of the rows of the chunk c
+func (L *rowList) chunkLen(c int) int {
+	if L.chunks[c] == nil && L.spill != nil {
+		return L.spill.sizes[c]
+	}
+	return len(L.chunks[c])
+}
+
+// locate returns the chunk and the offset of the row numbered lnum
+func (L *rowList) locate(lnum int) (int, int, bool) {
+	if lnum < 0 || lnum >= L.length {
+		return 0, 0, false
+	}
+	for c := range L.chunks {
+		if n := L.chunkLen(c); lnum >= n {
+			lnum -= n
+		} else {
+			return c, lnum, true
+		}
+	}
+	return 0, 0, false
+}
+
+// load reads the rows of the chunk c again when it is evicted. The rows
+// which can not be read because the file is changed are left empty.
+func (L *rowList) load(c int) {
+	if L.chunks[c] != nil || L.spill == nil {
+		return
+	}
+	s := L.spill
+	reader := bufio.NewReader(io.NewSectionReader(s.source, s.offsets[c], math.MaxInt64-s.offsets[c]))
+	chunk := make([]*uncsv.Row, s.sizes[c])
+	i := 0
+	for i < len(chunk) {
+		row, err := s.read(reader)
+		if row == nil || (err != nil && err != io.EOF) {
+			break
+		}
+		chunk[i] = row
+		i++
+		if err == io.EOF {
+			break
+		}
+	}
+	for ; i < len(chunk); i++ {
+		chunk[i] = &uncsv.Row{Cell: []uncsv.Cell{{}}}
+	}
+	L.chunks[c] = chunk
+}
+
+// evict drops the rows of the chunk c, which are read again by load.
+// The first chunk, which may start with BOM, and the last chunk, where
+// the rows are pushed, are not evicted.
+func (L *rowList) evict(c int) {
+	if L.spill == nil || L.chunks[c] == nil || c == 0 || c >= len(L.chunks)-1 {
+		return
+	}
+	L.spill.sizes[c] = len(L.chunks[c])
+	L.chunks[c] = nil
+}
+
+// unspill reads all the chunks evicted and stops evicting them
+func (L *rowList) unspill() {
+	if L.spill == nil {
+		return
+	}
+	for c := range L.chunks {
+		L.load(c)
+	}
+	L.spill = nil
+}
+
+func (L *rowList) PushBack(row *uncsv.Row) {
+	if s := L.spill; s != nil && s.next < 0 {
+		// the row not read from the source can not be read again
+		L.unspill()
+	}
+	if n := len(L.chunks); n > 0 && len(L.chunks[n-1]) < rowChunkSize {
+		L.chunks[n-1] = append(L.chunks[n-1], row)
+	} else {
+		chunk := make([]*uncsv.Row, 1, rowChunkSize)
+		chunk[0] = row
+		L.chunks = append(L.chunks, chunk)
+		if s := L.spill; s != nil {
+			s.offsets = append(s.offsets, s.next)
+			s.sizes = append(s.sizes, 0)
+			if c := n - 1; c > s.cursor+s.keep {
+				L.evict(c)
+			}
+		}
+	}
+	if L.spill != nil {
+		L.spill.next = -1
+	}
+	L.length++
+	L.terms[row.Term]++
+}
+
+// insert inserts the row at the offset o of the chunk c, whose number is lnum
+func (L *rowList) insert(c, o, lnum int, row *uncsv.Row) {
+	L.unspill()
+	chunk := slices.Insert(L.chunks[c], o, row)
+	if len(chunk) >= rowChunkSize*2 {
+		half := len(chunk) / 2
+		second := slices.Clone(chunk[half:])
+		L.chunks[c] = chunk[:half:half]
+		L.chunks = slices.Insert(L.chunks, c+1, second)
+	} else {
+		L.chunks[c] = chunk
+	}
+	L.length++
+	L.logEdit(rowEdit{lnum: lnum, delta: 1})
+	L.terms[row.Term]++
+}
+
+// remove removes the row at the offset o of the chunk c, whose number is lnum
+func (L *rowList) remove(c, o, lnum int) *uncsv.Row {
+	L.unspill()
+	row := L.chunks[c][o]
+	L.chunks[c] = slices.Delete(L.chunks[c], o, o+1)
+	if len(L.chunks[c]) <= 0 {
+		L.chunks = slices.Delete(L.chunks, c, c+1)
+	}
+	L.length--
+	L.logEdit(rowEdit{lnum: lnum, delta: -1})
+	L.terms[row.Term]--
+	return row
+}
+
+// RowPtr points a row by its line number lnum.
+// chunk and offset are the cache of the position for lnum and are valid
+// while version equals to that of list.
 type RowPtr struct {
 	*uncsv.Row
-	element *list.Element
 	lnum    int
-	list    *list.List
+	list    *rowList
+	chunk   int
+	offset  int
+	version int
+}
+
+func (L *rowList) ptr(c, o, lnum int) *RowPtr {
+	L.load(c)
+	return &RowPtr{
+		Row:     L.chunks[c][o],
+		lnum:    lnum,
+		list:    L,
+		chunk:   c,
+		offset:  o,
+		version: L.version(),
+	}
+}
+
+// resolve updates lnum and the cached position by the insertions and the
+// removals after the version of r. When r.Row has been removed, r points
+// the row which took its place, or the last row. When the edits after
+// the version of r are trimmed, r.Row is searched instead. When all rows
+// are removed, r.Row is nil.
+func (r *RowPtr) resolve() {
+	L := r.list
+	if r.version == L.version() {
+		return
+	}
+	if r.version < L.base {
+		r.lnum = L.index(r.Row, r.lnum)
+	} else {
+		for _, e := range L.edits[r.version-L.base:] {
+			if e.lnum < r.lnum || (e.delta > 0 && e.lnum == r.lnum) {
+				r.lnum += e.delta
+			}
+		}
+	}
+	r.version = L.version()
+	if c, o, ok := L.locate(min(r.lnum, L.length-1)); ok {
+		r.chunk, r.offset = c, o
+		r.lnum = min(r.lnum, L.length-1)
+		L.load(c)
+		r.Row = L.chunks[c][o]
+	} else {
+		r.chunk, r.offset, r.lnum = 0, 0, 0
+		r.Row = nil
+	}
 }
 
 func (r *RowPtr) Next() *RowPtr {
-	next := r.element.Next()
-	if next == nil {
+	r.resolve()
+	if r.Row == nil {
 		return nil
 	}
-	return &RowPtr{Row: next.Value.(*uncsv.Row), element: next, lnum: r.lnum + 1, list: r.list}
+	L := r.list
+	if r.offset+1 < L.chunkLen(r.chunk) {
+		return L.ptr(r.chunk, r.offset+1, r.lnum+1)
+	}
+	if r.chunk+1 < len(L.chunks) {
+		return L.ptr(r.chunk+1, 0, r.lnum+1)
+	}
+	return nil
 }
 
 func (r *RowPtr) Prev() *RowPtr {
-	prev := r.element.Prev()
-	if prev == nil {
+	r.resolve()
+	if r.Row == nil {
 		return nil
 	}
-	return &RowPtr{Row: prev.Value.(*uncsv.Row), element: prev, lnum: r.lnum - 1, list: r.list}
+	L := r.list
+	if r.offset > 0 {
+		return L.ptr(r.chunk, r.offset-1, r.lnum-1)
+	}
+	if r.chunk > 0 {
+		return L.ptr(r.chunk-1, L.chunkLen(r.chunk-1)-1, r.lnum-1)
+	}
+	return nil
 }
 
 func (r *RowPtr) Remove() *uncsv.Row {
-	return r.list.Remove(r.element).(*uncsv.Row)
+	r.resolve()
+	if r.Row == nil {
+		return nil
+	}
+	return r.list.remove(r.chunk, r.offset, r.lnum)
 }
 
 func (r *RowPtr) Clone() *RowPtr {
-	return &RowPtr{Row: r.element.Value.(*uncsv.Row), element: r.element, lnum: r.lnum, list: r.list}
+	clone := *r
+	return &clone
 }
 
-func frontPtr(L *list.List) *RowPtr {
-	front := L.Front()
-	return &RowPtr{Row: front.Value.(*uncsv.Row), element: front, lnum: 0, list: L}
+func frontPtr(L *rowList) *RowPtr {
+	return L.ptr(0, 0, 0)
 }
 
-func backPtr(L *list.List) *RowPtr {
-	back := L.Back()
-	return &RowPtr{Row: back.Value.(*uncsv.Row), element: back, lnum: L.Len() - 1, list: L}
+func backPtr(L *rowList) *RowPtr {
+	c := len(L.chunks) - 1
+	return L.ptr(c, L.chunkLen(c)-1, L.length-1)
 }
 
 func (r *RowPtr) InsertAfter(val *uncsv.Row) *RowPtr {
-	next := r.list.InsertAfter(val, r.element)
-	return &RowPtr{Row: next.Value.(*uncsv.Row), element: next, lnum: r.lnum + 1, list: r.list}
+	r.resolve()
+	r.list.insert(r.chunk, r.offset+1, r.lnum+1, val)
+	next := r.Next()
+	return next
 }
 
 func (r *RowPtr) InsertBefore(val *uncsv.Row) *RowPtr {
-	next := r.list.InsertBefore(val, r.element)
-	r.lnum++
-	return &RowPtr{Row: next.Value.(*uncsv.Row), element: next, lnum: r.lnum - 1, list: r.list}
+	r.resolve()
+	L := r.list
+	lnum := r.lnum
+	L.insert(r.chunk, r.offset, lnum, val)
+	c, o, _ := L.locate(lnum)
+	return L.ptr(c, o, lnum)
 }
 
+// Index returns the 0-based line number of the row, which follows the rows
+// inserted and removed above it.
 func (r *RowPtr) Index() int {
+	r.resolve()
 	return r.lnum
 }
 
 type _Application struct {
-	csvLines    *list.List
-	removedRows []*uncsv.Row
-	out         io.Writer
+	*Document
+	out       io.Writer
+	lastTitle string
+	// lastColumnEdit is undone by `-undo` of the commands editing a column
+	lastColumnEdit *columnEdit
+	// dateLayouts are the layouts of the columns declared by `:date`
+	dateLayouts map[int]string
+	// columnTypes are the types of the columns converted by `:convert`
+	columnTypes map[int]*columnType
+	// parseState has the problems found on reading for `:warnings`
+	parseState *parseState
+	// gitBase maps the rows to those of the last commit compared by
+	// `:gitdiff`, or nil for the rows added after it
+	gitBase map[*uncsv.Row]*uncsv.Row
+	// saved is set when the data is written by `w` and so on
+	saved bool
+	// registers are inserted into the prompts by Ctrl-R
+	registers registers
+	// recentFiles are the names written, offered by the prompt of `w`
+	recentFiles *recentFiles
 	Pilot
 	*Config
 }
 
 type Result struct {
 	*_Application
+	// Row and Col are the 1-based position of the cursor on quitting,
+	// which can be given to Config.StartRow and Config.StartCol to resume
+	// the next session there.
+	Row int
+	Col int
+	// Search is the last pattern searched, to give to Config.StartSearch
+	Search string
+	// Saved is true when the data was written by the user at least once
+	Saved bool
+	// Picked is the row chosen by Enter in Config.PickMode and PickedCell
+	// is the text of the cell at the cursor on it. Picked is nil when the
+	// user quit without choosing.
+	Picked     *uncsv.Row
+	PickedCell string
+	// Checked are the rows checked by Space in Config.MultiPick in the
+	// order of the data
+	Checked []*uncsv.Row
 }
 
 func (app *_Application) Write(data []byte) (int, error) {
 	return app.out.Write(data)
 }
-
-func (app *_Application) Front() *RowPtr {
-	return frontPtr(app.csvLines)
-}
-
-func (app *_Application) Back() *RowPtr {
-	return backPtr(app.csvLines)
-}
-
-func (app *_Application) Len() int {
-	return app.csvLines.Len()
-}
-
-func (app *_Application) Push(row *uncsv.Row) {
-	app.csvLines.PushBack(row)
-}
-
-func (app *_Application) Each(callback func(*uncsv.Row) bool) {
-	for p := app.Front(); p != nil; p = p.Next() {
-		if !callback(p.Row) {
-			break
-		}
-	}
-}
-
-func (app *_Application) RemovedRows(callback func(*uncsv.Row) bool) {
-	for _, p := range app.removedRows {
-		if !callback(p) {
-			break
-		}
-	}
-}
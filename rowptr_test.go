@@ -0,0 +1,136 @@
+package csvi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func newTestRow(text string) *uncsv.Row {
+	mode := &uncsv.Mode{Comma: ','}
+	row := uncsv.NewRow(mode)
+	row.Replace(0, text, mode)
+	return &row
+}
+
+func rowListTexts(L *rowList) string {
+	texts := []string{}
+	for p := frontPtr(L); p != nil; p = p.Next() {
+		texts = append(texts, p.Cell[0].Text())
+	}
+	return fmt.Sprint(texts)
+}
+
+func TestRowList(t *testing.T) {
+	L := newRowList()
+	for i := 0; i < rowChunkSize*3; i++ {
+		L.PushBack(newTestRow(fmt.Sprint(i)))
+	}
+	// insert enough rows in one chunk to split it
+	p := frontPtr(L)
+	for i := 0; i < rowChunkSize*2; i++ {
+		p.InsertBefore(newTestRow("x"))
+	}
+	if p.Index() != rowChunkSize*2 || p.Cell[0].Text() != "0" {
+		t.Fatalf("InsertBefore: index=%d text=%s", p.Index(), p.Cell[0].Text())
+	}
+	if L.Len() != rowChunkSize*5 {
+		t.Fatalf("Len: %d", L.Len())
+	}
+	n := 0
+	for q := frontPtr(L); q != nil; q = q.Next() {
+		if q.Index() != n {
+			t.Fatalf("Index: expect %d but %d", n, q.Index())
+		}
+		n++
+	}
+	for q := backPtr(L); q != nil; q = q.Prev() {
+		n--
+		if q.Index() != n {
+			t.Fatalf("Index(reverse): expect %d but %d", n, q.Index())
+		}
+	}
+
+	// a pointer keeps its row after rows are inserted or removed above it
+	L = newRowList()
+	for _, s := range []string{"a", "b", "c", "d"} {
+		L.PushBack(newTestRow(s))
+	}
+	c := frontPtr(L).Next().Next()
+	frontPtr(L).InsertAfter(newTestRow("A"))
+	if c.Next().Cell[0].Text() != "d" || c.Index() != 3 {
+		t.Fatalf("after insertion: %s %d", c.Next().Cell[0].Text(), c.Index())
+	}
+	frontPtr(L).Remove()
+	if c.Prev().Cell[0].Text() != "b" || c.Index() != 2 {
+		t.Fatalf("after removal: %s %d", c.Prev().Cell[0].Text(), c.Index())
+	}
+	if s := rowListTexts(L); s != "[A b c d]" {
+		t.Fatalf("rows: %s", s)
+	}
+
+	// Index follows the rows inserted above without moving the pointer
+	d := c.Next()
+	frontPtr(L).InsertBefore(newTestRow("Z"))
+	if d.Index() != 4 || c.Index() != 3 {
+		t.Fatalf("Index: %d %d", c.Index(), d.Index())
+	}
+	// a pointer to the row removed points the row which took its place
+	removed := c.Clone()
+	removed.Remove()
+	if c.Index() != 3 || c.Cell[0].Text() != "d" || c.Prev().Cell[0].Text() != "b" {
+		t.Fatalf("after removal of itself: %d %s", c.Index(), c.Cell[0].Text())
+	}
+	d.Remove()
+	if c.Index() != 2 || c.Cell[0].Text() != "b" || c.Next() != nil {
+		t.Fatalf("after removal of the last row: %d %s", c.Index(), c.Cell[0].Text())
+	}
+}
+
+func TestRowListTrimEdits(t *testing.T) {
+	L := newRowList()
+	for _, s := range []string{"a", "b", "c"} {
+		L.PushBack(newTestRow(s))
+	}
+	c := frontPtr(L).Next().Next()
+	for i := 0; i < maxRowEdits*2; i++ {
+		frontPtr(L).InsertBefore(newTestRow("x"))
+		frontPtr(L).Remove()
+	}
+	if len(L.edits) >= maxRowEdits*2 {
+		t.Fatalf("the edits are not trimmed: %d", len(L.edits))
+	}
+	if c.Index() != 2 || c.Cell[0].Text() != "c" {
+		t.Fatalf("after the edits trimmed: %d %s", c.Index(), c.Cell[0].Text())
+	}
+	frontPtr(L).InsertBefore(newTestRow("A"))
+	if c.Index() != 3 || c.Cell[0].Text() != "c" {
+		t.Fatalf("after the insertion: %d %s", c.Index(), c.Cell[0].Text())
+	}
+
+	// a pointer on the list emptied points no rows
+	for L.Len() > 0 {
+		frontPtr(L).Remove()
+	}
+	if c.Index() != 0 || c.Row != nil || c.Next() != nil || c.Prev() != nil {
+		t.Fatalf("on the empty list: %d %v", c.Index(), c.Row)
+	}
+}
+
+func BenchmarkRowList(b *testing.B) {
+	L := newRowList()
+	for i := 0; i < 1000000; i++ {
+		L.PushBack(newTestRow("x"))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := frontPtr(L)
+		for j := 0; j < 1000; j++ {
+			p = p.Next()
+		}
+		p.InsertBefore(newTestRow("y"))
+		for q := frontPtr(L); q != nil; q = q.Next() {
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package csvi
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["sample"] = &exCommand{
+		help:  "keep N rows or P% of rows at random, or write them to FILE (sample [-seed S] N|P% [FILE])",
+		batch: true,
+		run:   cmdSample,
+	}
+}
+
+// parseSampleSize returns the number of rows to sample from total rows
+// for `N` or `P%`.
+func parseSampleSize(s string, total int) (int, error) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		percent, err := strconv.ParseFloat(p, 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return 0, fmt.Errorf("%s: the percentage must be from 0 to 100", s)
+		}
+		return int(math.Ceil(float64(total) * percent / 100)), nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s: the size must be N or P%%", s)
+	}
+	return min(n, total), nil
+}
+
+// sampleRows returns the sorted indexes of size rows chosen from total rows
+func sampleRows(total, size int, seed int64) []int {
+	picked := rand.New(rand.NewSource(seed)).Perm(total)[:size]
+	sort.Ints(picked)
+	return picked
+}
+
+func cmdSample(e *exCommandArgs) (string, error) {
+	const usage = "usage: sample [-seed S] N|P% [FILE]"
+	args := strings.Fields(e.Args)
+	seed := time.Now().UnixNano()
+	if len(args) >= 2 && args[0] == "-seed" {
+		var err error
+		seed, err = strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return usage, nil
+		}
+		args = args[2:]
+	}
+	if len(args) < 1 {
+		return usage, nil
+	}
+	fname := ""
+	if len(args) >= 2 {
+		fname, _ = expandHome(strings.Join(args[1:], " "))
+		if err := checkWritableSuffix(fname); err != nil {
+			return "", err
+		}
+	} else if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	header := min(max(e.HeaderLines, 0), e.Len())
+	total := e.Len() - header
+	size, err := parseSampleSize(args[0], total)
+	if err != nil {
+		return "", err
+	}
+	picked := sampleRows(total, size, seed)
+	message := fmt.Sprintf("%d of %d rows sampled (seed %d)", size, total, seed)
+
+	if fname != "" {
+		fd, err := os.Create(fname)
+		if err != nil {
+			return "", err
+		}
+		lnum := 0
+		err = dumpAndClose(fname, fd, func(w io.Writer) {
+			e.Mode.DumpBy(func() *uncsv.Row {
+				if lnum >= header {
+					if len(picked) <= 0 {
+						return nil
+					}
+					lnum, picked = header+picked[0], picked[1:]
+				}
+				row, err := e.rowAt(lnum + 1)
+				if err != nil {
+					return nil
+				}
+				lnum++
+				return row.Row
+			}, w)
+		})
+		if err != nil {
+			return "", err
+		}
+		return message + " to " + fname, nil
+	}
+	if size >= total {
+		return message, nil
+	}
+	if size == 0 && header == 0 {
+		return "the last row can not be removed", nil
+	}
+	keep := make(map[int]struct{}, size)
+	for _, i := range picked {
+		keep[header+i] = struct{}{}
+	}
+	for lnum := e.Len() - 1; lnum >= header; lnum-- {
+		if _, ok := keep[lnum]; ok {
+			continue
+		}
+		row, err := e.rowAt(lnum + 1)
+		if err != nil {
+			return "", err
+		}
+		e.removeRow(row)
+	}
+	e.CursorRow = e.Front()
+	e.CursorCol = 0
+	e.setDirty()
+	e.view.clearCache()
+	return message, nil
+}
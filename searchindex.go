@@ -0,0 +1,89 @@
+package csvi
+
+import (
+	"sort"
+	"strings"
+)
+
+// searchIndexRowsPerStep is the number of rows scanned by a step of searchIndex
+// so that keys typed are not kept waiting.
+const searchIndexRowsPerStep = 256
+
+type searchHit struct {
+	lnum int
+	col  int
+}
+
+func (h searchHit) before(lnum, col int) bool {
+	return h.lnum < lnum || (h.lnum == lnum && h.col < col)
+}
+
+// searchIndex collects the cells containing the last searched word while
+// the application waits for keys, so that `n` and `N` find the next one
+// without scanning rows. It is obsolete after rows are modified.
+type searchIndex struct {
+	word      string
+	hits      []searchHit
+	scanned   int
+	version   int
+	editCount int
+}
+
+func (idx *searchIndex) reset(app *_Application, word string) {
+	idx.word = word
+	idx.hits = idx.hits[:0]
+	idx.scanned = 0
+	idx.version = app.csvLines.version()
+	idx.editCount = app.editCount
+}
+
+func (idx *searchIndex) valid(app *_Application, word string) bool {
+	return idx.word != "" && idx.word == word &&
+		idx.version == app.csvLines.version() &&
+		idx.editCount == app.editCount
+}
+
+// step scans some rows not scanned yet and returns true while rows remain.
+func (idx *searchIndex) step(app *_Application) bool {
+	if !idx.valid(app, idx.word) || idx.scanned >= app.Len() {
+		return false
+	}
+	p, err := app.rowAt(idx.scanned + 1)
+	for i := 0; err == nil && p != nil && i < searchIndexRowsPerStep; i++ {
+		for c, cell := range p.Cell {
+			if strings.Contains(cell.Text(), idx.word) {
+				idx.hits = append(idx.hits, searchHit{lnum: p.lnum, col: c})
+			}
+		}
+		idx.scanned++
+		p = p.Next()
+	}
+	return idx.scanned < app.Len()
+}
+
+// forward returns the first hit after (lnum,col).
+// When it is false, the rows not scanned yet have to be searched.
+func (idx *searchIndex) forward(lnum, col int) (searchHit, bool) {
+	i := sort.Search(len(idx.hits), func(i int) bool {
+		return !idx.hits[i].before(lnum, col+1)
+	})
+	if i < len(idx.hits) {
+		return idx.hits[i], true
+	}
+	return searchHit{}, false
+}
+
+// backward returns the last hit before (lnum,col).
+// When it is false, the rows not scanned yet have to be searched.
+func (idx *searchIndex) backward(lnum, col int) (searchHit, bool) {
+	if idx.scanned <= lnum {
+		return searchHit{}, false
+	}
+	i := sort.Search(len(idx.hits), func(i int) bool {
+		return !idx.hits[i].before(lnum, col)
+	})
+	if i > 0 {
+		return idx.hits[i-1], true
+	}
+	return searchHit{}, false
+}
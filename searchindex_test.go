@@ -0,0 +1,29 @@
+package csvi
+
+import (
+	"testing"
+)
+
+func TestSearchIndex(t *testing.T) {
+	app := &_Application{Document: NewDocument(nil)}
+	for _, s := range []string{"foo", "bar", "foobar", "baz", "food"} {
+		app.Push(newTestRow(s))
+	}
+	var idx searchIndex
+	idx.reset(app, "foo")
+	for idx.step(app) {
+	}
+	if hit, ok := idx.forward(0, 0); !ok || hit.lnum != 2 {
+		t.Fatalf("forward: %v %v", hit, ok)
+	}
+	if hit, ok := idx.backward(4, 0); !ok || hit.lnum != 2 {
+		t.Fatalf("backward: %v %v", hit, ok)
+	}
+	if _, ok := idx.forward(4, 0); ok {
+		t.Fatal("forward: found after the last hit")
+	}
+	app.Front().Next().InsertAfter(newTestRow("foo"))
+	if idx.valid(app, "foo") {
+		t.Fatal("valid after insertion")
+	}
+}
@@ -0,0 +1,36 @@
+package csvi
+
+import (
+	"slices"
+	"sync"
+)
+
+// Session lets other goroutines append rows to the data while Edit runs,
+// e.g. the results of a query arriving one by one. Set it to
+// Config.Session before calling Edit. The rows are shown after all rows
+// of the reader given to Edit.
+type Session struct {
+	mu   sync.Mutex
+	rows [][]string
+}
+
+// AppendRow queues the row of cells to be appended. It is safe to call
+// it from any goroutines.
+func (s *Session) AppendRow(cells []string) {
+	s.mu.Lock()
+	s.rows = append(s.rows, slices.Clone(cells))
+	s.mu.Unlock()
+}
+
+// flush appends the rows queued to app and returns the number of them
+func (s *Session) flush(app *_Application) int {
+	s.mu.Lock()
+	rows := s.rows
+	s.rows = nil
+	s.mu.Unlock()
+
+	for _, cells := range rows {
+		app.appendRow(cells)
+	}
+	return len(rows)
+}
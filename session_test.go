@@ -0,0 +1,37 @@
+package csvi
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestSessionAppendRow(t *testing.T) {
+	cfg := &Config{Mode: &uncsv.Mode{Comma: ',', DefaultTerm: "\n"}}
+	app, err := cfg.readAll(strings.NewReader("a,b"), io.Discard)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var session Session
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			session.AppendRow([]string{"c", "d,e"})
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	if n := session.flush(app); n != 2 {
+		t.Fatalf("expect 2 rows but %d", n)
+	}
+	var out strings.Builder
+	dump(app, &out)
+	expect := "a,b\nc,\"d,e\"\nc,\"d,e\"\n"
+	if out.String() != expect {
+		t.Fatalf("expect %q but %q", expect, out.String())
+	}
+}
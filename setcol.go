@@ -0,0 +1,154 @@
+package csvi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["setcol"] = &exCommand{
+		help:  "set the current column of all rows, or of rows matching COL=VALUE or COL~REGEXP, to TEXT (setcol [-where COND] TEXT | setcol -undo)",
+		batch: true,
+		run:   cmdSetColumn,
+	}
+}
+
+// columnEdit is the cells changed by the last `:setcol` or `:transform`
+// to undo them at once
+type columnEdit struct {
+	col   int
+	rows  []*RowPtr
+	cells []uncsv.Cell
+}
+
+// parseRowCondition returns the function to test rows for `COL=VALUE`
+// or `COL~REGEXP`.
+func (app *_Application) parseRowCondition(s string) (func(*uncsv.Row) bool, error) {
+	i := strings.IndexAny(s, "=~")
+	if i < 0 {
+		return nil, fmt.Errorf("%s: the condition must be COL=VALUE or COL~REGEXP", s)
+	}
+	col, err := app.columnIndex(s[:i])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s[:i], err)
+	}
+	value := s[i+1:]
+	if s[i] == '=' {
+		return func(row *uncsv.Row) bool {
+			return col < len(row.Cell) && row.Cell[col].Text() == value
+		}, nil
+	}
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return nil, err
+	}
+	return func(row *uncsv.Row) bool {
+		return col < len(row.Cell) && re.MatchString(row.Cell[col].Text())
+	}, nil
+}
+
+func cmdSetColumn(e *exCommandArgs) (string, error) {
+	const usage = "usage: setcol [-where COL=VALUE|COL~REGEXP] TEXT | setcol -undo"
+	if e.Args == "-undo" {
+		return e.undoColumnEdit()
+	}
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	match := func(*uncsv.Row) bool { return true }
+	args := e.Args
+	if rest, ok := strings.CutPrefix(args, "-where "); ok {
+		cond, text, ok := strings.Cut(strings.TrimSpace(rest), " ")
+		if !ok {
+			return usage, nil
+		}
+		var err error
+		match, err = e.parseRowCondition(cond)
+		if err != nil {
+			return "", err
+		}
+		args = strings.TrimSpace(text)
+	}
+	text, err := unquoteBatchText(args)
+	if err != nil {
+		return "", err
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	col := e.CursorCol
+	var rows []*RowPtr
+	var texts []string
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() < e.HeaderLines || !match(p.Row) {
+			continue
+		}
+		if col >= len(p.Cell) && e.FixColumn {
+			return "", fmt.Errorf("row %d: %s", p.Index()+1, msgColumnFixed)
+		}
+		tx, err := e.validate(p, col, text)
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", p.Index()+1, err)
+		}
+		rows = append(rows, p)
+		texts = append(texts, tx)
+	}
+	if len(rows) <= 0 {
+		return "no rows match", nil
+	}
+	name := e.columnName(col)
+	if name == "" {
+		name = fmt.Sprintf("column %d", col+1)
+	}
+	if e.Config.Pilot != nil && !e.confirm(ConfirmCommand, fmt.Sprintf("set %d cell(s) of %s to %s ? [y/n]", len(rows), name, text)) {
+		return "", nil
+	}
+	e.setColumn(col, rows, texts)
+	return fmt.Sprintf("set %d cell(s) of %s (`:setcol -undo` restores them)", len(rows), name), nil
+}
+
+// setColumn replaces the cells of the column col in rows with texts and
+// records them to undo at once.
+func (app *_Application) setColumn(col int, rows []*RowPtr, texts []string) {
+	undo := &columnEdit{col: col}
+	for i, row := range rows {
+		for col >= len(row.Cell) {
+			row.Insert(len(row.Cell), "", app.Mode)
+		}
+		undo.rows = append(undo.rows, row)
+		undo.cells = append(undo.cells, row.Cell[col])
+		app.audit("replace", row, col, row.Cell[col].Text(), texts[i])
+		q := row.Cell[col].IsQuoted()
+		row.Replace(col, texts[i], app.Mode)
+		if q {
+			row.Cell[col] = row.Cell[col].Quote(app.Mode)
+		}
+		app.touch(row, col)
+	}
+	app.lastColumnEdit = undo
+	app.setDirty()
+}
+
+// undoColumnEdit restores the cells changed by the last `:setcol` or
+// `:transform`.
+func (app *_Application) undoColumnEdit() (string, error) {
+	undo := app.lastColumnEdit
+	if undo == nil {
+		return "", errors.New("nothing to undo")
+	}
+	for i, row := range undo.rows {
+		if undo.col >= len(row.Cell) {
+			continue
+		}
+		app.audit("restore", row, undo.col, row.Cell[undo.col].Text(), undo.cells[i].Text())
+		row.Cell[undo.col] = undo.cells[i]
+		app.touch(row, undo.col)
+	}
+	app.lastColumnEdit = nil
+	app.setDirty()
+	return fmt.Sprintf("restored %d cell(s)", len(undo.rows)), nil
+}
@@ -0,0 +1,167 @@
+package csvi
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/nyaosorg/go-readline-ny/keys"
+)
+
+func init() {
+	exCommands["spell"] = &exCommand{
+		help: "list values occurring only once that look like a typo of a frequent value in the current column",
+		run:  cmdSpell,
+	}
+}
+
+// editDistance returns the Levenshtein distance between a and b counted in runes.
+func editDistance(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+type spellHint struct {
+	row        *RowPtr
+	value      string
+	suggestion string
+	count      int
+}
+
+const spellMaxDistance = 2
+
+// findSpellHints looks for values in the column col which occur only once
+// and are within spellMaxDistance of a value occurring twice or more.
+// Short values need a relatively smaller distance so that codes like "A"
+// and "B" are not reported.
+func findSpellHints(front *RowPtr, headerLines, col int) []spellHint {
+	count := map[string]int{}
+	first := map[string]*RowPtr{}
+	for p := front; p != nil; p = p.Next() {
+		if p.Index() < headerLines || col >= len(p.Cell) {
+			continue
+		}
+		text := p.Cell[col].Text()
+		if text == "" {
+			continue
+		}
+		if count[text]++; count[text] == 1 {
+			first[text] = p
+		}
+	}
+	frequent := []string{}
+	for text, n := range count {
+		if n >= 2 {
+			frequent = append(frequent, text)
+		}
+	}
+	sort.Slice(frequent, func(i, j int) bool {
+		if count[frequent[i]] != count[frequent[j]] {
+			return count[frequent[i]] > count[frequent[j]]
+		}
+		return frequent[i] < frequent[j]
+	})
+	var hints []spellHint
+	for text, n := range count {
+		if n != 1 {
+			continue
+		}
+		length := utf8.RuneCountInString(text)
+		best := ""
+		bestDistance := spellMaxDistance + 1
+		for _, f := range frequent {
+			d := editDistance(text, f)
+			if d*2 < length && d < bestDistance {
+				best = f
+				bestDistance = d
+			}
+		}
+		if best != "" {
+			hints = append(hints, spellHint{
+				row:        first[text],
+				value:      text,
+				suggestion: best,
+				count:      count[best],
+			})
+		}
+	}
+	sort.Slice(hints, func(i, j int) bool {
+		return hints[i].row.Index() < hints[j].row.Index()
+	})
+	return hints
+}
+
+func cmdSpell(e *exCommandArgs) (string, error) {
+	col := e.CursorCol
+	hints := findSpellHints(e.Front(), e.HeaderLines, col)
+	if len(hints) <= 0 {
+		return fmt.Sprintf("column %d: no suspicious values", col+1), nil
+	}
+	defer e.view.clearCache()
+	mode := e.Mode
+	message := ""
+	index := 0
+	for len(hints) > 0 {
+		lines := make([]string, len(hints))
+		for i, h := range hints {
+			lines[i] = fmt.Sprintf("(%d,%d) %s -> %s (%d times)",
+				col+1, h.row.Index()+1, h.value, h.suggestion, h.count)
+		}
+		title := fmt.Sprintf("column %d: %d suspicious value(s). [f]fix [Enter]jump [q]quit", col+1, len(hints))
+		if message != "" {
+			title = message
+			message = ""
+		}
+		ch, i, err := e.listBox(title, lines, index, e.lfCount, e.screenWidth, e.screenHeight)
+		if err != nil {
+			return "", err
+		}
+		index = i
+		switch ch {
+		case "f":
+			h := hints[index]
+			if m := e.checkWriteProtect(h.row); m != "" {
+				message = m
+				break
+			}
+			text, err := e.validate(h.row, col, h.suggestion)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			e.audit("replace", h.row, col, h.row.Cell[col].Text(), text)
+			q := h.row.Cell[col].IsQuoted()
+			h.row.Replace(col, text, mode)
+			if q {
+				h.row.Cell[col] = h.row.Cell[col].Quote(mode)
+			}
+			e.setDirty()
+			e.touch(h.row, col)
+			hints = append(hints[:index], hints[index+1:]...)
+		case keys.Enter:
+			e.CursorRow = hints[index].row
+			e.CursorCol = col
+			return "", nil
+		case "q", keys.Escape:
+			return "", nil
+		}
+	}
+	return "all suspicious values are fixed", nil
+}
@@ -0,0 +1,91 @@
+package csvi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func init() {
+	exCommands["split"] = &exCommand{
+		help:  "write every N rows to FILE-001, FILE-002 ... (split [-h] N FILE. -h repeats the header)",
+		batch: true,
+		run:   cmdSplit,
+	}
+}
+
+// chunkName returns the name of the n-th chunk: out.csv.gz -> out-001.csv.gz
+func chunkName(fname string, n int) string {
+	ext := filepath.Ext(fname)
+	if strings.EqualFold(ext, ".gz") {
+		ext = filepath.Ext(strings.TrimSuffix(fname, ext)) + ext
+	}
+	return fmt.Sprintf("%s-%03d%s", strings.TrimSuffix(fname, ext), n, ext)
+}
+
+func cmdSplit(e *exCommandArgs) (string, error) {
+	args := e.Args
+	withHeader := false
+	if rest, ok := strings.CutPrefix(args, "-h "); ok {
+		withHeader = true
+		args = strings.TrimSpace(rest)
+	}
+	count, fname, _ := strings.Cut(args, " ")
+	fname = strings.TrimSpace(fname)
+	size, err := strconv.Atoi(count)
+	if err != nil || size <= 0 || fname == "" {
+		return "usage: split [-h] N FILE", nil
+	}
+	fname, _ = expandHome(fname)
+	if err := checkWritableSuffix(fname); err != nil {
+		return "", err
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	var header []*uncsv.Row
+	cursor := e.Front()
+	if withHeader {
+		for i := 0; i < e.HeaderLines && cursor != nil; i++ {
+			header = append(header, cursor.Row)
+			cursor = cursor.Next()
+		}
+	}
+	n := 0
+	for cursor != nil {
+		n++
+		name := chunkName(fname, n)
+		fd, err := os.Create(name)
+		if err != nil {
+			return "", err
+		}
+		err = dumpAndClose(name, fd, func(w io.Writer) {
+			i := 0
+			e.Mode.DumpBy(func() *uncsv.Row {
+				if i < len(header) {
+					i++
+					return header[i-1]
+				}
+				if cursor == nil || i >= len(header)+size {
+					return nil
+				}
+				i++
+				row := cursor.Row
+				cursor = cursor.Next()
+				return row
+			}, w)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if n <= 0 {
+		return "split: no rows to write", nil
+	}
+	return fmt.Sprintf("%d files written: %s .. %s", n, chunkName(fname, 1), chunkName(fname, n)), nil
+}
@@ -0,0 +1,165 @@
+package csvi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nyaosorg/go-readline-ny/keys"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// overlongLine is the length in bytes of a row regarded as too long
+// by Config.Strict
+const overlongLine = 64 * 1024
+
+func init() {
+	exCommands["warnings"] = &exCommand{
+		help: "list the problems found on reading with -strict and the cells cut by -maxcell",
+		run:  cmdWarnings,
+	}
+}
+
+// parseWarning is a problem of the data found on reading
+type parseWarning struct {
+	// lnum is the 0-based index of the row
+	lnum int
+	// line is the 1-based line number in the source where the row starts
+	line int
+	// col is the 0-based index of the column or -1 for the whole row
+	col     int
+	message string
+}
+
+// parseState is the position in the source tracked on reading
+type parseState struct {
+	rows     int
+	lines    int
+	warnings []parseWarning
+}
+
+// readLine reads a row and records the problems of it into p when
+// Config.Strict is set, and the cells cut by MaxCellLength of uncsv.Mode.
+func (cfg *Config) readLine(reader *bufio.Reader, p *parseState) (*uncsv.Row, error) {
+	row, err := uncsv.ReadLine(reader, cfg.Mode)
+	if !cfg.Strict && cfg.MaxCellLength <= 0 {
+		return row, err
+	}
+	if (err != nil && err != io.EOF) || (err == io.EOF && isEmptyRow(row)) {
+		return row, err
+	}
+	var warnings []parseWarning
+	if cfg.Strict {
+		warnings = checkRow(row, cfg.Mode, err == io.EOF)
+	}
+	for i, c := range row.Cell {
+		if c.Truncated() {
+			warnings = append(warnings, parseWarning{
+				col:     i,
+				message: fmt.Sprintf("cut at %d bytes", cfg.MaxCellLength),
+			})
+		}
+	}
+	for _, w := range warnings {
+		w.lnum = p.rows
+		w.line = p.lines + 1
+		p.warnings = append(p.warnings, w)
+		cfg.notify(MessageWarning, fmt.Sprintf("line %d: %s", w.line, w.message))
+	}
+	p.rows++
+	for _, c := range row.Cell {
+		p.lines += strings.Count(c.SourceText(cfg.Mode), "\n")
+	}
+	if row.Term != "" {
+		p.lines++
+	}
+	return row, err
+}
+
+// isBareQuoted returns true when the source of a cell contains double
+// quotes which are not the enclosing ones nor the doubled ones.
+func isBareQuoted(s string) bool {
+	if !strings.Contains(s, `"`) {
+		return false
+	}
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return true
+	}
+	return strings.Contains(strings.ReplaceAll(s[1:len(s)-1], `""`, ""), `"`)
+}
+
+// checkRow returns the problems in row without their positions.
+// eof is true when row is the last one.
+func checkRow(row *uncsv.Row, mode *uncsv.Mode, eof bool) []parseWarning {
+	var warnings []parseWarning
+	size := 0
+	for i, c := range row.Cell {
+		s := c.SourceText(mode)
+		size += len(c.Source())
+		switch {
+		case eof && i == len(row.Cell)-1 && strings.Count(s, `"`)%2 == 1:
+			warnings = append(warnings, parseWarning{col: i, message: "unterminated quote"})
+		case isBareQuoted(s):
+			warnings = append(warnings, parseWarning{col: i, message: "bare quote inside the field"})
+		}
+		if strings.ContainsRune(s, 0) {
+			warnings = append(warnings, parseWarning{col: i, message: "NUL byte"})
+		}
+	}
+	if size > overlongLine {
+		warnings = append(warnings, parseWarning{
+			col:     -1,
+			message: fmt.Sprintf("overlong line (%d bytes)", size),
+		})
+	}
+	return warnings
+}
+
+// warningStatus returns the number of the problems for the status line
+func (app *_Application) warningStatus() string {
+	if n := len(app.parseState.warnings); n > 0 {
+		return fmt.Sprintf("[!%d]", n)
+	}
+	return ""
+}
+
+func cmdWarnings(e *exCommandArgs) (string, error) {
+	warnings := e.parseState.warnings
+	if len(warnings) <= 0 {
+		if !e.Strict {
+			return "no cells are cut. Other problems are recorded only with -strict", nil
+		}
+		if e.loading() {
+			return "no problems found in the rows read so far", nil
+		}
+		return "no problems found", nil
+	}
+	lines := make([]string, len(warnings))
+	for i, w := range warnings {
+		if w.col >= 0 {
+			lines[i] = fmt.Sprintf("line %d (%d,%d): %s", w.line, w.col+1, w.lnum+1, w.message)
+		} else {
+			lines[i] = fmt.Sprintf("line %d (row %d): %s", w.line, w.lnum+1, w.message)
+		}
+	}
+	title := fmt.Sprintf("%d problem(s): [Enter]jump [q]close", len(warnings))
+	defer e.view.clearCache()
+	key, index, err := e.listBox(title, lines, 0, e.lfCount, e.screenWidth, e.screenHeight)
+	if err != nil {
+		return "", err
+	}
+	if key == keys.Enter && 0 <= index && index < len(warnings) {
+		row, err := e.rowAt(warnings[index].lnum + 1)
+		if err != nil {
+			return "", err
+		}
+		e.CursorRow = row
+		if w := warnings[index]; w.col >= 0 {
+			e.CursorCol = w.col
+		}
+		return warnings[index].message, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,214 @@
+package csvi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// the values of Config.Summary
+const (
+	SummarySpark = "spark"
+	SummaryStats = "stats"
+)
+
+func init() {
+	exCommands["summary"] = &exCommand{
+		help: "draw the row of the sparklines or min..max avg of the numeric columns under the rows, or remove it (summary [spark|stats|off])",
+		run: func(e *exCommandArgs) (string, error) {
+			switch e.Args {
+			case "":
+				if e.Summary == "" {
+					e.Summary = SummarySpark
+				} else {
+					e.Summary = ""
+				}
+			case SummarySpark, SummaryStats:
+				e.Summary = e.Args
+			case "off":
+				e.Summary = ""
+			default:
+				return "usage: summary [spark|stats|off]", nil
+			}
+			e.view.clearCache()
+			if e.Summary == "" {
+				return "summary off", nil
+			}
+			return "summary " + e.Summary, nil
+		},
+	}
+}
+
+// sparkBuckets is the number of the buckets keeping the averages of the
+// values in the order of rows for a sparkline
+const sparkBuckets = 64
+
+// sparkBars are the characters of sparklines from the lowest to the
+// highest
+var sparkBars = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+type sparkBucket struct {
+	sum float64
+	n   int
+}
+
+// columnSummary accumulates the numbers of a column. The buckets of a
+// sparkline are merged in pairs when they are full, so that the memory
+// does not grow with the rows.
+type columnSummary struct {
+	count    int
+	nonEmpty int
+	sum      float64
+	min, max float64
+	buckets  []sparkBucket
+	per      int
+}
+
+func (s *columnSummary) add(text string) {
+	if text == "" {
+		return
+	}
+	s.nonEmpty++
+	value, ok := parseNumber(text)
+	if !ok {
+		return
+	}
+	if s.count == 0 {
+		s.min, s.max, s.per = value, value, 1
+	} else {
+		s.min = min(s.min, value)
+		s.max = max(s.max, value)
+	}
+	s.count++
+	s.sum += value
+	if len(s.buckets) <= 0 || s.buckets[len(s.buckets)-1].n >= s.per {
+		if len(s.buckets) >= sparkBuckets {
+			merged := s.buckets[:0]
+			for i := 0; i+1 < len(s.buckets); i += 2 {
+				merged = append(merged, sparkBucket{
+					sum: s.buckets[i].sum + s.buckets[i+1].sum,
+					n:   s.buckets[i].n + s.buckets[i+1].n,
+				})
+			}
+			s.buckets = merged
+			s.per *= 2
+		}
+		if len(s.buckets) <= 0 || s.buckets[len(s.buckets)-1].n >= s.per {
+			s.buckets = append(s.buckets, sparkBucket{})
+		}
+	}
+	s.buckets[len(s.buckets)-1].sum += value
+	s.buckets[len(s.buckets)-1].n++
+}
+
+// numeric returns true when the half or more of the cells not empty are
+// numbers
+func (s *columnSummary) numeric() bool {
+	return s.count > 0 && s.count*2 >= s.nonEmpty
+}
+
+func formatSummaryNumber(value float64) string {
+	return strconv.FormatFloat(value, 'g', 4, 64)
+}
+
+// stats returns the text like `1..10 avg 5.5`
+func (s *columnSummary) stats() string {
+	return fmt.Sprintf("%s..%s avg %s",
+		formatSummaryNumber(s.min),
+		formatSummaryNumber(s.max),
+		formatSummaryNumber(s.sum/float64(s.count)))
+}
+
+// sparkline returns the averages of the buckets drawn in width. When
+// the bars are drawn in two cells as East Asian Ambiguous characters,
+// it returns the stats instead.
+func (s *columnSummary) sparkline(width int) string {
+	if runewidth.StringWidth(sparkBars[0]) != 1 {
+		return s.stats()
+	}
+	n := min(width, len(s.buckets))
+	var buffer strings.Builder
+	for i := 0; i < n; i++ {
+		// the buckets resampled to n characters
+		from, to := i*len(s.buckets)/n, (i+1)*len(s.buckets)/n
+		var sum float64
+		var count int
+		for _, b := range s.buckets[from:to] {
+			sum += b.sum
+			count += b.n
+		}
+		level := 0
+		if s.max > s.min && count > 0 {
+			level = int(math.Round((sum/float64(count) - s.min) / (s.max - s.min) * float64(len(sparkBars)-1)))
+		}
+		buffer.WriteString(sparkBars[min(max(level, 0), len(sparkBars)-1)])
+	}
+	return buffer.String()
+}
+
+// summary keeps the summaries of the columns of the rows loaded. The rows
+// loaded later are added to them.
+type summary struct {
+	columns   []*columnSummary
+	scanned   int
+	editCount int
+}
+
+// updateSummary adds the rows not scanned yet to the summaries, or scans
+// all rows again after they are modified.
+func (app *_Application) updateSummary() {
+	if app.Summary == "" {
+		app.summary = nil
+		return
+	}
+	s := app.summary
+	if s == nil || s.editCount != app.editCount {
+		s = &summary{editCount: app.editCount}
+		app.summary = s
+	}
+	if s.scanned >= app.Len() {
+		return
+	}
+	p, err := app.rowAt(s.scanned + 1)
+	for ; err == nil && p != nil; p = p.Next() {
+		s.scanned++
+		if p.Index() < app.HeaderLines {
+			continue
+		}
+		for len(s.columns) < len(p.Cell) {
+			s.columns = append(s.columns, &columnSummary{})
+		}
+		for i, c := range p.Cell {
+			s.columns[i].add(c.Text())
+		}
+	}
+}
+
+// summaryRow returns the row drawn under the rows with the summaries of
+// the numeric columns
+func (cfg *Config) summaryRow(cellWidth int) *uncsv.Row {
+	row := &uncsv.Row{}
+	if cfg.summary == nil {
+		return row
+	}
+	sepWidth := runewidth.StringWidth(cfg.ColumnSeparator)
+	for i, s := range cfg.summary.columns {
+		text := ""
+		if s.numeric() {
+			if cfg.Summary == SummaryStats {
+				text = s.stats()
+			} else {
+				text = s.sparkline(cfg.columnWidth(i, cellWidth) - sepWidth - 1)
+			}
+		}
+		row.Insert(i, text, cfg.Mode)
+		// not underlined as a modified cell
+		row.Cell[i].SetOriginal(row.Cell[i])
+	}
+	return row
+}
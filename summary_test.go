@@ -0,0 +1,27 @@
+package csvi
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestColumnSummary(t *testing.T) {
+	var s columnSummary
+	for i := 1; i <= 200; i++ {
+		s.add(strconv.Itoa(i))
+	}
+	s.add("")
+	s.add("n/a")
+	if !s.numeric() || s.count != 200 || s.min != 1 || s.max != 200 {
+		t.Fatalf("expect 200 numbers from 1 to 200, but %d from %g to %g", s.count, s.min, s.max)
+	}
+	if len(s.buckets) > sparkBuckets {
+		t.Fatalf("expect %d buckets at most, but %d", sparkBuckets, len(s.buckets))
+	}
+	if expect := "1..200 avg 100.5"; s.stats() != expect {
+		t.Fatalf("expect %q but %q", expect, s.stats())
+	}
+	if expect := "▁▂▃▄▅▆▇█"; s.sparkline(8) != expect {
+		t.Fatalf("expect %q but %q", expect, s.sparkline(8))
+	}
+}
@@ -0,0 +1,68 @@
+package csvi
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// PrintTable reads all rows from in and writes them to out as an aligned
+// table without the terminal. Each cell is cut in CellWidth in the same
+// way as the screen of Edit. When colored is true, the rows are painted
+// with the colors of the screen.
+func (cfg Config) PrintTable(in io.Reader, out io.Writer, colored bool) error {
+	app, err := cfg.readAll(in, out)
+	if err != nil {
+		return err
+	}
+	cellWidth := cfg.CellWidth
+	if cellWidth <= 0 {
+		cellWidth = 14
+	}
+	sep := cfg.ColumnSeparator
+	if sep == "" {
+		sep = " "
+	}
+	textWidth := cellWidth - runewidth.StringWidth(sep)
+	w := bufio.NewWriter(out)
+	maxColumns := 0
+	for p := app.Front(); p != nil; p = p.Next() {
+		maxColumns = max(maxColumns, len(p.Cell))
+	}
+	for p := app.Front(); p != nil; p = p.Next() {
+		style := &bodyColorStyle
+		if p.Index() < cfg.HeaderLines {
+			style = &headColorStyle
+		}
+		if colored {
+			if p.Index()%2 == 0 {
+				w.WriteString(style.Even[0])
+			} else {
+				w.WriteString(style.Odd[0])
+			}
+		}
+		var line strings.Builder
+		for i, c := range p.Cell {
+			text := cfg.truncate(cfg.displayText(c), textWidth)
+			line.WriteString(text)
+			if i < len(p.Cell)-1 {
+				line.WriteString(strings.Repeat(" ", max(textWidth-runewidth.StringWidth(text), 0)))
+				line.WriteString(sep)
+			}
+		}
+		w.WriteString(line.String())
+		if colored {
+			w.WriteString(_ANSI_RESET)
+		}
+		w.WriteString("\n")
+		if p.Index() == cfg.HeaderLines-1 && cfg.HeaderRule != "" {
+			if rw := runewidth.StringWidth(cfg.HeaderRule); rw > 0 {
+				w.WriteString(strings.Repeat(cfg.HeaderRule, maxColumns*cellWidth/rw))
+				w.WriteString("\n")
+			}
+		}
+	}
+	return w.Flush()
+}
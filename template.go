@@ -0,0 +1,35 @@
+package csvi
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// expandTemplate replaces {today} and {now} in a value of Config.RowTemplate
+func expandTemplate(value string, now time.Time) string {
+	if !strings.Contains(value, "{") {
+		return value
+	}
+	return strings.NewReplacer(
+		"{today}", now.Format("2006-01-02"),
+		"{now}", now.Format("2006-01-02 15:04:05"),
+	).Replace(value)
+}
+
+// applyRowTemplate fills the cells of a new row with Config.RowTemplate
+func (app *_Application) applyRowTemplate(row *uncsv.Row) {
+	if len(app.RowTemplate) <= 0 {
+		return
+	}
+	now := time.Now()
+	for len(row.Cell) < len(app.RowTemplate) {
+		row.Insert(len(row.Cell), "", app.Mode)
+	}
+	for i, value := range app.RowTemplate {
+		if value != "" {
+			row.Replace(i, expandTemplate(value, now), app.Mode)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package csvi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	now := time.Date(2024, 6, 10, 9, 8, 7, 0, time.UTC)
+	for source, expect := range map[string]string{
+		"{today}":   "2024-06-10",
+		"at {now}":  "at 2024-06-10 09:08:07",
+		"{unknown}": "{unknown}",
+	} {
+		if result := expandTemplate(source, now); result != expect {
+			t.Fatalf("expandTemplate(%q): expect %q but %q", source, expect, result)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package csvi
+
+import (
+	"time"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+// DefaultTimestampLayout is the layout of Config.CreatedColumn and
+// Config.ModifiedColumn used when Config.TimestampLayout is empty.
+const DefaultTimestampLayout = "2006-01-02 15:04:05"
+
+func (app *_Application) timestamp() string {
+	layout := app.TimestampLayout
+	if layout == "" {
+		layout = DefaultTimestampLayout
+	}
+	return time.Now().Format(layout)
+}
+
+// setTimestamp sets the time into the column of the row which is named
+// name on the header or numbered name, and returns the index of the column.
+// When there is no such column, it returns -1.
+func (app *_Application) setTimestamp(row *uncsv.Row, name string, now string) int {
+	if name == "" {
+		return -1
+	}
+	col, err := app.columnIndex(name)
+	if err != nil {
+		return -1
+	}
+	for len(row.Cell) <= col {
+		row.Insert(len(row.Cell), "", app.Mode)
+	}
+	row.Replace(col, now, app.Mode)
+	return col
+}
+
+// stampNewRow fills Config.CreatedColumn and Config.ModifiedColumn
+// of a new row not inserted yet.
+func (app *_Application) stampNewRow(row *uncsv.Row) {
+	now := app.timestamp()
+	app.setTimestamp(row, app.CreatedColumn, now)
+	app.setTimestamp(row, app.ModifiedColumn, now)
+}
+
+// touch updates Config.ModifiedColumn of the row whose cell of the column
+// col has been changed. Rows of the header are not touched.
+func (app *_Application) touch(row *RowPtr, col int) {
+	if app.ModifiedColumn == "" || row.Index() < app.HeaderLines {
+		return
+	}
+	if c, err := app.columnIndex(app.ModifiedColumn); err != nil || c == col {
+		return
+	}
+	app.setTimestamp(row.Row, app.ModifiedColumn, app.timestamp())
+}
@@ -0,0 +1,48 @@
+package csvi
+
+import (
+	"io"
+)
+
+const (
+	_ANSI_PUSH_TITLE = "\x1B[22;0t"
+	_ANSI_POP_TITLE  = "\x1B[23;0t"
+)
+
+// title returns the window title. The control characters in the filename
+// are replaced not to end OSC 0 nor to send other escape sequences.
+func (app *_Application) title() string {
+	title := "csvi"
+	if app.Filename != "" {
+		title += " — " + app.replaceControls(app.Filename)
+	}
+	if app.dirty {
+		title += " [+]"
+	}
+	return title
+}
+
+// updateTitle sets the window title with OSC 0 when Config.SetTitle is true.
+// The first call saves the previous title on the terminal's title stack
+// so that restoreTitle can bring it back.
+func (app *_Application) updateTitle() {
+	if !app.SetTitle {
+		return
+	}
+	title := app.title()
+	if title == app.lastTitle {
+		return
+	}
+	if app.lastTitle == "" {
+		io.WriteString(app.out, _ANSI_PUSH_TITLE)
+	}
+	io.WriteString(app.out, "\x1B]0;"+title+"\a")
+	app.lastTitle = title
+}
+
+func (app *_Application) restoreTitle() {
+	if app.lastTitle != "" {
+		io.WriteString(app.out, _ANSI_POP_TITLE)
+		app.lastTitle = ""
+	}
+}
@@ -0,0 +1,20 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTitleControls(t *testing.T) {
+	app := &_Application{
+		Document: NewDocument(nil),
+		Config:   &Config{Filename: "a\a\x1B]0;evil\x1B\\.csv"},
+	}
+	title := app.title()
+	if strings.ContainsFunc(title, func(c rune) bool { return c < 0x20 }) {
+		t.Fatalf("the title has control characters: %q", title)
+	}
+	if expect := "csvi — a␇␛]0;evil␛\\.csv"; title != expect {
+		t.Fatalf("expect %q but %q", expect, title)
+	}
+}
@@ -0,0 +1,105 @@
+package csvi
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	exCommands["transform"] = &exCommand{
+		help:  "replace REGEXP with REPLACEMENT ($1 for the groups) in the current column after the preview (transform REGEXP REPLACEMENT | transform -undo)",
+		batch: true,
+		run:   cmdTransform,
+	}
+}
+
+// transformPreviewRows is the number of rows shown before applying `:transform`
+const transformPreviewRows = 10
+
+// cutBatchArg splits s into the first argument, which may be
+// double-quoted, and the rest.
+func cutBatchArg(s string) (string, string, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", "", err
+		}
+		arg, _ := strconv.Unquote(quoted)
+		return arg, strings.TrimSpace(s[len(quoted):]), nil
+	}
+	arg, rest, _ := strings.Cut(s, " ")
+	return arg, strings.TrimSpace(rest), nil
+}
+
+func cmdTransform(e *exCommandArgs) (string, error) {
+	const usage = "usage: transform REGEXP REPLACEMENT | transform -undo"
+	if e.Args == "-undo" {
+		return e.undoColumnEdit()
+	}
+	if e.ReadOnly {
+		return msgReadOnly, nil
+	}
+	pattern, rest, err := cutBatchArg(e.Args)
+	if err != nil {
+		return "", err
+	}
+	if pattern == "" {
+		return usage, nil
+	}
+	replacement, err := unquoteBatchText(rest)
+	if err != nil {
+		return "", err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	if err := e.fetchAll(); err != nil {
+		return "", err
+	}
+	col := e.CursorCol
+	var rows []*RowPtr
+	var texts []string
+	var preview []string
+	for p := e.Front(); p != nil; p = p.Next() {
+		if p.Index() < e.HeaderLines || col >= len(p.Cell) {
+			continue
+		}
+		old := p.Cell[col].Text()
+		text := re.ReplaceAllString(old, replacement)
+		if text == old {
+			continue
+		}
+		text, err := e.validate(p, col, text)
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", p.Index()+1, err)
+		}
+		rows = append(rows, p)
+		texts = append(texts, text)
+		if len(preview) < transformPreviewRows {
+			preview = append(preview, fmt.Sprintf("%d: %s → %s", p.Index()+1, old, text))
+		}
+	}
+	if len(rows) <= 0 {
+		return "no cells change", nil
+	}
+	if e.Config.Pilot != nil {
+		if len(rows) > len(preview) {
+			preview = append(preview, fmt.Sprintf("... and %d more", len(rows)-len(preview)))
+		}
+		title := fmt.Sprintf("%d cell(s) will change: [y]apply [q]cancel", len(rows))
+		defer e.view.clearCache()
+		key, _, err := e.listBox(title, preview, 0, e.lfCount, e.screenWidth, e.screenHeight)
+		if err != nil {
+			return "", err
+		}
+		if key != "y" {
+			return "transform cancelled", nil
+		}
+	}
+	e.setColumn(col, rows, texts)
+	return fmt.Sprintf("transformed %d cell(s) (`:transform -undo` restores them)", len(rows)), nil
+}
@@ -0,0 +1,113 @@
+package uncsv
+
+import (
+	"unicode/utf8"
+)
+
+// trimIncompleteRune removes the incomplete UTF-8 sequence at the end of
+// data which is cut off by the size of the buffer.
+func trimIncompleteRune(data []byte) []byte {
+	for i := 1; i <= utf8.UTFMax-1 && i <= len(data); i++ {
+		c := data[len(data)-i]
+		if c < 0x80 {
+			return data
+		}
+		if utf8.RuneStart(c) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			return data
+		}
+	}
+	return data
+}
+
+func isValidShiftJIS(data []byte) bool {
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c < 0x80, 0xA1 <= c && c <= 0xDF:
+			i++
+		case 0x81 <= c && c <= 0x9F, 0xE0 <= c && c <= 0xFC:
+			if i+1 >= len(data) {
+				return true
+			}
+			if t := data[i+1]; t < 0x40 || t == 0x7F || t > 0xFC {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isValidEUCJP(data []byte) bool {
+	inRange := func(i int, low, high byte) bool {
+		return i >= len(data) || (low <= data[i] && data[i] <= high)
+	}
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c < 0x80:
+			i++
+		case c == 0x8E:
+			if !inRange(i+1, 0xA1, 0xDF) {
+				return false
+			}
+			i += 2
+		case c == 0x8F:
+			if !inRange(i+1, 0xA1, 0xFE) || !inRange(i+2, 0xA1, 0xFE) {
+				return false
+			}
+			i += 3
+		case 0xA1 <= c && c <= 0xFE:
+			if !inRange(i+1, 0xA1, 0xFE) {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// GuessEncoding guesses the encoding of data read from the beginning of
+// a file. It returns "" for UTF-8 (or ASCII), otherwise one of
+// "UTF-16LE", "UTF-16BE", "EUC-JP", "Shift_JIS" and "ISO-8859-1".
+// The result is only a guess and should be confirmed by the user.
+func GuessEncoding(data []byte) string {
+	if len(data) <= 0 {
+		return ""
+	}
+	var evenNul, oddNul int
+	for i, c := range data {
+		if c == 0 {
+			if i%2 == 0 {
+				evenNul++
+			} else {
+				oddNul++
+			}
+		}
+	}
+	if quarter := len(data) / 4; oddNul > quarter && oddNul > evenNul*4 {
+		return "UTF-16LE"
+	} else if evenNul > quarter && evenNul > oddNul*4 {
+		return "UTF-16BE"
+	}
+	if utf8.Valid(trimIncompleteRune(data)) {
+		return ""
+	}
+	// Text in Shift_JIS usually contains the lead bytes 0x82 and 0x83
+	// which are invalid in EUC-JP, while text in EUC-JP is often valid
+	// as Shift_JIS too. So EUC-JP is tested first.
+	if isValidEUCJP(data) {
+		return "EUC-JP"
+	}
+	if isValidShiftJIS(data) {
+		return "Shift_JIS"
+	}
+	return "ISO-8859-1"
+}
@@ -39,10 +39,35 @@ type Mode struct {
 	NonUTF8     bool
 	Comma       byte
 	DefaultTerm string
-	hasBom      tristate
-	endian      endian
-	decoder     *encoding.Decoder
-	encoder     *encoding.Encoder
+	// ForceTerm replaces the terminators of all rows except for the last
+	// one without it when the rows are rebuilt. The rows themselves keep
+	// their original terminators.
+	ForceTerm string
+	// LazyText makes cells read keep only their source bytes and decode
+	// their texts every time Cell.Text is called. It reduces the memory
+	// for data mostly viewed and not edited.
+	LazyText bool
+	// MaxCellLength is the maximum length in bytes of the source of a cell
+	// whose text is decoded on reading. Zero means no limit. The text of a
+	// longer cell is cut there, but its source is kept whole, so that the
+	// rows are written back as they were read.
+	MaxCellLength int
+	// TruncatedMarker is the mark which viewers draw after the text of
+	// cells cut by MaxCellLength. Neither the text nor the source of the
+	// cells contain it.
+	TruncatedMarker string
+
+	hasBom  tristate
+	endian  endian
+	decoder *encoding.Decoder
+	encoder *encoding.Encoder
+	name    string
+}
+
+// EncodingName returns the name of the encoding set by SetEncoding.
+// It returns "" when no encodings are set.
+func (m *Mode) EncodingName() string {
+	return m.name
 }
 
 func (m *Mode) IsUTF16LE() bool {
@@ -92,7 +117,33 @@ func (m *Mode) setEncoding(e encoding.Encoding) {
 	m.encoder = e.NewEncoder()
 }
 
+// encodingAliases maps short names often used on command lines
+// to the names registered in IANA
+var encodingAliases = map[string]string{
+	"sjis":    "Shift_JIS",
+	"cp932":   "Shift_JIS",
+	"eucjp":   "EUC-JP",
+	"jis":     "ISO-2022-JP",
+	"latin1":  "ISO-8859-1",
+	"latin9":  "ISO-8859-15",
+	"cp1251":  "windows-1251",
+	"cp1252":  "windows-1252",
+	"cp437":   "IBM437",
+	"cp936":   "GBK",
+	"cp949":   "EUC-KR",
+	"cp950":   "Big5",
+	"euckr":   "EUC-KR",
+	"koi8r":   "KOI8-R",
+	"gb18030": "GB18030",
+}
+
+// SetEncoding sets the encoding to read and write NonUTF8 text.
+// The name is an IANA-registered name or one of short aliases like
+// sjis, eucjp, latin1 and cp1252.
 func (m *Mode) SetEncoding(name string) error {
+	if alias, ok := encodingAliases[strings.ReplaceAll(strings.ToLower(name), "-", "")]; ok {
+		name = alias
+	}
 	e, err := ianaindex.IANA.Encoding(name)
 	if err != nil {
 		return err
@@ -101,6 +152,7 @@ func (m *Mode) SetEncoding(name string) error {
 		return fmt.Errorf("%s: not supported in golang.org/x/text/encoding/ianaindex", name)
 	}
 	m.setEncoding(e)
+	m.name = name
 	return nil
 }
 
@@ -108,6 +160,12 @@ func (m *Mode) HasBom() bool {
 	return m.hasBom == triTrue
 }
 
+// Decode returns the text of s in the encoding of the mode, like the bytes
+// of Row.Rebuild
+func (m *Mode) Decode(s []byte) string {
+	return m.decode(s)
+}
+
 func (m *Mode) decode(s []byte) string {
 	if !m.NonUTF8 && utf8.Valid(s) {
 		return string(s)
@@ -144,12 +202,70 @@ type Cell struct {
 	source   []byte
 	text     string
 	original []byte
+	// lazy is set instead of text when Mode.LazyText is true.
+	// Then text is decoded from source every time it is required.
+	lazy *Mode
+	// limit is the length of the original whose text is decoded when it
+	// is longer than Mode.MaxCellLength. Zero means the whole.
+	limit int
+}
+
+// cutLength returns the length of source decoded for Mode.MaxCellLength,
+// which does not split a character of UTF-8 or UTF-16. It returns zero
+// when source is not longer than the maximum.
+func (mode *Mode) cutLength(source []byte) int {
+	n := mode.MaxCellLength
+	if n <= 0 || len(source) <= n {
+		return 0
+	}
+	if mode.endian != octet {
+		n &^= 1
+	} else if !mode.NonUTF8 {
+		for i := n; i > 0 && i > n-utf8.UTFMax; i-- {
+			if utf8.RuneStart(source[i]) {
+				n = i
+				break
+			}
+		}
+	}
+	return max(n, 1)
+}
+
+// readCell makes a cell read from the source
+func (mode *Mode) readCell(source []byte) Cell {
+	c := Cell{
+		source:   source,
+		original: source,
+		limit:    mode.cutLength(source),
+	}
+	if mode.LazyText {
+		c.lazy = mode
+		return c
+	}
+	c.text = c.decodeText(mode)
+	return c
+}
+
+func (c Cell) decodeText(mode *Mode) string {
+	if c.limit > 0 && bytes.Equal(c.source, c.original) {
+		return dequote(mode.decode(c.source[:c.limit]))
+	}
+	return dequote(mode.decode(c.source))
 }
 
 func (c Cell) Text() string {
+	if c.lazy != nil {
+		return c.decodeText(c.lazy)
+	}
 	return c.text
 }
 
+// Truncated returns true when the text of the cell is cut by
+// Mode.MaxCellLength. It becomes false when the cell is changed.
+func (c Cell) Truncated() bool {
+	return c.limit > 0 && bytes.Equal(c.source, c.original)
+}
+
 func (c Cell) Source() []byte {
 	return c.source
 }
@@ -158,6 +274,11 @@ func (c Cell) SourceText(m *Mode) string {
 	return m.decode(c.source)
 }
 
+// OriginalText returns the source text which the cell had when it was read
+func (c Cell) OriginalText(m *Mode) string {
+	return m.decode(c.original)
+}
+
 func (c Cell) Modified() bool {
 	return !bytes.Equal(c.source, c.original)
 }
@@ -170,13 +291,19 @@ func (c Cell) IsQuoted() bool {
 
 func (c *Cell) Restore(mode *Mode) {
 	c.source = c.original
-	c.text = dequote(mode.decode(c.original))
+	c.text = c.decodeText(mode)
 }
 
 func (c *Cell) Original() []byte {
 	return c.original
 }
 
+// SetOriginal makes the source of base the original of the cell,
+// so that Modified and Restore compare the cell with base.
+func (c *Cell) SetOriginal(base Cell) {
+	c.original = base.source
+}
+
 type Row struct {
 	// Cell must have one or more element at least
 	Cell []Cell
@@ -221,11 +348,7 @@ func ReadLine(br *bufio.Reader, mode *Mode) (*Row, error) {
 		for {
 			c, err := br.ReadByte()
 			if err != nil {
-				row.Cell = append(row.Cell, Cell{
-					source:   source,
-					text:     dequote(mode.decode(source)),
-					original: source,
-				})
+				row.Cell = append(row.Cell, mode.readCell(source))
 				row.Term = ""
 				return row, err
 			}
@@ -235,11 +358,7 @@ func ReadLine(br *bufio.Reader, mode *Mode) (*Row, error) {
 			if !quoted {
 				switch c {
 				case mode.Comma:
-					row.Cell = append(row.Cell, Cell{
-						source:   source,
-						text:     dequote(mode.decode(source)),
-						original: source,
-					})
+					row.Cell = append(row.Cell, mode.readCell(source))
 					source = []byte{}
 					continue
 				case '\n':
@@ -249,11 +368,7 @@ func ReadLine(br *bufio.Reader, mode *Mode) (*Row, error) {
 					} else {
 						row.Term = "\n"
 					}
-					row.Cell = append(row.Cell, Cell{
-						source:   source,
-						text:     dequote(mode.decode(source)),
-						original: source,
-					})
+					row.Cell = append(row.Cell, mode.readCell(source))
 					if mode.DefaultTerm == "" {
 						mode.DefaultTerm = row.Term
 					}
@@ -267,11 +382,7 @@ func ReadLine(br *bufio.Reader, mode *Mode) (*Row, error) {
 			var buf [2]byte
 			n, err := io.ReadFull(br, buf[:])
 			if err != nil {
-				row.Cell = append(row.Cell, Cell{
-					source:   source,
-					text:     dequote(mode.decode(source)),
-					original: source,
-				})
+				row.Cell = append(row.Cell, mode.readCell(source))
 				row.Term = ""
 				return row, err
 			}
@@ -287,11 +398,7 @@ func ReadLine(br *bufio.Reader, mode *Mode) (*Row, error) {
 			if !quoted {
 				switch c {
 				case rune(mode.Comma):
-					row.Cell = append(row.Cell, Cell{
-						source:   source,
-						text:     dequote(mode.decode(source)),
-						original: source,
-					})
+					row.Cell = append(row.Cell, mode.readCell(source))
 					source = []byte{}
 					continue
 				case '\n':
@@ -302,11 +409,7 @@ func ReadLine(br *bufio.Reader, mode *Mode) (*Row, error) {
 					} else {
 						row.Term = "\n"
 					}
-					row.Cell = append(row.Cell, Cell{
-						source:   source,
-						text:     dequote(mode.decode(source)),
-						original: source,
-					})
+					row.Cell = append(row.Cell, mode.readCell(source))
 					if mode.DefaultTerm == "" {
 						mode.DefaultTerm = row.Term
 					}
@@ -358,8 +461,12 @@ func (row *Row) Rebuild(mode *Mode) []byte {
 			writeEndian(&buffer, mode.Comma, mode.endian)
 		}
 	}
-	for i := 0; i < len(row.Term); i++ {
-		writeEndian(&buffer, row.Term[i], mode.endian)
+	term := row.Term
+	if term != "" && mode.ForceTerm != "" {
+		term = mode.ForceTerm
+	}
+	for i := 0; i < len(term); i++ {
+		writeEndian(&buffer, term[i], mode.endian)
 	}
 	return buffer.Bytes()
 }
@@ -423,7 +530,10 @@ func newCell(text string, mode *Mode) Cell {
 }
 
 func (c Cell) Quote(mode *Mode) Cell {
-	text := c.text
+	text := c.Text()
+	if c.Truncated() {
+		text = dequote(mode.decode(c.source))
+	}
 	source := make([]byte, 0, len(text))
 	source = append(source, '"')
 	for i, end := 0, len(text); i < end; i++ {
@@ -438,7 +548,7 @@ func (c Cell) Quote(mode *Mode) Cell {
 			source = s
 		}
 	}
-	return Cell{source: source, text: text, original: c.original}
+	return Cell{source: source, text: text, original: c.original, limit: c.limit}
 }
 
 func NewRow(mode *Mode) Row {
@@ -453,9 +563,10 @@ func (row *Row) Insert(i int, text string, mode *Mode) {
 }
 
 func (row *Row) Replace(i int, text string, mode *Mode) {
-	original := row.Cell[i].original
+	original, limit := row.Cell[i].original, row.Cell[i].limit
 	row.Cell[i] = newCell(text, mode)
 	row.Cell[i].original = original
+	row.Cell[i].limit = limit
 }
 
 func (row *Row) Delete(i int) {
@@ -77,3 +77,166 @@ func TestDeleteWithBom(t *testing.T) {
 		r[0].Delete(0)
 	})
 }
+
+func TestForceTerm(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("a,b\r\nc,d\ne,f"))
+	mode := &Mode{Comma: ',', ForceTerm: "\n"}
+	var result strings.Builder
+	for {
+		row, err := ReadLine(r, mode)
+		result.Write(row.Rebuild(mode))
+		if err != nil {
+			break
+		}
+		if row.Cell[0].Text() == "a" && row.Term != "\r\n" {
+			t.Fatalf("the original terminator is changed to %q", row.Term)
+		}
+	}
+	if expect := "a,b\nc,d\ne,f"; result.String() != expect {
+		t.Fatalf("expect %q but %q", expect, result.String())
+	}
+}
+
+func TestLazyText(t *testing.T) {
+	source := "\"ab\"\"c\",d\n"
+	r := bufio.NewReader(strings.NewReader(source))
+	mode := &Mode{Comma: ',', LazyText: true}
+	row, err := ReadLine(r, mode)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if text := row.Cell[0].Text(); text != `ab"c` {
+		t.Fatalf("expect %q but %q", `ab"c`, text)
+	}
+	if q := row.Cell[0].Quote(mode); q.Text() != `ab"c` {
+		t.Fatalf("Quote: expect %q but %q", `ab"c`, q.Text())
+	}
+	row.Replace(1, "e", mode)
+	if j := string(row.Rebuild(mode)); j != "\"ab\"\"c\",e\n" {
+		t.Fatalf("Rebuild: %q", j)
+	}
+}
+
+func TestSetEncodingAlias(t *testing.T) {
+	mode := &Mode{Comma: ','}
+	if err := mode.SetEncoding("latin1"); err != nil {
+		t.Fatal(err.Error())
+	}
+	mode.NonUTF8 = true
+	r := bufio.NewReader(strings.NewReader("caf\xE9,na\xEFve\n"))
+	row, err := ReadLine(r, mode)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if text := row.Cell[0].Text(); text != "café" {
+		t.Fatalf("expect café but %v", text)
+	}
+	row.Replace(1, "déjà", mode)
+	if result := string(row.Rebuild(mode)); result != "caf\xE9,d\xE9j\xE0\n" {
+		t.Fatalf("expect latin1 bytes but %q", result)
+	}
+	if err := mode.SetEncoding("EUC-JP"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := mode.SetEncoding("no-such-encoding"); err == nil {
+		t.Fatal("expect error for an unknown encoding")
+	}
+}
+
+func TestGuessEncoding(t *testing.T) {
+	tests := []struct {
+		source string
+		expect string
+	}{
+		{"abc,def\n", ""},
+		{"あいう,えお\n", ""},
+		{"\x82\xA0\x82\xA2,\x83A\n", "Shift_JIS"},
+		{"\xA4\xA2\xA4\xA4,\xA5\xA2\n", "EUC-JP"},
+		{"caf\xE9,na\xEFve\n", "ISO-8859-1"},
+		{"a\x00b\x00,\x00c\x00", "UTF-16LE"},
+		{"\x00a\x00b\x00,\x00c", "UTF-16BE"},
+		// the last rune is cut by the size of the buffer
+		{"abc\xE3\x81", ""},
+	}
+	for _, tt := range tests {
+		if result := GuessEncoding([]byte(tt.source)); result != tt.expect {
+			t.Errorf("GuessEncoding(%q): expect %q but %q", tt.source, tt.expect, result)
+		}
+	}
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	for _, source := range []string{
+		"a,b\r\n\"c,d\",\"e\"\"f\"\r\n",
+		"\uFEFFa,b\nc,d",
+		"a,\"b\nc\"\n",
+	} {
+		if err := VerifyRoundTrip(strings.NewReader(source), &Mode{Comma: ','}); err != nil {
+			t.Fatalf("%q: %s", source, err.Error())
+		}
+	}
+	mode := &Mode{Comma: ',', ForceTerm: "\n"}
+	err := VerifyRoundTrip(strings.NewReader("a,b\nc,d\r\ne,f\n"), mode)
+	e, ok := err.(*RoundTripError)
+	if !ok {
+		t.Fatalf("expect RoundTripError but %v", err)
+	}
+	if e.Offset != 7 || e.Row != 2 {
+		t.Fatalf("expect offset 7 on row 2 but %d on row %d", e.Offset, e.Row)
+	}
+}
+
+func TestMaxCellLength(t *testing.T) {
+	mode := &Mode{Comma: ',', MaxCellLength: 4, TruncatedMarker: "~"}
+	source := "a,\"bcdef,g\"\r\nq,\"r\"\"st\",\u3042\u3044\r\n"
+	rows, err := ReadAll(strings.NewReader(source), mode)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expect := [][]string{{"a", "bcd"}, {"q", "r\"", "\u3042"}, {""}}
+	truncated := [][]bool{{false, true}, {false, true, true}, {false}}
+	if len(rows) != len(expect) {
+		t.Fatalf("expect %d rows but %d", len(expect), len(rows))
+	}
+	for i, row := range rows {
+		if len(row.Cell) != len(expect[i]) {
+			t.Fatalf("row %d: expect %v but %d cells", i, expect[i], len(row.Cell))
+		}
+		for j, c := range row.Cell {
+			if c.Text() != expect[i][j] {
+				t.Fatalf("(%d,%d): expect %q but %q", j, i, expect[i][j], c.Text())
+			}
+			if c.Truncated() != truncated[i][j] {
+				t.Fatalf("(%d,%d): Truncated() returns %v", j, i, c.Truncated())
+			}
+		}
+		if i < 2 && row.Term != "\r\n" {
+			t.Fatalf("row %d: expect CRLF but %q", i, row.Term)
+		}
+	}
+	var buffer strings.Builder
+	mode.Dump(rows, &buffer)
+	if result := buffer.String(); result != source {
+		t.Fatalf("expect %q but %q", source, result)
+	}
+	rows[0].Replace(1, "x", mode)
+	if rows[0].Cell[1].Truncated() {
+		t.Fatal("the cell replaced is still truncated")
+	}
+	rows[0].Cell[1].Restore(mode)
+	if c := rows[0].Cell[1]; c.Text() != "bcd" || !c.Truncated() {
+		t.Fatalf("the cell restored is %q", c.Text())
+	}
+}
+
+func TestDecodeRebuild(t *testing.T) {
+	source := "\xFF\xFEa\x00,\x00\"\x00b\x00\"\x00\r\x00\n\x00"
+	mode := &Mode{Comma: ','}
+	row, err := ReadLine(bufio.NewReader(strings.NewReader(source)), mode)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if text := mode.Decode(row.Rebuild(mode)); text != "a,\"b\"\r\n" {
+		t.Fatalf("expect %q but %q", "a,\"b\"\r\n", text)
+	}
+}
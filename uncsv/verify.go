@@ -0,0 +1,62 @@
+package uncsv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// RoundTripError is returned by VerifyRoundTrip when the rebuilt data
+// differs from the input.
+type RoundTripError struct {
+	// Offset is the first byte offset where the output differs
+	Offset int64
+	// Row is the 1-based number of the row containing Offset
+	Row int
+}
+
+func (e *RoundTripError) Error() string {
+	return fmt.Sprintf("offset %d (row %d): the rebuilt data differs from the input", e.Offset, e.Row)
+}
+
+// VerifyRoundTrip parses all rows of r and rebuilds them in mode, and
+// returns a *RoundTripError when the result would not be byte-identical
+// to the input.
+func VerifyRoundTrip(r io.Reader, mode *Mode) error {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	rows, err := ReadAll(bytes.NewReader(source), mode)
+	if err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	mode.Dump(rows, &output)
+	rebuilt := output.Bytes()
+
+	offset := 0
+	for offset < len(source) && offset < len(rebuilt) && source[offset] == rebuilt[offset] {
+		offset++
+	}
+	if offset == len(source) && offset == len(rebuilt) {
+		return nil
+	}
+	// find the row which the offset belongs to
+	pos := 0
+	if mode.HasBom() {
+		pos = 3
+		if mode.endian != octet {
+			pos = 2
+		}
+	}
+	rowNum := len(rows)
+	for i := range rows {
+		pos += len(rows[i].Rebuild(mode))
+		if offset < pos {
+			rowNum = i + 1
+			break
+		}
+	}
+	return &RoundTripError{Offset: int64(offset), Row: rowNum}
+}
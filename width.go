@@ -0,0 +1,136 @@
+package csvi
+
+import (
+	"maps"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// the range of the widths derived by Config.AutoWidthRows
+const (
+	autoMinWidth = 4
+	autoMaxWidth = 40
+)
+
+// guessColumnWidths derives the widths of the columns from the widest
+// texts of the header lines and the first AutoWidthRows rows loaded.
+func (app *_Application) guessColumnWidths() {
+	widths := map[int]int{}
+	n := app.HeaderLines + app.AutoWidthRows
+	for p := app.Front(); p != nil && p.Index() < n; p = p.Next() {
+		for i, c := range p.Cell {
+			widths[i] = max(widths[i], runewidth.StringWidth(app.displayText(c)))
+		}
+	}
+	// a space between columns
+	gap := 1 + runewidth.StringWidth(app.ColumnSeparator)
+	for i, w := range widths {
+		widths[i] = min(max(w+gap, autoMinWidth), autoMaxWidth)
+	}
+	app.autoWidths = widths
+}
+
+// updateColumnWidths resolves the columns of Config.ColumnMinWidth,
+// Config.ColumnMaxWidth and Config.ElasticColumn with the current header
+// into columnWidths, so that renaming a column takes effect on the next
+// frame.
+func (app *_Application) updateColumnWidths(cellWidth, screenWidth int) {
+	if len(app.ColumnMinWidth) <= 0 && len(app.ColumnMaxWidth) <= 0 && !app.Elastic {
+		app.columnWidths = app.autoWidths
+		return
+	}
+	widths := maps.Clone(app.autoWidths)
+	if widths == nil {
+		widths = map[int]int{}
+	}
+	width := func(col int) int {
+		if w, ok := widths[col]; ok {
+			return w
+		}
+		return cellWidth
+	}
+	limited := map[int]bool{}
+	for name, max := range app.ColumnMaxWidth {
+		if col, err := app.columnIndex(name); err == nil && max > 0 {
+			widths[col] = min(width(col), max)
+			limited[col] = true
+		}
+	}
+	for name, min := range app.ColumnMinWidth {
+		if col, err := app.columnIndex(name); err == nil {
+			widths[col] = max(width(col), min)
+		}
+	}
+	if app.Elastic && app.Len() > 0 {
+		app.stretchColumns(widths, limited, len(app.Front().Cell), cellWidth, screenWidth-1)
+	}
+	app.columnWidths = widths
+}
+
+// stretchColumns widens the n columns when all of them are narrower than
+// screenWidth. Config.ElasticColumn takes all the spare width, or the
+// columns not limited by Config.ColumnMaxWidth share it in proportion to
+// their widths.
+func (app *_Application) stretchColumns(widths map[int]int, limited map[int]bool, n, cellWidth, screenWidth int) {
+	width := func(col int) int {
+		if w, ok := widths[col]; ok {
+			return w
+		}
+		return cellWidth
+	}
+	total := 0
+	for col := 0; col < n; col++ {
+		total += width(col)
+	}
+	spare := screenWidth - total
+	if spare <= 0 {
+		return
+	}
+	if app.ElasticColumn != "" {
+		if col, err := app.columnIndex(app.ElasticColumn); err == nil && col < n {
+			widths[col] = width(col) + spare
+		}
+		return
+	}
+	var targets []int
+	sum := 0
+	for col := 0; col < n; col++ {
+		if !limited[col] {
+			targets = append(targets, col)
+			sum += width(col)
+		}
+	}
+	given := 0
+	for i, col := range targets {
+		add := spare * width(col) / sum
+		if i == len(targets)-1 {
+			add = spare - given
+		}
+		widths[col] = width(col) + add
+		given += add
+	}
+}
+
+// columnWidth returns the width of the column col on the screen
+func (cfg *Config) columnWidth(col, cellWidth int) int {
+	if w, ok := cfg.columnWidths[col]; ok {
+		return w
+	}
+	return cellWidth
+}
+
+// screenX returns the 0-based position on the screen where the column
+// col is drawn from when the columns are drawn from startCol.
+func (cfg *Config) screenX(col, startCol, cellWidth int) int {
+	x := 0
+	for i := 0; i < cfg.screenCol(col, startCol); i++ {
+		x += cfg.columnWidth(cfg.columnAt(startCol, i), cellWidth)
+	}
+	return x
+}
+
+// isColumnVisible returns true when the whole of the column col is drawn
+// in screenWidth when the columns are drawn from startCol.
+func (cfg *Config) isColumnVisible(col, startCol, cellWidth, screenWidth int) bool {
+	return cfg.screenX(col, startCol, cellWidth)+cfg.columnWidth(col, cellWidth) <= screenWidth
+}
@@ -0,0 +1,75 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColumnWidths(t *testing.T) {
+	cfg := &Config{
+		HeaderLines:    1,
+		ColumnMinWidth: map[string]int{"id": 20},
+		ColumnMaxWidth: map[string]int{"2": 5, "3": 30},
+	}
+	_, out := runEdit(t, cfg, "q|y", "id,name,note,x\n1,a,b,c\n")
+	// id: 20 (min), name: 5 (max), note: 14 (the max is wider than CellWidth)
+	if !strings.Contains(out, "\x1B[21Ga\x1B[26Gb\x1B[40Gc") {
+		t.Fatalf("the columns are not drawn in their widths: %q", out)
+	}
+}
+
+func TestAutoWidth(t *testing.T) {
+	cfg := &Config{
+		HeaderLines:    1,
+		AutoWidthRows:  1,
+		ColumnMaxWidth: map[string]int{"description": 10},
+	}
+	_, out := runEdit(t, cfg, "q|y", "id,name,description,x\n1,Alice,long text,y\n22,Bob,short,z\n33333333,Carol,-,w\n")
+	// id: 4 (the minimum), name: 6, description: 10 (the maximum), and
+	// the row 3 is not measured.
+	if !strings.Contains(out, "1\x1B[5GAlice\x1B[11Glong text\x1B[21Gy") {
+		t.Fatalf("the widths are not derived from the texts: %q", out)
+	}
+}
+
+func TestElastic(t *testing.T) {
+	cfg := &Config{
+		HeaderLines: 1,
+		CellWidth:   10,
+		Elastic:     true,
+	}
+	source := "id,name,x\n1,a,b\n"
+	_, out := runEdit(t, cfg, "q|y", source)
+	// 79 columns of the screen are shared: 26, 26 and 27
+	if !strings.Contains(out, "1\x1B[27Ga\x1B[53Gb") {
+		t.Fatalf("the columns are not widened in proportion: %q", out)
+	}
+
+	cfg.ElasticColumn = "name"
+	_, out = runEdit(t, cfg, "q|y", source)
+	if !strings.Contains(out, "1\x1B[11Ga\x1B[70Gb") {
+		t.Fatalf("the spare width is not given to the column: %q", out)
+	}
+}
+
+func TestCutStrInWidth(t *testing.T) {
+	tests := []struct {
+		source string
+		width  int
+		expect string
+	}{
+		{"abcdef", 3, "abc"},
+		{"あいう", 5, "あい"},
+		// e + COMBINING ACUTE ACCENT must not be separated
+		{"ae\u0301b", 2, "ae\u0301"},
+		// family: man ZWJ woman ZWJ girl is one cluster of width 2
+		{"\U0001F468\u200d\U0001F469\u200d\U0001F467x", 1, ""},
+		{"\U0001F468\u200d\U0001F469\u200d\U0001F467x", 2, "\U0001F468\u200d\U0001F469\u200d\U0001F467"},
+	}
+	for _, tt := range tests {
+		result, _ := cutStrInWidth(tt.source, tt.width)
+		if result != tt.expect {
+			t.Errorf("cutStrInWidth(%q,%d): expect %q but %q", tt.source, tt.width, tt.expect, result)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package csvi
+
+import (
+	"io"
+)
+
+func init() {
+	exCommands["wrap"] = &exCommand{
+		help: "toggle wrapping long texts of cells",
+		run: func(e *exCommandArgs) (string, error) {
+			e.Wrap = !e.Wrap
+			e.view.clearCache()
+			if e.Wrap {
+				return "wrap on", nil
+			}
+			return "wrap off", nil
+		},
+	}
+}
+
+// rowHeight returns the number of screen lines which the row occupies
+// in the wrap mode.
+func rowHeight(cfg *Config, row *RowPtr, cellWidth, startCol, cursorPos, screenWidth int) int {
+	return drawLine(cfg, row.Row, cfg.cellsFrom(row.Cell, startCol), startCol, true, cellWidth, screenWidth-1, cursorPos, 0, false, &bodyColorStyle, nil, io.Discard)
+}
+
+// scrollForWrap returns the row to start drawing the body from so that
+// the whole of cursorRow fits in bodyLines screen lines.
+func scrollForWrap(cfg *Config, startRow, cursorRow *RowPtr, cellWidth, headerLines, startCol, cursorCol, bodyLines, screenWidth int) *RowPtr {
+	total := rowHeight(cfg, cursorRow, cellWidth, startCol, cfg.screenCol(cursorCol, startCol), screenWidth)
+	p := cursorRow.Clone()
+	for p.Index() > startRow.Index() && p.Index() > headerLines {
+		prev := p.Prev()
+		total += rowHeight(cfg, prev, cellWidth, startCol, -1, screenWidth)
+		if total > bodyLines {
+			return p
+		}
+		p = prev
+	}
+	return startRow
+}
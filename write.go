@@ -1,53 +1,161 @@
 package csvi
 
 import (
-	"flag"
+	"compress/gzip"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
-
-	"github.com/hymkor/csvi/uncsv"
+	"strings"
 )
 
 var overWritten = map[string]struct{}{}
 
+func init() {
+	exCommands["x"] = &exCommand{
+		help: "save to the original file if modified and quit",
+		run:  cmdExit,
+	}
+}
+
+func cmdExit(e *exCommandArgs) (string, error) {
+	quit, err := saveIfDirty(e._Application, e.fetchAll)
+	if err != nil {
+		return "", err
+	}
+	e.quit = quit
+	return "", nil
+}
+
+// saveIfDirty saves the data to the original file when it is modified
+// and returns true unless the data is still modified.
+func saveIfDirty(app *_Application, fetchAll func() error) (bool, error) {
+	if !app.dirty {
+		return true, nil
+	}
+	if err := fetchAll(); err != nil {
+		return false, err
+	}
+	if err := cmdQuickSave(app); err != nil {
+		return false, err
+	}
+	return !app.dirty, nil
+}
+
 func dump(app *_Application, w io.Writer) {
-	cursor := app.Front()
-	app.Config.Mode.DumpBy(
-		func() *uncsv.Row {
-			if cursor == nil {
-				return nil
-			}
-			row := cursor.Row
-			cursor = cursor.Next()
-			return row
-		}, w)
+	app.Document.Dump(w)
 }
 
-func cmdWrite(app *_Application) error {
-	fname := "-"
-	var err error
-	if args := flag.Args(); len(args) >= 1 {
-		fname, err = filepath.Abs(args[0])
-		if err != nil {
-			return err
+// defaultSaveName returns the name offered on the prompt of `w`
+func (app *_Application) defaultSaveName() (string, error) {
+	if app.Filename == "" {
+		return "-", nil
+	}
+	if strings.Contains(app.Filename, "://") {
+		// Remote sources are saved as a local file with the same base name.
+		fname := path.Base(app.Filename)
+		if i := strings.IndexAny(fname, "?#"); i >= 0 {
+			fname = fname[:i]
 		}
+		return fname, nil
+	}
+	return filepath.Abs(app.Filename)
+}
+
+func cmdWrite(app *_Application) error {
+	fname, err := app.defaultSaveName()
+	if err != nil {
+		return err
 	}
 	fname, err = app.GetFilename(app, "write to>", fname)
 	if err != nil {
 		return nil
 	}
+	fname, _ = expandHome(fname)
+	return app.save(fname, false)
+}
+
+// cmdQuickSave saves the data to the original file without the prompt.
+// When the name of the original file is unknown, it asks one as `w`.
+func cmdQuickSave(app *_Application) error {
+	if app.Filename == "" || strings.Contains(app.Filename, "://") {
+		return cmdWrite(app)
+	}
+	return app.save(app.Filename, true)
+}
+
+func (app *_Application) save(fname string, force bool) error {
+	e := &SaveEvent{Result: &Result{_Application: app}, Filename: fname, Force: force}
+	saver := app.Saver
+	if saver == nil && app.OnSave != nil {
+		saver = SaveFunc(app.OnSave)
+	}
+	if saver == nil {
+		saver = FileSaver{}
+	}
+	if err := saver.Save(e); errors.Is(err, ErrNotSaved) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	app.markSaved(fname)
+	if app.GitCommit && fname != "-" {
+		return app.gitCommit(fname)
+	}
+	return nil
+}
+
+// markSaved records that the data is saved as fname
+func (app *_Application) markSaved(fname string) {
+	app.dirty = false
+	app.saved = true
+	if fname != "-" {
+		app.recentFiles.add(fname)
+	}
+}
+
+// ErrNotSaved is returned by Saver when the data is not saved without
+// failures, like when the user declines to overwrite the file.
+var ErrNotSaved = errors.New("not saved")
+
+// Saver saves the data to the destination named on the prompt of `w`.
+// The data is regarded as saved when Save returns nil, and stays modified
+// when it returns an error or ErrNotSaved.
+type Saver interface {
+	Save(*SaveEvent) error
+}
+
+// SaveFunc is the function used as a Saver
+type SaveFunc func(*SaveEvent) error
+
+func (f SaveFunc) Save(e *SaveEvent) error {
+	return f(e)
+}
+
+// FileSaver is the default Saver. It writes the data to the local file,
+// or to STDOUT for `-`. An existing file is renamed with `~` appended
+// after the confirmation.
+type FileSaver struct{}
+
+func (FileSaver) Save(e *SaveEvent) error {
+	app := e._Application
+	fname := e.Filename
 	if fname == "-" {
 		dump(app, os.Stdout)
 		return nil
 	}
+	if err := checkWritableSuffix(fname); err != nil {
+		return err
+	}
 	fd, err := os.OpenFile(fname, os.O_WRONLY|os.O_EXCL|os.O_CREATE, 0666)
 	if os.IsExist(err) {
 		if _, ok := overWritten[fname]; ok {
 			os.Remove(fname)
 		} else {
-			if !app.YesNo("Overwrite as \"" + fname + "\" [y/n] ?") {
-				return nil
+			if !e.Force && !app.NoConfirmOverwrite && !app.confirm(ConfirmOverwrite, "Overwrite as \""+fname+"\" [y/n] ?") {
+				return ErrNotSaved
 			}
 			backupName := fname + "~"
 			os.Remove(backupName)
@@ -59,9 +167,51 @@ func cmdWrite(app *_Application) error {
 	if err != nil {
 		return err
 	}
-	dump(app, fd)
-	if err := fd.Close(); err != nil {
+	return writeAndClose(app, fname, fd)
+}
+
+// WriteFile writes all rows into the file name in the mode of the data.
+// The file is compressed when name ends with .gz and is overwritten
+// when it exists.
+func (app *_Application) WriteFile(name string) error {
+	if err := checkWritableSuffix(name); err != nil {
+		return err
+	}
+	fd, err := os.Create(name)
+	if err != nil {
 		return err
 	}
+	return writeAndClose(app, name, fd)
+}
+
+// checkWritableSuffix returns an error when fname ends with the suffix of
+// a compression which can not be written.
+func checkWritableSuffix(fname string) error {
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".bz2":
+		return fmt.Errorf("%s: writing this compression is not supported", fname)
+	}
 	return nil
 }
+
+// writeAndClose dumps all rows into fd, which is compressed when fname
+// ends with .gz, and closes fd.
+func writeAndClose(app *_Application, fname string, fd io.WriteCloser) error {
+	return dumpAndClose(fname, fd, func(w io.Writer) { dump(app, w) })
+}
+
+// dumpAndClose calls dump with fd, which is compressed when fname
+// ends with .gz, and closes fd.
+func dumpAndClose(fname string, fd io.WriteCloser, dump func(io.Writer)) error {
+	if strings.EqualFold(filepath.Ext(fname), ".gz") {
+		zw := gzip.NewWriter(fd)
+		dump(zw)
+		if err := zw.Close(); err != nil {
+			fd.Close()
+			return err
+		}
+	} else {
+		dump(fd)
+	}
+	return fd.Close()
+}
@@ -0,0 +1,80 @@
+package csvi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hymkor/csvi/uncsv"
+)
+
+func TestSaver(t *testing.T) {
+	var saved strings.Builder
+	var savedName string
+	cfg := &Config{
+		Saver: SaveFunc(func(e *SaveEvent) error {
+			savedName = e.Filename
+			e.Each(func(row *uncsv.Row) bool {
+				saved.Write(row.Rebuild(e.Mode))
+				return true
+			})
+			return nil
+		}),
+	}
+	runEdit(t, cfg, "r|X|w|mem|q|y", "a,b\nc,d\n")
+	if savedName != "mem" {
+		t.Fatalf("expect mem but %q", savedName)
+	}
+	if expect := "X,b\nc,d\n"; saved.String() != expect {
+		t.Fatalf("expect %q but %q", expect, saved.String())
+	}
+}
+
+func TestOnSave(t *testing.T) {
+	var savedName string
+	cfg := &Config{
+		OnSave: func(e *SaveEvent) error {
+			savedName = e.Filename
+			return nil
+		},
+	}
+	result, _ := runEdit(t, cfg, "r|X|w|mem|q|y", "a,b\n")
+	if savedName != "mem" {
+		t.Fatalf("expect mem but %q", savedName)
+	}
+	if result.Modified() {
+		t.Fatal("expect the data saved by OnSave")
+	}
+}
+
+func TestQuickSaveWithoutFilename(t *testing.T) {
+	var events []SaveEvent
+	cfg := &Config{
+		Saver: SaveFunc(func(e *SaveEvent) error {
+			events = append(events, *e)
+			return nil
+		}),
+	}
+	runEdit(t, cfg, "r|X|W|out.csv|q|y", "a,b\n")
+	if len(events) != 1 || events[0].Filename != "out.csv" || events[0].Force {
+		t.Fatalf("expect out.csv asked by the prompt, but %v", events)
+	}
+}
+
+func TestSaverNotSaved(t *testing.T) {
+	for _, c := range []struct {
+		err   error
+		saved bool
+	}{
+		{nil, true},
+		{ErrNotSaved, false},
+	} {
+		cfg := &Config{
+			Saver: SaveFunc(func(*SaveEvent) error { return c.err }),
+		}
+		result, _ := runEdit(t, cfg, "r|X|w|mem|q|y", "a,b\n")
+		if result.Saved != c.saved || result.Modified() == c.saved {
+			t.Fatalf("%v: expect saved=%v but saved=%v,modified=%v",
+				c.err, c.saved, result.Saved, result.Modified())
+		}
+	}
+}